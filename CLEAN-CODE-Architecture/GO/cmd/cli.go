@@ -0,0 +1,217 @@
+package main
+
+// Command-line subcommands. They run against the same database and
+// configuration as the server and exit instead of serving HTTP:
+//
+//	auth-module import -file users.csv -tenant acme
+//	auth-module export -format csv -out users.csv -role admin
+//	auth-module reencrypt
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/interface/bulk"
+	"auth-module/internal/interface/handler"
+	pgRepo "auth-module/internal/interface/repository/postgres"
+	tenantUseCase "auth-module/internal/usecase/tenant"
+	userUseCase "auth-module/internal/usecase/user"
+)
+
+// runCommand executes a subcommand and returns the process exit code.
+func runCommand(args []string, bulkUseCase *userUseCase.BulkUseCase, userRepo *pgRepo.PostgresUserRepo, tenants *tenantUseCase.TenantUseCase) int {
+	switch args[0] {
+	case "import":
+		return runImport(args[1:], bulkUseCase, tenants)
+	case "export":
+		return runExport(args[1:], bulkUseCase, tenants)
+	case "reencrypt":
+		return runReencrypt(args[1:], userRepo)
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q (available: import, export, reencrypt)\n", args[0])
+	return 2
+}
+
+// runImport imports users from a file or stdin into one tenant and prints
+// the report as JSON. It exits 1 if the import aborted or any row was
+// rejected. Since rows may carry a role, this is also how a new tenant gets
+// its first admin.
+func runImport(args []string, bulkUseCase *userUseCase.BulkUseCase, tenants *tenantUseCase.TenantUseCase) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "file to import (default stdin)")
+	tenant := flags.String("tenant", entity.DefaultTenantSlug, "slug or ID of the tenant to import into")
+	formatName := flags.String("format", "", "csv or ndjson (default from the file extension)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	name := *formatName
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+	format, err := bulk.ParseFormat(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 2
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "import:", err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+
+	source, err := bulk.NewReader(format, in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+	ctx, err := tenantContext(tenants, *tenant)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 2
+	}
+	report, importErr := bulkUseCase.Import(ctx, source)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if importErr != nil {
+		fmt.Fprintln(os.Stderr, "import aborted:", importErr)
+		return 1
+	}
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runExport writes the selected users of one tenant to a file.
+func runExport(args []string, bulkUseCase *userUseCase.BulkUseCase, tenants *tenantUseCase.TenantUseCase) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", "", "file to write (required)")
+	tenant := flags.String("tenant", entity.DefaultTenantSlug, "slug or ID of the tenant to export")
+	formatName := flags.String("format", "", "csv or ndjson (default from the file extension)")
+	query := flags.String("q", "", "only users matching this search")
+	role := flags.String("role", "", "only users with this role")
+	status := flags.String("status", "", "only users with this account status")
+	domain := flags.String("email-domain", "", "only users with this email domain")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "export: -out is required")
+		return 2
+	}
+
+	name := *formatName
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(*out), ".")
+	}
+	format, err := bulk.ParseFormat(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 2
+	}
+
+	// Reuse the API's filter parsing so both accept the same values
+	values := url.Values{}
+	for key, value := range map[string]string{"role": *role, "status": *status, "email_domain": *domain} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	filter, err := handler.ParseUserFilter(values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 2
+	}
+	filter.Search = *query
+
+	ctx, err := tenantContext(tenants, *tenant)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 2
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 1
+	}
+	defer f.Close()
+
+	writer, err := bulk.NewWriter(format, f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 1
+	}
+	count := 0
+	err = bulkUseCase.Export(ctx, filter, func(user *entity.User) error {
+		count++
+		return writer.Write(user)
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "exported %d users to %s\n", count, *out)
+	return 0
+}
+
+// runReencrypt moves encrypted user fields to the primary key of the
+// keyring, e.g. after a key rotation, and prints the report as JSON. Retired
+// keys can be removed from the keyring once it has completed.
+func runReencrypt(args []string, userRepo *pgRepo.PostgresUserRepo) int {
+	flags := flag.NewFlagSet("reencrypt", flag.ContinueOnError)
+	batchSize := flags.Int("batch", 500, "rows read per query")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *batchSize <= 0 {
+		fmt.Fprintln(os.Stderr, "reencrypt: -batch must be positive")
+		return 2
+	}
+
+	report, err := userRepo.Reencrypt(signalContext(), *batchSize)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reencrypt aborted:", err)
+		return 1
+	}
+	return 0
+}
+
+// tenantContext returns a cancellable context (see signalContext) scoped to
+// the tenant with slug or ID ref.
+func tenantContext(tenants *tenantUseCase.TenantUseCase, ref string) (context.Context, error) {
+	ctx := signalContext()
+	tenant, err := tenants.ResolveTenant(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", ref, err)
+	}
+	return repository.WithTenant(ctx, tenant.ID), nil
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/domain/service"
+	"auth-module/internal/infrastructure/breach"
+	"auth-module/internal/infrastructure/disposable"
+	"auth-module/internal/infrastructure/encryption"
+	"auth-module/internal/infrastructure/geoip"
+	"auth-module/internal/infrastructure/mail"
+	"auth-module/internal/infrastructure/notify"
+	"auth-module/internal/interface/handler"
+	"auth-module/pkg/hash"
+)
+
+// envInt reads an integer environment variable, falling back when it is
+// unset or malformed.
+func envInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Ignoring invalid %s=%q: %v", key, value, err)
+		return fallback
+	}
+	return n
+}
+
+// envBool reads a boolean environment variable, falling back when it is
+// unset or malformed.
+func envBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Ignoring invalid %s=%q: %v", key, value, err)
+		return fallback
+	}
+	return b
+}
+
+// loadPasswordPolicy builds the password policy from PASSWORD_* variables.
+// BREACHED_PASSWORDS_FILE optionally points at a list of SHA-1 hashes of
+// breached passwords (Have I Been Pwned format). With
+// PASSWORD_HASH_ALGORITHM=bcrypt the maximum length is capped at the 72
+// bytes bcrypt can hash.
+func loadPasswordPolicy() *policy.PasswordPolicy {
+	defaults := policy.DefaultPasswordPolicyConfig()
+	cfg := policy.PasswordPolicyConfig{
+		MinLength:          envInt("PASSWORD_MIN_LENGTH", defaults.MinLength),
+		MaxLength:          envInt("PASSWORD_MAX_LENGTH", defaults.MaxLength),
+		RequireUpper:       envBool("PASSWORD_REQUIRE_UPPER", defaults.RequireUpper),
+		RequireLower:       envBool("PASSWORD_REQUIRE_LOWER", defaults.RequireLower),
+		RequireDigit:       envBool("PASSWORD_REQUIRE_DIGIT", defaults.RequireDigit),
+		RequireSymbol:      envBool("PASSWORD_REQUIRE_SYMBOL", defaults.RequireSymbol),
+		ForbidPersonalInfo: envBool("PASSWORD_FORBID_PERSONAL_INFO", defaults.ForbidPersonalInfo),
+		MinStrength:        envInt("PASSWORD_MIN_STRENGTH", defaults.MinStrength),
+	}
+	if envString("PASSWORD_HASH_ALGORITHM", "argon2id") == "bcrypt" &&
+		(cfg.MaxLength <= 0 || cfg.MaxLength > hash.BcryptMaxPasswordLength) {
+		log.Printf("PASSWORD_MAX_LENGTH lowered to %d, the most bcrypt can hash", hash.BcryptMaxPasswordLength)
+		cfg.MaxLength = hash.BcryptMaxPasswordLength
+	}
+
+	var breached service.BreachedPasswordChecker
+	if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+		checker, err := breach.LoadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to load breached password list: %v", err)
+		}
+		log.Printf("Loaded %d breached password hashes from %s", checker.Len(), path)
+		breached = checker
+	}
+
+	return policy.NewPasswordPolicy(cfg, breached)
+}
+
+// loadRegistrationPolicy builds the registration policy from REGISTRATION_MODE
+// (open, restricted, invite_only or closed) and
+// REGISTRATION_ALLOWED_DOMAINS, a comma-separated list required in
+// restricted mode. DISPOSABLE_EMAIL_DOMAINS_FILE optionally points at a list
+// of disposable email domains, one per line, refused at self-service
+// registration.
+func loadRegistrationPolicy() *policy.RegistrationPolicy {
+	mode, err := policy.ParseRegistrationMode(envString("REGISTRATION_MODE", string(policy.RegistrationOpen)))
+	if err != nil {
+		log.Fatalf("Registration policy: %v", err)
+	}
+	cfg := policy.RegistrationPolicyConfig{Mode: mode}
+	if domains := os.Getenv("REGISTRATION_ALLOWED_DOMAINS"); domains != "" {
+		cfg.AllowedDomains = strings.Split(domains, ",")
+	}
+
+	var disposableDomains service.DisposableEmailChecker
+	if path := os.Getenv("DISPOSABLE_EMAIL_DOMAINS_FILE"); path != "" {
+		list, err := disposable.LoadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to load disposable email domains: %v", err)
+		}
+		log.Printf("Loaded %d disposable email domains from %s", list.Len(), path)
+		disposableDomains = list
+	}
+
+	registration, err := policy.NewRegistrationPolicy(cfg, disposableDomains)
+	if err != nil {
+		log.Fatalf("Registration policy: %v", err)
+	}
+	return registration
+}
+
+// loadPasswordHasher builds the pooled password hasher from PASSWORD_HASH_*,
+// ARGON2_*, BCRYPT_COST and HASH_* variables.
+// New hashes use PASSWORD_HASH_ALGORITHM (argon2id by default); hashes from
+// the other algorithm are still accepted and upgraded on the next login.
+// PASSWORD_PEPPER (base64) enables an Argon2id pepper identified by
+// PASSWORD_PEPPER_ID; PASSWORD_PEPPERS_RETIRED lists earlier peppers as
+// comma-separated id:base64 pairs so their hashes keep verifying.
+func loadPasswordHasher() *hash.Pool {
+	defaults := hash.DefaultArgon2Params()
+	params := hash.Argon2Params{
+		Memory:      uint32(envInt("ARGON2_MEMORY_KIB", int(defaults.Memory))),
+		Iterations:  uint32(envInt("ARGON2_ITERATIONS", int(defaults.Iterations))),
+		Parallelism: uint8(envInt("ARGON2_PARALLELISM", int(defaults.Parallelism))),
+		SaltLength:  defaults.SaltLength,
+		KeyLength:   defaults.KeyLength,
+	}
+
+	var pepper hash.Pepper
+	if encoded := os.Getenv("PASSWORD_PEPPER"); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			log.Fatalf("PASSWORD_PEPPER must be base64: %v", err)
+		}
+		pepper = hash.Pepper{ID: envString("PASSWORD_PEPPER_ID", "1"), Key: key}
+	}
+	var retired []hash.Pepper
+	for _, entry := range strings.Split(os.Getenv("PASSWORD_PEPPERS_RETIRED"), ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if !ok || id == "" || err != nil {
+			log.Fatalf("PASSWORD_PEPPERS_RETIRED entries must be id:base64, got %q", entry)
+		}
+		if id == pepper.ID {
+			log.Fatalf("PASSWORD_PEPPERS_RETIRED must not contain the current PASSWORD_PEPPER_ID %q", id)
+		}
+		retired = append(retired, hash.Pepper{ID: id, Key: key})
+	}
+
+	argon := hash.NewArgon2id(params, pepper, retired...)
+	bcrypt := hash.NewBcrypt(envInt("BCRYPT_COST", hash.DefaultBcryptCost))
+
+	var hasher *hash.Hasher
+	switch algorithm := envString("PASSWORD_HASH_ALGORITHM", "argon2id"); algorithm {
+	case "argon2id":
+		hasher = hash.NewHasher(argon, bcrypt)
+	case "bcrypt":
+		if pepper.ID != "" {
+			log.Fatal("PASSWORD_PEPPER is only supported with PASSWORD_HASH_ALGORITHM=argon2id")
+		}
+		hasher = hash.NewHasher(bcrypt, argon)
+	default:
+		log.Fatalf("Unknown PASSWORD_HASH_ALGORITHM %q (want argon2id or bcrypt)", algorithm)
+	}
+
+	// Build the preferred algorithm's dummy hash now so the first login for
+	// an unknown email takes as long as every later one. Dummies for legacy
+	// costs are built once logins have verified such hashes.
+	if err := hasher.CompareDummy(context.Background(), "", ""); err != nil {
+		log.Fatalf("Failed to prepare password hasher: %v", err)
+	}
+
+	// Bound concurrent hashing so /login and /register bursts cannot starve
+	// other routes; excess requests get 503 instead of queuing forever.
+	return hash.NewPool(hasher, hash.PoolConfig{
+		Workers:    envInt("HASH_WORKERS", runtime.NumCPU()),
+		QueueDepth: envInt("HASH_QUEUE_DEPTH", 32),
+	})
+}
+
+// loadTenantOptions reads how requests name their tenant. DEFAULT_TENANT is
+// the fallback tenant (slug or ID) and defaults to the migrations' default
+// tenant; set it to an empty value to require every request to name one.
+func loadTenantOptions() handler.TenantOptions {
+	defaultTenant, ok := os.LookupEnv("DEFAULT_TENANT")
+	if !ok {
+		defaultTenant = entity.DefaultTenantSlug
+	}
+	return handler.TenantOptions{
+		Header:     envString("TENANT_HEADER", handler.DefaultTenantHeader),
+		BaseDomain: os.Getenv("TENANT_BASE_DOMAIN"),
+		Default:    defaultTenant,
+	}
+}
+
+// loadTrustedProxies reads TRUSTED_PROXIES, the comma-separated CIDRs of
+// reverse proxies whose X-Forwarded-For header is believed.
+func loadTrustedProxies() []*net.IPNet {
+	proxies, err := handler.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("TRUSTED_PROXIES: %v", err)
+	}
+	return proxies
+}
+
+// loadFieldCipher builds the cipher for encrypted user fields from the
+// keyring file named by FIELD_KEYRING_FILE.
+func loadFieldCipher() *encryption.FieldCipher {
+	path := os.Getenv("FIELD_KEYRING_FILE")
+	if path == "" {
+		log.Fatal("FIELD_KEYRING_FILE environment variable is required (use ./dev-keyring.json for local development)")
+	}
+	keyring, err := encryption.LoadKeyring(path)
+	if err != nil {
+		log.Fatalf("Failed to load field encryption keyring: %v", err)
+	}
+	return encryption.NewFieldCipher(keyring)
+}
+
+// loadMailer returns an SMTP mailer for SMTP_ADDR, or a mailer that only
+// logs messages when it is unset.
+func loadMailer() service.Mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		log.Println("SMTP_ADDR is not set; emails will be written to the log instead of sent")
+		return mail.LogMailer{}
+	}
+	mailer, err := mail.NewSMTPMailer(addr, os.Getenv("MAIL_FROM"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+	if err != nil {
+		log.Fatalf("Mailer: %v", err)
+	}
+	return mailer
+}
+
+// loadLoginNotifier returns how users are told about risky logins:
+// LOGIN_NOTIFIER=email (the default) sends them through mailer, and none
+// only flags them on the session and in the login history.
+func loadLoginNotifier(mailer service.Mailer) service.LoginNotifier {
+	switch notifier := envString("LOGIN_NOTIFIER", "email"); notifier {
+	case "email":
+		return notify.NewMailLoginNotifier(mailer)
+	case "none":
+		return nil
+	default:
+		log.Fatalf("LOGIN_NOTIFIER must be email or none, got %q", notifier)
+		return nil
+	}
+}
+
+// loadGeoIP opens the MaxMind-format database named by
+// GEOIP_DATABASE_FILE, or returns nil when it is unset.
+func loadGeoIP() *geoip.Database {
+	path := os.Getenv("GEOIP_DATABASE_FILE")
+	if path == "" {
+		return nil
+	}
+	db, err := geoip.Open(path)
+	if err != nil {
+		log.Fatalf("GeoIP: %v", err)
+	}
+	log.Printf("Loaded GeoIP database %s", db.Describe())
+	return db
+}
+
+// loadCountryPolicy reads LOGIN_COUNTRIES_ALLOWED and LOGIN_COUNTRIES_DENIED
+// (comma-separated ISO country codes) and LOGIN_COUNTRIES_ALLOW_UNKNOWN.
+// It returns nil when neither list is set.
+func loadCountryPolicy(locator service.GeoLocator) *policy.CountryPolicy {
+	countries, err := policy.NewCountryPolicy(policy.CountryPolicyConfig{
+		Allowed:      strings.Split(os.Getenv("LOGIN_COUNTRIES_ALLOWED"), ","),
+		Denied:       strings.Split(os.Getenv("LOGIN_COUNTRIES_DENIED"), ","),
+		AllowUnknown: envBool("LOGIN_COUNTRIES_ALLOW_UNKNOWN", true),
+	}, locator)
+	if err != nil {
+		log.Fatalf("Login country policy: %v", err)
+	}
+	return countries
+}
+
+// loadCursorSecret returns CURSOR_SECRET, or a key derived from jwtSecret
+// when it is unset. The derived key is never the JWT key itself, so a
+// cursor signature cannot double as a token signature or the reverse.
+func loadCursorSecret(jwtSecret string) []byte {
+	if secret := os.Getenv("CURSOR_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	mac.Write([]byte("auth-module pagination cursors"))
+	return mac.Sum(nil)
+}
+
+// envString reads a string environment variable, falling back when it is unset or empty.
+func envString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envDuration reads a duration environment variable such as "15m",
+// falling back when it is unset or malformed.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Ignoring invalid %s=%q: %v", key, value, err)
+		return fallback
+	}
+	return d
+}
+
+// signalContext is canceled on interrupt or SIGTERM, so a long-running
+// command stops cleanly between batches.
+func signalContext() context.Context {
+	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	return ctx
+}
@@ -3,12 +3,14 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,9 +18,18 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
-	"auth-module/internal/infrastructure/database/models"
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/service"
+	"auth-module/internal/infrastructure/database"
+	"auth-module/internal/infrastructure/token"
+	"auth-module/internal/infrastructure/useragent"
 	"auth-module/internal/interface/handler"
 	pgRepo "auth-module/internal/interface/repository/postgres"
+	"auth-module/internal/usecase/auth"
+	groupUseCase "auth-module/internal/usecase/group"
+	invitationUseCase "auth-module/internal/usecase/invitation"
+	tenantUseCase "auth-module/internal/usecase/tenant"
+	userUseCase "auth-module/internal/usecase/user"
 )
 
 // findAvailablePort finds an available port starting from the given port
@@ -82,17 +93,117 @@ func main() {
 	}
 	fmt.Println("Database connection successful!")
 
-	// Drop existing table and recreate
-	if err := db.Migrator().DropTable(&models.UserModel{}); err != nil {
-		log.Printf("Warning: Could not drop users table: %v", err)
+	migrator := database.NewMigrator(db)
+
+	// Subcommands such as "import" run instead of the server
+	command := os.Args[1:]
+
+	// Drop existing tables and recreate them; for development only, as
+	// imported data would not survive a restart
+	if envBool("DB_RESET_ON_START", false) && len(command) == 0 {
+		if err := migrator.DropTables(); err != nil {
+			log.Printf("Warning: Could not drop users table: %v", err)
+		}
+	}
+
+	// Extensions, tables and the search indexes
+	if err := migrator.RunMigrations(); err != nil {
+		log.Fatalf("Migrations failed: %v", err)
+	}
+
+	// Create repository instance with GORM DB; the TxManager shares the
+	// same handle so repository calls join its transactions
+	userRepo := pgRepo.NewPostgresUserRepo(db, loadFieldCipher())
+	txManager := pgRepo.NewTxManager(db)
+	tenantRepo := pgRepo.NewPostgresTenantRepo(db)
+	groupRepo := pgRepo.NewPostgresGroupRepo(db)
+	invitationRepo := pgRepo.NewPostgresInvitationRepo(db)
+	impersonationRepo := pgRepo.NewPostgresImpersonationRepo(db)
+	sessionRepo := pgRepo.NewPostgresSessionRepo(db)
+	loginAttemptRepo := pgRepo.NewPostgresLoginAttemptRepo(db)
+	magicLinkRepo := pgRepo.NewPostgresMagicLinkRepo(db)
+	passwordHasher := loadPasswordHasher()
+	passwordPolicy := loadPasswordPolicy()
+	registrationPolicy := loadRegistrationPolicy()
+	mailer := loadMailer()
+	deviceDetector := useragent.NewDetector()
+	// Locations are only recorded, and country rules only enforced, with a
+	// GeoIP database
+	geoDB := loadGeoIP()
+	var geoLocator service.GeoLocator
+	if geoDB != nil {
+		geoLocator = geoDB
+	}
+	loginHistory := auth.NewLoginHistory(loginAttemptRepo, deviceDetector, geoLocator, loadLoginNotifier(mailer))
+
+	registerUseCase := auth.NewRegisterUseCase(userRepo, passwordHasher, passwordPolicy, registrationPolicy, auth.RegisterOptions{
+		ConcealExistingEmail: envBool("REGISTRATION_ENUMERATION_SAFE", false),
+	})
+	countryPolicy := loadCountryPolicy(geoLocator)
+	loginUseCase := auth.NewLoginUseCase(userRepo, passwordHasher, loginHistory, countryPolicy)
+	passwordUseCase := auth.NewPasswordUseCase(userRepo, passwordHasher, passwordPolicy)
+	usersUseCase := userUseCase.NewUserUseCase(userRepo, txManager)
+	tenantsUseCase := tenantUseCase.NewTenantUseCase(tenantRepo)
+	groupsUseCase := groupUseCase.NewGroupUseCase(groupRepo, userRepo)
+	invitationsUseCase := invitationUseCase.NewInvitationUseCase(invitationRepo, userRepo, groupRepo, txManager, passwordHasher, passwordPolicy, registrationPolicy, mailer, invitationUseCase.Options{
+		TTL:       envDuration("INVITATION_TTL", invitationUseCase.DefaultTTL),
+		AcceptURL: envString("INVITATION_URL", "http://localhost:8080/invitations/{token}/accept"),
+	})
+	bulkUseCase := userUseCase.NewBulkUseCase(userRepo, passwordHasher, passwordPolicy, userUseCase.BulkOptions{
+		BatchSize: envInt("IMPORT_BATCH_SIZE", 500),
+	})
+
+	if len(command) > 0 {
+		os.Exit(runCommand(command, bulkUseCase, userRepo, tenantsUseCase))
 	}
 
-	if err := db.AutoMigrate(&models.UserModel{}); err != nil {
-		log.Fatalf("AutoMigrate failed: %v", err)
+	jwtService, err := token.NewJWTService([]byte(jwtSecret), "auth-module", envDuration("ACCESS_TOKEN_TTL", token.DefaultAccessTokenTTL))
+	if err != nil {
+		log.Fatalf("Token service: %v", err)
+	}
+	// Session activity is written in batches rather than on every request
+	lastSeenTracker := auth.NewLastSeenTracker(sessionRepo, envDuration("SESSION_LAST_SEEN_INTERVAL", auth.DefaultLastSeenInterval))
+	// Tokens of suspended accounts or revoked sessions stop working at once
+	tokenService := auth.NewActiveAccountTokens(jwtService, userRepo, impersonationRepo, sessionRepo, lastSeenTracker)
+	impersonationUseCase := auth.NewImpersonationUseCase(userRepo, impersonationRepo, tokenService, envDuration("IMPERSONATION_TTL", auth.DefaultImpersonationTTL))
+
+	// Admins of the default tenant, which the migrations create, manage
+	// all tenants
+	managerTenant, err := tenantsUseCase.ResolveTenant(context.Background(), entity.DefaultTenantSlug)
+	if err != nil {
+		log.Fatalf("Default tenant: %v", err)
 	}
 
-	// Create repository instance with GORM DB
-	userRepo := pgRepo.NewUserRepo(db)
+	// Every user route runs in the tenant named by the request
+	tenantOptions := loadTenantOptions()
+	withTenant := func(next http.HandlerFunc) http.HandlerFunc {
+		// Picking the tenant only needs the signed tid claim; RequireAuth
+		// checks the account afterwards
+		return handler.RequireTenant(tenantsUseCase, jwtService, tenantOptions, next)
+	}
+
+	// Access tokens only list the user's groups when enabled, as the claim
+	// costs a query per login and grows with the number of groups
+	var tokenGroups auth.GroupLister
+	if envBool("TOKEN_GROUPS_CLAIM", false) {
+		tokenGroups = groupsUseCase
+	}
+	sessionsUseCase := auth.NewSessionUseCase(sessionRepo, userRepo, tokenService, deviceDetector, txManager, lastSeenTracker, auth.SessionOptions{
+		TTL:     envDuration("SESSION_TTL", auth.DefaultSessionTTL),
+		Groups:  tokenGroups,
+		History: loginHistory,
+		Locator: geoLocator,
+	})
+	magicLinksUseCase := auth.NewMagicLinkUseCase(magicLinkRepo, userRepo, sessionsUseCase, mailer, loginHistory, countryPolicy, auth.MagicLinkOptions{
+		TTL:       envDuration("MAGIC_LINK_TTL", auth.DefaultMagicLinkTTL),
+		Limit:     envInt("MAGIC_LINK_LIMIT", auth.DefaultMagicLinkLimit),
+		Window:    envDuration("MAGIC_LINK_WINDOW", auth.DefaultMagicLinkWindow),
+		IPLimit:   envInt("MAGIC_LINK_IP_LIMIT", auth.DefaultMagicLinkIPLimit),
+		VerifyURL: envString("MAGIC_LINK_URL", "http://localhost:8080/login/magic-link/verify?token={token}"),
+	})
+
+	// Pagination cursors are signed so clients cannot forge positions
+	cursorCodec := handler.NewCursorCodec(loadCursorSecret(jwtSecret))
 
 	fmt.Println("Database migration complete! Setting up HTTP routes...")
 
@@ -100,7 +211,7 @@ func main() {
 	mux := http.NewServeMux()
 
 	// Register routes with method check and pass repository
-	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/register", withTenant(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -109,10 +220,48 @@ func main() {
 			})
 			return
 		}
-		handler.RegisterHandlerWithRepo(w, r, userRepo)
+		handler.RegisterHandlerWithRepo(w, r, registerUseCase)
+	}))
+
+	mux.HandleFunc("/login", withTenant(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.LoginHandlerWithRepo(w, r, loginUseCase, sessionsUseCase)
+	}))
+
+	mux.HandleFunc("/login/magic-link", withTenant(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.RequestMagicLinkHandler(w, r, magicLinksUseCase)
+	}))
+
+	// The link token names the tenant, so no tenant is resolved here
+	mux.HandleFunc("/login/magic-link/verify", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET method is allowed",
+			})
+			return
+		}
+		handler.VerifyMagicLinkHandler(w, r, magicLinksUseCase)
 	})
 
-	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+	// The refresh token names the tenant, so no tenant is resolved here
+	mux.HandleFunc("/token/refresh", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -121,11 +270,36 @@ func main() {
 			})
 			return
 		}
-		handler.LoginHandlerWithRepo(w, r, userRepo)
+		handler.RefreshTokenHandler(w, r, sessionsUseCase)
 	})
 
-	// User management routes
-	mux.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
+	// The invitation token names the tenant, so no tenant is resolved here
+	mux.HandleFunc("/invitations/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.AcceptInvitationHandler(w, r, invitationsUseCase)
+	})
+
+	// Admin routes require an access token with the admin role
+	mux.HandleFunc("/api/admin/users/import", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.ImportUsersHandler(w, r, bulkUseCase)
+	})))
+
+	mux.HandleFunc("/api/admin/users/export", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -134,10 +308,47 @@ func main() {
 			})
 			return
 		}
-		handler.ListUsersHandler(w, r, userRepo)
-	})
+		handler.ExportUsersHandler(w, r, bulkUseCase)
+	})))
+
+	mux.HandleFunc("/api/admin/users/", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/suspend"):
+			handler.SuspendUserHandler(w, r, usersUseCase)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/lock"):
+			handler.LockUserHandler(w, r, usersUseCase)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/ban"):
+			handler.BanUserHandler(w, r, usersUseCase)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/reinstate"):
+			handler.ReinstateUserHandler(w, r, usersUseCase)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/sessions"):
+			handler.ListUserSessionsHandler(w, r, sessionsUseCase)
+		case r.Method == http.MethodDelete && strings.Contains(r.URL.Path, "/sessions/"):
+			handler.RevokeUserSessionHandler(w, r, sessionsUseCase)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Use POST on /api/admin/users/{id}/suspend, /lock, /ban or /reinstate, GET on /api/admin/users/{id}/sessions, or DELETE on /api/admin/users/{id}/sessions/{session_id}",
+			})
+		}
+	})))
+
+	// Impersonation tokens carry the target's role, so they never pass
+	// RequireRole admin; an impersonating admin cannot chain impersonations
+	mux.HandleFunc("/api/admin/impersonate/", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.ImpersonateUserHandler(w, r, impersonationUseCase)
+	})))
 
-	mux.HandleFunc("/api/users/all", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/admin/impersonations", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -146,11 +357,34 @@ func main() {
 			})
 			return
 		}
-		log.Println("Handling GET /api/users/all.")
-		handler.GetAllUsersHandler(w, r, userRepo)
-	})
+		handler.ListImpersonationsHandler(w, r, impersonationUseCase)
+	})))
 
-	mux.HandleFunc("/api/users/search", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/admin/impersonations/", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only DELETE method is allowed",
+			})
+			return
+		}
+		handler.EndImpersonationByIDHandler(w, r, impersonationUseCase)
+	})))
+
+	mux.HandleFunc("/api/impersonation/end", withTenant(handler.RequireAuth(tokenService, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.EndImpersonationHandler(w, r, impersonationUseCase)
+	})))
+
+	mux.HandleFunc("/api/admin/users/legacy/", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -159,10 +393,73 @@ func main() {
 			})
 			return
 		}
-		handler.SearchUsersHandler(w, r, userRepo)
-	})
+		handler.GetUserByLegacyIDHandler(w, r, usersUseCase)
+	})))
+
+	mux.HandleFunc("/api/admin/invitations", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListInvitationsHandler(w, r, invitationsUseCase)
+		case http.MethodPost:
+			handler.CreateInvitationHandler(w, r, invitationsUseCase)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET and POST methods are allowed",
+			})
+		}
+	})))
+
+	mux.HandleFunc("/api/admin/invitations/", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/resend"):
+			handler.ResendInvitationHandler(w, r, invitationsUseCase)
+		case r.Method == http.MethodDelete:
+			handler.RevokeInvitationHandler(w, r, invitationsUseCase)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Use POST on /api/admin/invitations/{id}/resend or DELETE on /api/admin/invitations/{id}",
+			})
+		}
+	})))
+
+	// Tenant management is outside any single tenant
+	mux.HandleFunc("/api/admin/tenants", handler.RequireTenantManager(tokenService, managerTenant.ID, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListTenantsHandler(w, r, tenantsUseCase)
+		case http.MethodPost:
+			handler.CreateTenantHandler(w, r, tenantsUseCase)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET and POST methods are allowed",
+			})
+		}
+	}))
+
+	mux.HandleFunc("/api/admin/tenants/", handler.RequireTenantManager(tokenService, managerTenant.ID, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.GetTenantHandler(w, r, tenantsUseCase)
+		case http.MethodPatch:
+			handler.RenameTenantHandler(w, r, tenantsUseCase)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET and PATCH methods are allowed",
+			})
+		}
+	}))
 
-	mux.HandleFunc("/api/users/count", func(w http.ResponseWriter, r *http.Request) {
+	// User management routes. Listings expose contact details and admin
+	// fields and accept admin filters, so they are for admins only
+	mux.HandleFunc("/api/users", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -171,11 +468,10 @@ func main() {
 			})
 			return
 		}
-		handler.GetUserCountHandler(w, r, userRepo)
-	})
+		handler.ListUsersHandler(w, r, usersUseCase, cursorCodec)
+	})))
 
-	// Handle user by ID (this needs to be last to avoid conflicts)
-	mux.HandleFunc("/api/users/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/users/search", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -184,8 +480,139 @@ func main() {
 			})
 			return
 		}
-		handler.GetUserByIDHandler(w, r, userRepo)
+		handler.SearchUsersHandler(w, r, usersUseCase, cursorCodec)
+	})))
+
+	mux.HandleFunc("/api/users/count", withTenant(handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET method is allowed",
+			})
+			return
+		}
+		handler.GetUserCountHandler(w, r, usersUseCase)
+	})))
+
+	// Changing credentials is refused to impersonating admins
+	mux.HandleFunc("/api/users/me/password", withTenant(handler.RequireAuth(tokenService, handler.ForbidImpersonation(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.ChangePasswordHandler(w, r, passwordUseCase)
+	}))))
+
+	mux.HandleFunc("/api/users/me/sessions", withTenant(handler.RequireAuth(tokenService, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET method is allowed",
+			})
+			return
+		}
+		handler.ListMySessionsHandler(w, r, sessionsUseCase)
+	})))
+
+	mux.HandleFunc("/api/users/me/logins", withTenant(handler.RequireAuth(tokenService, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET method is allowed",
+			})
+			return
+		}
+		handler.ListMyLoginsHandler(w, r, loginHistory)
+	})))
+
+	// Signing the user out of a device is theirs to decide, not an
+	// impersonating admin's
+	mux.HandleFunc("/api/users/me/sessions/", withTenant(handler.RequireAuth(tokenService, handler.ForbidImpersonation(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only DELETE method is allowed",
+			})
+			return
+		}
+		handler.RevokeMySessionHandler(w, r, sessionsUseCase)
+	}))))
+
+	// Handle user by ID (this needs to be last to avoid conflicts)
+	getUser := handler.RequireRole(tokenService, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		handler.GetUserByIDHandler(w, r, usersUseCase)
 	})
+	updateProfile := handler.RequireAuth(tokenService, func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateUserProfileHandler(w, r, usersUseCase)
+	})
+	listUserGroups := handler.RequireAuth(tokenService, func(w http.ResponseWriter, r *http.Request) {
+		handler.ListUserGroupsHandler(w, r, groupsUseCase)
+	})
+	mux.HandleFunc("/api/users/", withTenant(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/groups") {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error": "Only GET method is allowed",
+				})
+				return
+			}
+			listUserGroups(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			getUser(w, r)
+		case http.MethodPatch:
+			updateProfile(w, r)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only GET and PATCH methods are allowed",
+			})
+		}
+	}))
+
+	// Groups live in the tenant of the request; the use case checks whether
+	// the caller is an admin, an owner or a member of the group
+	mux.HandleFunc("/api/groups", withTenant(handler.RequireAuth(tokenService, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Only POST method is allowed",
+			})
+			return
+		}
+		handler.CreateGroupHandler(w, r, groupsUseCase)
+	})))
+
+	mux.HandleFunc("/api/groups/", withTenant(handler.RequireAuth(tokenService, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/members"):
+			handler.ListGroupMembersHandler(w, r, groupsUseCase, cursorCodec)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/members"):
+			handler.AddGroupMemberHandler(w, r, groupsUseCase)
+		case r.Method == http.MethodDelete && strings.Contains(r.URL.Path, "/members/"):
+			handler.RemoveGroupMemberHandler(w, r, groupsUseCase)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Use GET or POST on /api/groups/{id}/members, or DELETE on /api/groups/{id}/members/{user_id}",
+			})
+		}
+	})))
 
 	// Add health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -200,11 +627,43 @@ func main() {
 	fmt.Println("\nAPI Endpoints:")
 	fmt.Println("POST http://localhost:8080/register")
 	fmt.Println("POST http://localhost:8080/login")
+	fmt.Println("POST http://localhost:8080/login/magic-link")
+	fmt.Println("GET  http://localhost:8080/login/magic-link/verify?token={token}")
+	fmt.Println("POST http://localhost:8080/token/refresh")
 	fmt.Println("GET  http://localhost:8080/api/users")
-	fmt.Println("GET  http://localhost:8080/api/users/all")
 	fmt.Println("GET  http://localhost:8080/api/users/search")
 	fmt.Println("GET  http://localhost:8080/api/users/count")
 	fmt.Println("GET  http://localhost:8080/api/users/{id}")
+	fmt.Println("PATCH http://localhost:8080/api/users/{id} (auth, If-Match)")
+	fmt.Println("GET  http://localhost:8080/api/users/{id}/groups (auth)")
+	fmt.Println("POST http://localhost:8080/api/users/me/password (auth, not impersonating)")
+	fmt.Println("GET  http://localhost:8080/api/users/me/sessions (auth)")
+	fmt.Println("GET  http://localhost:8080/api/users/me/logins (auth)")
+	fmt.Println("DELETE http://localhost:8080/api/users/me/sessions/{id} (auth, not impersonating)")
+	fmt.Println("POST http://localhost:8080/api/groups (admin)")
+	fmt.Println("GET  http://localhost:8080/api/groups/{id}/members (auth)")
+	fmt.Println("POST http://localhost:8080/api/groups/{id}/members (admin or group owner)")
+	fmt.Println("DELETE http://localhost:8080/api/groups/{id}/members/{user_id} (auth)")
+	fmt.Println("POST http://localhost:8080/api/admin/users/import (admin)")
+	fmt.Println("GET  http://localhost:8080/api/admin/users/export (admin)")
+	fmt.Println("GET  http://localhost:8080/api/admin/users/legacy/{id} (admin)")
+	fmt.Println("POST http://localhost:8080/api/admin/users/{id}/suspend (admin)")
+	fmt.Println("POST http://localhost:8080/api/admin/users/{id}/reinstate (admin)")
+	fmt.Println("GET  http://localhost:8080/api/admin/users/{id}/sessions (admin)")
+	fmt.Println("DELETE http://localhost:8080/api/admin/users/{id}/sessions/{session_id} (admin)")
+	fmt.Println("POST http://localhost:8080/api/admin/impersonate/{id} (admin)")
+	fmt.Println("GET  http://localhost:8080/api/admin/impersonations (admin)")
+	fmt.Println("DELETE http://localhost:8080/api/admin/impersonations/{id} (admin)")
+	fmt.Println("POST http://localhost:8080/api/impersonation/end (impersonation token)")
+	fmt.Println("GET  http://localhost:8080/api/admin/invitations (admin)")
+	fmt.Println("POST http://localhost:8080/api/admin/invitations (admin)")
+	fmt.Println("POST http://localhost:8080/api/admin/invitations/{id}/resend (admin)")
+	fmt.Println("DELETE http://localhost:8080/api/admin/invitations/{id} (admin)")
+	fmt.Println("POST http://localhost:8080/invitations/{token}/accept")
+	fmt.Println("GET  http://localhost:8080/api/admin/tenants (default tenant admin)")
+	fmt.Println("POST http://localhost:8080/api/admin/tenants (default tenant admin)")
+	fmt.Println("GET  http://localhost:8080/api/admin/tenants/{id} (default tenant admin)")
+	fmt.Println("PATCH http://localhost:8080/api/admin/tenants/{id} (default tenant admin)")
 	fmt.Println("GET  http://localhost:8080/health")
 
 	// Determine which port to use
@@ -220,7 +679,7 @@ func main() {
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         serverAddr,
-		Handler:      mux,
+		Handler:      handler.ClientIP(loadTrustedProxies(), mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -230,6 +689,43 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
+	trackerCtx, stopTracker := context.WithCancel(context.Background())
+	trackerDone := make(chan struct{})
+	go func() {
+		lastSeenTracker.Run(trackerCtx)
+		close(trackerDone)
+	}()
+
+	// SIGHUP reloads the GeoIP database after it was updated on disk
+	if geoDB != nil {
+		hangup := make(chan os.Signal, 1)
+		signal.Notify(hangup, syscall.SIGHUP)
+		go func() {
+			for range hangup {
+				if err := geoDB.Reload(); err != nil {
+					log.Printf("Reloading GeoIP database failed, keeping the previous one: %v", err)
+					continue
+				}
+				log.Printf("Reloaded GeoIP database %s", geoDB.Describe())
+			}
+		}()
+	}
+
+	// Runtime and password hashing pool metrics are served apart from the
+	// API, on an address that should only be reachable from inside
+	var debugServer *http.Server
+	if debugAddr := os.Getenv("DEBUG_ADDR"); debugAddr != "" {
+		debugMux := http.NewServeMux()
+		debugMux.Handle("/debug/vars", expvar.Handler())
+		debugServer = &http.Server{Addr: debugAddr, Handler: debugMux, ReadTimeout: 5 * time.Second, WriteTimeout: 5 * time.Second}
+		go func() {
+			fmt.Printf("📊 Metrics on http://%s/debug/vars\n", debugAddr)
+			if err := debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start debug server: %v", err)
+			}
+		}()
+	}
+
 	// Start server in a goroutine
 	go func() {
 		fmt.Printf("\n🚀 Server is running on http://localhost:%d\n", availablePort)
@@ -254,6 +750,12 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+	if debugServer != nil {
+		debugServer.Shutdown(ctx)
+	}
+	// Write the session activity still buffered
+	stopTracker()
+	<-trackerDone
 
 	fmt.Println("✅ Server stopped gracefully")
 }
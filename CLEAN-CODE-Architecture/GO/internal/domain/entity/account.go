@@ -0,0 +1,179 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Role is the coarse authorization level of an account.
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole validates a role name.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleUser, RoleAdmin:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// AccountStatus is the lifecycle state of an account. Only active accounts
+// can sign in.
+//   - suspended: stopped by support staff, optionally until a given time
+//   - locked: stopped for security reasons, e.g. a suspected takeover
+//   - banned: stopped for good; a ban can only be lifted by reinstating
+type AccountStatus string
+
+const (
+	StatusActive    AccountStatus = "active"
+	StatusSuspended AccountStatus = "suspended"
+	StatusLocked    AccountStatus = "locked"
+	StatusBanned    AccountStatus = "banned"
+)
+
+// ParseAccountStatus validates an account status name.
+func ParseAccountStatus(s string) (AccountStatus, error) {
+	switch st := AccountStatus(s); st {
+	case StatusActive, StatusSuspended, StatusLocked, StatusBanned:
+		return st, nil
+	}
+	return "", fmt.Errorf("unknown account status %q", s)
+}
+
+// MaxStatusReasonLength is the longest accepted status or impersonation
+// reason, in characters.
+const MaxStatusReasonLength = 500
+
+var (
+	// ErrAccountSuspended, ErrAccountLocked and ErrAccountBanned are returned
+	// by User.CheckActive for accounts that cannot sign in.
+	ErrAccountSuspended = errors.New("account is suspended")
+	ErrAccountLocked    = errors.New("account is locked")
+	ErrAccountBanned    = errors.New("account is banned")
+	// ErrInvalidStatusTransition is returned when the account's current
+	// status does not allow the requested change.
+	ErrInvalidStatusTransition = errors.New("account status does not allow this change")
+)
+
+// ValidateReason checks the reason recorded for stopping an account or
+// impersonating one.
+func ValidateReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("reason is required")
+	}
+	if utf8.RuneCountInString(reason) > MaxStatusReasonLength {
+		return fmt.Errorf("reason must be at most %d characters", MaxStatusReasonLength)
+	}
+	return nil
+}
+
+// EffectiveStatus returns the status as of now: a suspension past its end
+// is StatusActive.
+func (u *User) EffectiveStatus(now time.Time) AccountStatus {
+	if u.Status == StatusSuspended && u.StatusUntil != nil && !now.Before(*u.StatusUntil) {
+		return StatusActive
+	}
+	return u.Status
+}
+
+// CheckActive returns nil when the account may sign in and use its
+// sessions at now, and otherwise ErrAccountSuspended, ErrAccountLocked or
+// ErrAccountBanned.
+func (u *User) CheckActive(now time.Time) error {
+	switch u.EffectiveStatus(now) {
+	case StatusActive:
+		return nil
+	case StatusSuspended:
+		return ErrAccountSuspended
+	case StatusLocked:
+		return ErrAccountLocked
+	default:
+		return ErrAccountBanned
+	}
+}
+
+// Suspend stops an active, suspended or locked account, until the given
+// time or indefinitely when until is nil. Suspending a suspended account
+// replaces the reason and end. Sessions are revoked.
+func (u *User) Suspend(reason string, until *time.Time, now time.Time) error {
+	verr := &ValidationError{}
+	verr.Check("reason", ValidateReason(reason))
+	if until != nil && !until.After(now) {
+		verr.Check("until", errors.New("until must be in the future"))
+	}
+	if verr.HasErrors() {
+		return verr
+	}
+	if u.Status == StatusBanned {
+		return ErrInvalidStatusTransition
+	}
+	u.setStatus(StatusSuspended, reason, until, now)
+	u.RevokeSessions(now)
+	return nil
+}
+
+// Lock stops an active or suspended account for security reasons. Sessions
+// are revoked.
+func (u *User) Lock(reason string, now time.Time) error {
+	if err := ValidateReason(reason); err != nil {
+		return NewFieldError("reason", err)
+	}
+	if u.Status == StatusLocked || u.Status == StatusBanned {
+		return ErrInvalidStatusTransition
+	}
+	u.setStatus(StatusLocked, reason, nil, now)
+	u.RevokeSessions(now)
+	return nil
+}
+
+// Ban stops an account permanently. Sessions are revoked.
+func (u *User) Ban(reason string, now time.Time) error {
+	if err := ValidateReason(reason); err != nil {
+		return NewFieldError("reason", err)
+	}
+	if u.Status == StatusBanned {
+		return ErrInvalidStatusTransition
+	}
+	u.setStatus(StatusBanned, reason, nil, now)
+	u.RevokeSessions(now)
+	return nil
+}
+
+// Reinstate makes a suspended, locked or banned account active again.
+// Sessions revoked when it was stopped stay revoked.
+func (u *User) Reinstate(now time.Time) error {
+	if u.Status == StatusActive {
+		return ErrInvalidStatusTransition
+	}
+	u.setStatus(StatusActive, "", nil, now)
+	return nil
+}
+
+// RevokeSessions invalidates every session and access token issued before
+// now.
+func (u *User) RevokeSessions(now time.Time) {
+	u.SessionsRevokedAt = &now
+	u.UpdatedAt = now
+}
+
+// SessionRevoked reports whether a session or token issued at issuedAt was
+// revoked by RevokeSessions.
+func (u *User) SessionRevoked(issuedAt time.Time) bool {
+	return u.SessionsRevokedAt != nil && issuedAt.Before(*u.SessionsRevokedAt)
+}
+
+func (u *User) setStatus(status AccountStatus, reason string, until *time.Time, now time.Time) {
+	u.Status = status
+	u.StatusReason = strings.TrimSpace(reason)
+	u.StatusUntil = until
+	u.StatusChangedAt = &now
+	u.UpdatedAt = now
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	maxEmailLength    = 254
+	maxEmailLocalPart = 64
+)
+
+// Email is a syntactically valid, normalized email address.
+// It is obtained through NewEmail, so holding an Email means the address
+// has passed the domain rules, or RestoreEmail for stored addresses.
+type Email struct {
+	value string
+}
+
+// NewEmail parses raw as an RFC 5322 addr-spec and normalizes it:
+//   - surrounding whitespace is trimmed and display names are rejected
+//   - internationalized domains are converted to their ASCII (punycode) form
+//   - the whole address is lower-cased, matching the case-insensitive
+//     uniqueness enforced by the users table
+func NewEmail(raw string) (Email, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Email{}, errors.New("email is required")
+	}
+	if len(raw) > maxEmailLength {
+		return Email{}, errors.New("email is too long")
+	}
+
+	addr, err := mail.ParseAddress(raw)
+	if err != nil || addr.Address != raw {
+		// A differing Address means the input carried a display name,
+		// angle brackets or a quoted local part, none of which we store.
+		return Email{}, errors.New("invalid email format")
+	}
+
+	at := strings.LastIndexByte(addr.Address, '@')
+	local, domain := addr.Address[:at], addr.Address[at+1:]
+	if len(local) > maxEmailLocalPart {
+		return Email{}, errors.New("email local part is too long")
+	}
+
+	asciiDomain, err := NormalizeEmailDomain(domain)
+	if err != nil {
+		return Email{}, err
+	}
+
+	return Email{value: strings.ToLower(local) + "@" + asciiDomain}, nil
+}
+
+// NormalizeEmailDomain converts a (possibly internationalized) domain to
+// the lower-case ASCII form stored in Email values.
+func NormalizeEmailDomain(domain string) (string, error) {
+	asciiDomain, err := idna.Lookup.ToASCII(strings.TrimSpace(domain))
+	if err != nil || !strings.Contains(asciiDomain, ".") {
+		return "", errors.New("invalid email domain")
+	}
+	return strings.ToLower(asciiDomain), nil
+}
+
+// RestoreEmail is RestoreUsername for emails.
+func RestoreEmail(stored string) Email {
+	return Email{value: stored}
+}
+
+// String returns the normalized address.
+func (e Email) String() string {
+	return e.value
+}
+
+// Domain returns the ASCII domain part of the address.
+func (e Email) Domain() string {
+	return e.value[strings.LastIndexByte(e.value, '@')+1:]
+}
+
+// IsZero reports whether the email is unset.
+func (e Email) IsZero() bool {
+	return e.value == ""
+}
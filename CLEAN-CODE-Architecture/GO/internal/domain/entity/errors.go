@@ -0,0 +1,43 @@
+package entity
+
+import "strings"
+
+// FieldError describes why a single field of an entity is invalid.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError collects every field-level rule violation found while
+// constructing or changing an entity, so callers can report them together
+// instead of one at a time.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewFieldError wraps a single validation failure for the given field.
+func NewFieldError(field string, err error) *ValidationError {
+	v := &ValidationError{}
+	v.Check(field, err)
+	return v
+}
+
+// Check records err against field when err is non-nil.
+func (v *ValidationError) Check(field string, err error) {
+	if err != nil {
+		v.Fields = append(v.Fields, FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+// HasErrors reports whether any field failed validation.
+func (v *ValidationError) HasErrors() bool {
+	return len(v.Fields) > 0
+}
+
+func (v *ValidationError) Error() string {
+	msgs := make([]string, len(v.Fields))
+	for i, f := range v.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
@@ -0,0 +1,183 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidUserID is returned for IDs that are not UUIDs.
+var ErrInvalidUserID = errors.New("invalid user ID format")
+
+// ErrInvalidTenantID is returned for tenant IDs that are not UUIDs.
+var ErrInvalidTenantID = errors.New("invalid tenant ID format")
+
+// ErrInvalidGroupID is returned for group IDs that are not UUIDs.
+var ErrInvalidGroupID = errors.New("invalid group ID format")
+
+// ErrInvalidInvitationID is returned for invitation IDs that are not UUIDs.
+var ErrInvalidInvitationID = errors.New("invalid invitation ID format")
+
+// ErrInvalidImpersonationID is returned for impersonation IDs that are not
+// UUIDs.
+var ErrInvalidImpersonationID = errors.New("invalid impersonation ID format")
+
+// ErrInvalidSessionID is returned for session IDs that are not UUIDs.
+var ErrInvalidSessionID = errors.New("invalid session ID format")
+
+// ErrInvalidLoginAttemptID is returned for login attempt IDs that are not
+// UUIDs.
+var ErrInvalidLoginAttemptID = errors.New("invalid login attempt ID format")
+
+// NewUserID returns a new UUIDv7 (RFC 9562). Its leading bits are the
+// creation time in milliseconds, so IDs sort roughly by creation and index
+// well, while the 74 random bits make them impractical to guess.
+func NewUserID() UserID {
+	return NewUserIDAt(time.Now())
+}
+
+// NewUserIDAt returns a UUIDv7 for the given creation time. Migrations use
+// it to give existing accounts IDs that keep their signup order.
+func NewUserIDAt(t time.Time) UserID {
+	return UserID(newUUIDv7(t))
+}
+
+// NewTenantID returns a new UUIDv7 for an organization.
+func NewTenantID() TenantID {
+	return TenantID(newUUIDv7(time.Now()))
+}
+
+// NewGroupID returns a new UUIDv7 for a group.
+func NewGroupID() GroupID {
+	return GroupID(newUUIDv7(time.Now()))
+}
+
+// NewInvitationID returns a new UUIDv7 for an invitation.
+func NewInvitationID() InvitationID {
+	return InvitationID(newUUIDv7(time.Now()))
+}
+
+// NewImpersonationID returns a new UUIDv7 for an impersonation.
+func NewImpersonationID() ImpersonationID {
+	return ImpersonationID(newUUIDv7(time.Now()))
+}
+
+// NewSessionID returns a new UUIDv7 for a session.
+func NewSessionID() SessionID {
+	return SessionID(newUUIDv7(time.Now()))
+}
+
+// NewLoginAttemptID returns a new UUIDv7 for a login attempt.
+func NewLoginAttemptID() LoginAttemptID {
+	return LoginAttemptID(newUUIDv7(time.Now()))
+}
+
+// NewMagicLinkID returns a new UUIDv7 for a magic link.
+func NewMagicLinkID() MagicLinkID {
+	return MagicLinkID(newUUIDv7(time.Now()))
+}
+
+func newUUIDv7(t time.Time) string {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic("entity: reading random bytes: " + err.Error())
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixMilli()))
+	copy(b[:6], ms[2:])
+	b[6] = b[6]&0x0f | 0x70 // version 7
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+	return formatUUID(b)
+}
+
+// ParseUserID validates s as a UUID in the 8-4-4-4-12 hex form and returns
+// it in canonical lower case.
+func ParseUserID(s string) (UserID, error) {
+	id, ok := parseUUID(s)
+	if !ok {
+		return "", ErrInvalidUserID
+	}
+	return UserID(id), nil
+}
+
+// ParseTenantID is ParseUserID for tenant IDs.
+func ParseTenantID(s string) (TenantID, error) {
+	id, ok := parseUUID(s)
+	if !ok {
+		return "", ErrInvalidTenantID
+	}
+	return TenantID(id), nil
+}
+
+// ParseGroupID is ParseUserID for group IDs.
+func ParseGroupID(s string) (GroupID, error) {
+	id, ok := parseUUID(s)
+	if !ok {
+		return "", ErrInvalidGroupID
+	}
+	return GroupID(id), nil
+}
+
+// ParseInvitationID is ParseUserID for invitation IDs.
+func ParseInvitationID(s string) (InvitationID, error) {
+	id, ok := parseUUID(s)
+	if !ok {
+		return "", ErrInvalidInvitationID
+	}
+	return InvitationID(id), nil
+}
+
+// ParseImpersonationID is ParseUserID for impersonation IDs.
+func ParseImpersonationID(s string) (ImpersonationID, error) {
+	id, ok := parseUUID(s)
+	if !ok {
+		return "", ErrInvalidImpersonationID
+	}
+	return ImpersonationID(id), nil
+}
+
+// ParseSessionID is ParseUserID for session IDs.
+func ParseSessionID(s string) (SessionID, error) {
+	id, ok := parseUUID(s)
+	if !ok {
+		return "", ErrInvalidSessionID
+	}
+	return SessionID(id), nil
+}
+
+// ParseLoginAttemptID is ParseUserID for login attempt IDs.
+func ParseLoginAttemptID(s string) (LoginAttemptID, error) {
+	id, ok := parseUUID(s)
+	if !ok {
+		return "", ErrInvalidLoginAttemptID
+	}
+	return LoginAttemptID(id), nil
+}
+
+func parseUUID(s string) (string, bool) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return "", false
+	}
+	var b [16]byte
+	if _, err := hex.Decode(b[:], []byte(strings.ReplaceAll(s, "-", ""))); err != nil {
+		return "", false
+	}
+	return formatUUID(b), true
+}
+
+func formatUUID(b [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
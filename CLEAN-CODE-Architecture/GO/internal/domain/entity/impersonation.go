@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ImpersonationID identifies an impersonation. IDs are UUIDv7 strings.
+type ImpersonationID string
+
+// ErrImpersonationEnded is returned when ending an impersonation that has
+// already ended or expired.
+var ErrImpersonationEnded = errors.New("impersonation has already ended")
+
+// Impersonation records an admin acting as another user, so support staff
+// can see what the user sees. It is the audit trail of the session and is
+// kept after it ends.
+type Impersonation struct {
+	ID       ImpersonationID
+	TenantID TenantID
+	// ActorID is the admin; TargetID the user being impersonated
+	ActorID   UserID
+	TargetID  UserID
+	Reason    string
+	StartedAt time.Time
+	ExpiresAt time.Time
+	// EndedAt and EndedBy are set when the impersonation is ended before it
+	// expires, by the actor or another admin
+	EndedAt *time.Time
+	EndedBy *UserID
+}
+
+// NewImpersonation starts an impersonation of target by actor that expires
+// after ttl. The reason is required for the audit trail. The tenant is
+// assigned when it is stored.
+func NewImpersonation(actor, target UserID, reason string, ttl time.Duration) (*Impersonation, error) {
+	if err := ValidateReason(reason); err != nil {
+		return nil, NewFieldError("reason", err)
+	}
+
+	now := time.Now()
+	return &Impersonation{
+		ID:        NewImpersonationID(),
+		ActorID:   actor,
+		TargetID:  target,
+		Reason:    strings.TrimSpace(reason),
+		StartedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// Active reports whether the impersonation may still be used at now.
+func (i *Impersonation) Active(now time.Time) bool {
+	return i.EndedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// End stops an active impersonation; its token stops working.
+func (i *Impersonation) End(by UserID, now time.Time) error {
+	if !i.Active(now) {
+		return ErrImpersonationEnded
+	}
+	i.EndedAt = &now
+	i.EndedBy = &by
+	return nil
+}
@@ -0,0 +1,16 @@
+package entity
+
+// Location is where an IP address is, as far as the geolocation database
+// knows. Every field is empty when the address is unknown, such as a
+// private address or one missing from the database.
+type Location struct {
+	// CountryCode is the ISO 3166-1 alpha-2 code, such as "DE"
+	CountryCode string
+	Country     string
+	City        string
+}
+
+// Known reports whether the country of the location is known.
+func (l Location) Known() bool {
+	return l.CountryCode != ""
+}
@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+	"time"
+)
+
+// LoginAttemptID identifies a login attempt. IDs are UUIDv7 strings, so
+// they sort by time.
+type LoginAttemptID string
+
+// RiskFlag names something unusual about a successful login.
+type RiskFlag string
+
+const (
+	// RiskNewDevice marks a login from a device fingerprint the user never
+	// signed in from before.
+	RiskNewDevice RiskFlag = "new_device"
+	// RiskNewIPRange marks a login from an IP range the user never signed
+	// in from before.
+	RiskNewIPRange RiskFlag = "new_ip_range"
+)
+
+// Login failure reasons recorded on failed attempts. Stopped accounts use
+// "account_" followed by the account status.
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureCountryNotAllowed  = "country_not_allowed"
+	LoginFailureMagicLinkExpired   = "magic_link_expired"
+	LoginFailureMagicLinkUsed      = "magic_link_used"
+)
+
+// LoginAttempt records one sign-in attempt, successful or not. UserID is
+// nil when the identifier matched no account; the identifier is kept as
+// typed so attacks on unknown accounts remain visible.
+type LoginAttempt struct {
+	ID         LoginAttemptID
+	TenantID   TenantID
+	UserID     *UserID
+	Identifier string
+	Success    bool
+	// FailureReason is empty for successful attempts
+	FailureReason string
+	IP            string
+	// IPRange is the network of IP used to recognize familiar locations
+	IPRange     string
+	Location    Location
+	UserAgent   string
+	Device      Device
+	Fingerprint string
+	// RiskFlags and SessionID are only set on successful attempts
+	RiskFlags []RiskFlag
+	SessionID *SessionID
+	CreatedAt time.Time
+}
+
+// NewLoginAttempt records an attempt to sign in as identifier from a client
+// at location. user is nil when no account matched. The attempt is a
+// failure until Succeed is called.
+func NewLoginAttempt(user *User, identifier string, device Device, location Location, userAgent, ip, acceptLanguage string) *LoginAttempt {
+	attempt := &LoginAttempt{
+		ID:          NewLoginAttemptID(),
+		Identifier:  identifier,
+		IP:          ip,
+		IPRange:     IPRange(ip),
+		Location:    location,
+		UserAgent:   userAgent,
+		Device:      device,
+		Fingerprint: DeviceFingerprint(userAgent, acceptLanguage),
+		CreatedAt:   time.Now(),
+	}
+	if user != nil {
+		id := user.ID
+		attempt.UserID = &id
+		attempt.TenantID = user.TenantID
+	}
+	return attempt
+}
+
+// Fail marks the attempt failed for reason.
+func (a *LoginAttempt) Fail(reason string) {
+	a.Success = false
+	a.FailureReason = reason
+}
+
+// Succeed marks the attempt successful, having started session.
+func (a *LoginAttempt) Succeed(session SessionID) {
+	a.Success = true
+	a.FailureReason = ""
+	a.SessionID = &session
+}
+
+// Risky reports whether the attempt carries any risk flag.
+func (a *LoginAttempt) Risky() bool {
+	return len(a.RiskFlags) > 0
+}
+
+// IPRange returns the network ip belongs to for recognizing familiar
+// locations: its /24 for IPv4 and /48 for IPv6. It returns "" for anything
+// that is not an IP address.
+func IPRange(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// DeviceFingerprint identifies a client device from the headers it sends
+// with every request. It is a hex SHA-256, so it reveals nothing by itself.
+// The fingerprint is only a heuristic: browsers that update change it, and
+// identical installations share it.
+func DeviceFingerprint(userAgent, acceptLanguage string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(userAgent) + "\n" + strings.ToLower(strings.TrimSpace(acceptLanguage))))
+	return hex.EncodeToString(sum[:])
+}
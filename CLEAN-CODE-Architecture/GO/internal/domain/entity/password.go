@@ -0,0 +1,33 @@
+package entity
+
+import "errors"
+
+const minPasswordLength = 8
+
+// Password is a plaintext password that passed the domain rules.
+// It never prints its contents, so it is safe to pass around and log;
+// call Reveal only when handing it to a hasher.
+type Password struct {
+	value string
+}
+
+// NewPassword validates raw against the password rules.
+func NewPassword(raw string) (Password, error) {
+	if raw == "" {
+		return Password{}, errors.New("password is required")
+	}
+	if len(raw) < minPasswordLength {
+		return Password{}, errors.New("password must be at least 8 characters")
+	}
+	return Password{value: raw}, nil
+}
+
+// Reveal returns the plaintext for hashing.
+func (p Password) Reveal() string {
+	return p.value
+}
+
+// String redacts the password.
+func (p Password) String() string {
+	return "[REDACTED]"
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	minE164Digits = 7
+	maxE164Digits = 15
+)
+
+// PhoneNumber is a phone number normalized to E.164 ("+" followed by the
+// country code and subscriber number, at most 15 digits).
+// It is obtained through NewPhoneNumber, or RestorePhoneNumber for stored
+// values; the zero value means "no phone".
+type PhoneNumber struct {
+	value string
+}
+
+// NewPhoneNumber normalizes raw to E.164. Spaces, dashes, dots and
+// parentheses are ignored and an international "00" prefix is accepted in
+// place of "+". An empty input yields the zero PhoneNumber.
+func NewPhoneNumber(raw string) (PhoneNumber, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return PhoneNumber{}, nil
+	}
+
+	var digits strings.Builder
+	international := false
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return PhoneNumber{}, errors.New("phone number may only contain digits, spaces and + - . ( )")
+		}
+	}
+
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+	if !international {
+		return PhoneNumber{}, errors.New("phone number must include the country code, e.g. +14155552671")
+	}
+	if strings.HasPrefix(number, "0") {
+		return PhoneNumber{}, errors.New("country code cannot start with 0")
+	}
+	if len(number) < minE164Digits || len(number) > maxE164Digits {
+		return PhoneNumber{}, errors.New("phone number must have between 7 and 15 digits")
+	}
+
+	return PhoneNumber{value: "+" + number}, nil
+}
+
+// RestorePhoneNumber is RestoreUsername for phone numbers. Numbers stored
+// before E.164 was required are kept as they are.
+func RestorePhoneNumber(stored string) PhoneNumber {
+	return PhoneNumber{value: stored}
+}
+
+// String returns the E.164 form, or "" when unset.
+func (p PhoneNumber) String() string {
+	return p.value
+}
+
+// IsZero reports whether no phone number is set.
+func (p PhoneNumber) IsZero() bool {
+	return p.value == ""
+}
@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// SessionID identifies a session. IDs are UUIDv7 strings.
+type SessionID string
+
+// DeviceType is the kind of device a session was started from.
+type DeviceType string
+
+const (
+	DeviceDesktop DeviceType = "desktop"
+	DeviceMobile  DeviceType = "mobile"
+	DeviceTablet  DeviceType = "tablet"
+	DeviceBot     DeviceType = "bot"
+	DeviceUnknown DeviceType = "unknown"
+)
+
+// Device describes the client of a session, as far as its User-Agent tells.
+// Browser and OS are empty when they could not be recognized.
+type Device struct {
+	Type    DeviceType
+	Browser string
+	OS      string
+}
+
+// ErrSessionNotActive is returned when revoking a session that was already
+// revoked or has expired.
+var ErrSessionNotActive = errors.New("session is no longer active")
+
+// Session is one sign-in of a user on a device. It is also the family of the
+// refresh tokens issued for that sign-in: each refresh replaces the token,
+// and revoking the session ends them all.
+type Session struct {
+	ID        SessionID
+	TenantID  TenantID
+	UserID    UserID
+	Device    Device
+	UserAgent string
+	// IP is the client address the session was started from, and Location
+	// where that address is
+	IP         string
+	Location   Location
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// ExpiresAt ends the session however often it is refreshed
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	// RiskFlags are those of the login that started the session
+	RiskFlags []RiskFlag
+}
+
+// NewSession starts a session for user that lasts ttl. The tenant is taken
+// from the user.
+func NewSession(user *User, device Device, userAgent, ip string, ttl time.Duration) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         NewSessionID(),
+		TenantID:   user.TenantID,
+		UserID:     user.ID,
+		Device:     device,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+}
+
+// Active reports whether the session may still be used at now by user, its
+// owner: it is not revoked or expired, and the user's sessions were not
+// revoked after it started.
+func (s *Session) Active(user *User, now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt) && !user.SessionRevoked(s.CreatedAt)
+}
+
+// Revoke ends the session and every refresh token of its family.
+func (s *Session) Revoke(now time.Time) error {
+	if s.RevokedAt != nil || !now.Before(s.ExpiresAt) {
+		return ErrSessionNotActive
+	}
+	s.RevokedAt = &now
+	return nil
+}
+
+// RefreshToken is one token of a session's refresh token family. Only the
+// SHA-256 of the token is stored. A token can be used once; presenting a
+// used token again means it was stolen, and the whole family is revoked.
+type RefreshToken struct {
+	// TokenHash is the hex SHA-256 of the token.
+	TokenHash string
+	SessionID SessionID
+	TenantID  TenantID
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// NewRefreshToken creates an unused token of session's family.
+func NewRefreshToken(session *Session, tokenHash string) *RefreshToken {
+	return &RefreshToken{
+		TokenHash: tokenHash,
+		SessionID: session.ID,
+		TenantID:  session.TenantID,
+		CreatedAt: time.Now(),
+	}
+}
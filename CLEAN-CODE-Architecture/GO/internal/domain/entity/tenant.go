@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// TenantID identifies an organization. IDs are UUIDv7 strings; see
+// NewTenantID and ParseTenantID.
+type TenantID string
+
+// DefaultTenantSlug names the tenant created by the migrations. Accounts
+// from before multi-tenancy belong to it, and its admins manage the other
+// tenants.
+const DefaultTenantSlug = "default"
+
+const (
+	minTenantSlugLength = 2
+	maxTenantSlugLength = 63
+	maxTenantNameLength = 100
+)
+
+// Tenant is an organization whose users are isolated from every other
+// tenant's: emails and usernames only need to be unique within a tenant, and
+// one tenant's users can never see another's.
+type Tenant struct {
+	ID TenantID
+	// Slug is the tenant's short name, used in subdomains and the tenant
+	// header. It is immutable so existing URLs keep working.
+	Slug      TenantSlug
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewTenant creates a tenant, reporting every invalid field at once in a
+// *ValidationError.
+func NewTenant(slug, name string) (*Tenant, error) {
+	verr := &ValidationError{}
+
+	parsedSlug, err := NewTenantSlug(slug)
+	verr.Check("slug", err)
+	name = strings.TrimSpace(name)
+	verr.Check("name", validateTenantName(name))
+
+	if verr.HasErrors() {
+		return nil, verr
+	}
+
+	now := time.Now()
+	return &Tenant{
+		ID:        NewTenantID(),
+		Slug:      parsedSlug,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Rename changes the display name of the tenant.
+func (t *Tenant) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if err := validateTenantName(name); err != nil {
+		return NewFieldError("name", err)
+	}
+	t.Name = name
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+func validateTenantName(name string) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > maxTenantNameLength {
+		return errors.New("name must be at most 100 characters")
+	}
+	return nil
+}
+
+// TenantSlug is a lower-case DNS label, so every tenant can be addressed as
+// a subdomain. It can only be obtained through NewTenantSlug.
+type TenantSlug struct {
+	value string
+}
+
+// NewTenantSlug validates raw as a DNS label of 2 to 63 characters: ASCII
+// letters, digits and inner hyphens. It is lower-cased.
+func NewTenantSlug(raw string) (TenantSlug, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return TenantSlug{}, errors.New("slug is required")
+	}
+	if len(value) < minTenantSlugLength || len(value) > maxTenantSlugLength {
+		return TenantSlug{}, errors.New("slug must be between 2 and 63 characters")
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return TenantSlug{}, errors.New("slug may only contain letters, digits and hyphens")
+		}
+	}
+	if strings.HasPrefix(value, "-") || strings.HasSuffix(value, "-") {
+		return TenantSlug{}, errors.New("slug must start and end with a letter or digit")
+	}
+	return TenantSlug{value: value}, nil
+}
+
+// String returns the normalized slug.
+func (s TenantSlug) String() string {
+	return s.value
+}
+
+// IsZero reports whether the slug is unset.
+func (s TenantSlug) IsZero() bool {
+	return s.value == ""
+}
@@ -1,60 +1,85 @@
 package entity
 
 import (
-	"errors"
-	"strconv"
 	"strings"
 	"time"
 )
 
 // UserID represents a unique identifier for a user
 // This abstraction allows different storage implementations
+// IDs are UUIDv7 strings; see NewUserID and ParseUserID.
 type UserID string
 
 // User represents our core domain entity, completely independent of any framework
-// This entity focuses purely on business logic and domain rules
+// This entity focuses purely on business logic and domain rules.
+// Username, Email and Phone are value objects, so a User can never hold an
+// address or handle that failed validation.
 type User struct {
-	ID        UserID
-	Username  string
-	FirstName string
-	LastName  string
-	Email     string
-	Phone     string
-	Address   string
-	Password  string
+	ID UserID
+	// TenantID is the organization the user belongs to. Repositories set it
+	// from the request's tenant when the user is created.
+	TenantID   TenantID
+	Username   Username
+	FirstName  string
+	LastName   string
+	Email      Email
+	Phone      PhoneNumber
+	Address    string
+	Password   string // password hash once persisted
 	ProfilePic string
+	Role       Role
+	Status     AccountStatus
+	// StatusReason explains why a non-active account was stopped, and
+	// StatusUntil ends a temporary suspension; see Suspend
+	StatusReason    string
+	StatusUntil     *time.Time
+	StatusChangedAt *time.Time
+	// SessionsRevokedAt invalidates sessions and tokens issued before it
+	SessionsRevokedAt *time.Time
+	// EmailVerifiedAt is nil until the email address has been confirmed
+	EmailVerifiedAt *time.Time
+	LastLoginAt     *time.Time
+	// Version counts stored revisions; updates based on an older version
+	// are rejected (optimistic concurrency control)
+	Version int64
 	// Audit fields - these could be moved to a separate concern
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
 // NewUser creates a new user with validation
-// This constructor ensures business rules are followed
+// This constructor ensures business rules are followed.
+// Every invalid field is reported at once in a *ValidationError.
 func NewUser(username, email, password string) (*User, error) {
-	if err := validateEmail(email); err != nil {
-		return nil, err
-	}
-	
-	if err := validateUsername(username); err != nil {
-		return nil, err
-	}
-	
-	if err := validatePassword(password); err != nil {
-		return nil, err
+	verr := &ValidationError{}
+
+	parsedEmail, err := NewEmail(email)
+	verr.Check("email", err)
+	parsedUsername, err := NewUsername(username)
+	verr.Check("username", err)
+	parsedPassword, err := NewPassword(password)
+	verr.Check("password", err)
+
+	if verr.HasErrors() {
+		return nil, verr
 	}
-	
+
 	now := time.Now()
 	return &User{
-		Username:  username,
-		Email:     email,
-		Password:  password, // In real app, this should be hashed
+		ID:        NewUserIDAt(now),
+		Username:  parsedUsername,
+		Email:     parsedEmail,
+		Password:  parsedPassword.Reveal(), // hashed by the registration use case
+		Role:      RoleUser,
+		Status:    StatusActive,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
 }
 
 // UpdateProfile updates user profile information
-func (u *User) UpdateProfile(firstName, lastName, phone, address string) {
+func (u *User) UpdateProfile(firstName, lastName string, phone PhoneNumber, address string) {
 	u.FirstName = firstName
 	u.LastName = lastName
 	u.Phone = phone
@@ -62,68 +87,35 @@ func (u *User) UpdateProfile(firstName, lastName, phone, address string) {
 	u.UpdatedAt = time.Now()
 }
 
-// ChangePassword changes user password with validation
-func (u *User) ChangePassword(newPassword string) error {
-	if err := validatePassword(newPassword); err != nil {
-		return err
-	}
-	u.Password = newPassword // In real app, hash this
+// SetPasswordHash replaces the stored password hash. Validation and hashing
+// of the new password happen in the use case, against the password policy.
+func (u *User) SetPasswordHash(hash string) {
+	u.Password = hash
 	u.UpdatedAt = time.Now()
-	return nil
 }
 
-// GetFullName returns the full name of the user
-func (u *User) GetFullName() string {
-	return strings.TrimSpace(u.FirstName + " " + u.LastName)
-}
-
-// IsValid checks if the user entity is valid
-func (u *User) IsValid() bool {
-	return u.Username != "" && u.Email != "" && u.Password != ""
+// RecordLogin notes a successful sign-in.
+func (u *User) RecordLogin(at time.Time) {
+	u.LastLoginAt = &at
 }
 
-// Domain validation functions
-func validateEmail(email string) error {
-	if email == "" {
-		return errors.New("email is required")
-	}
-	if !strings.Contains(email, "@") {
-		return errors.New("invalid email format.")
-	}
-	return nil
+// MarkEmailVerified records that the user proved ownership of the email.
+func (u *User) MarkEmailVerified(at time.Time) {
+	u.EmailVerifiedAt = &at
+	u.UpdatedAt = at
 }
 
-func validateUsername(username string) error {
-	if username == "" {
-		return errors.New("username is required")
-	}
-	if len(username) < 3 {
-		return errors.New("username must be at least 3 characters")
-	}
-	return nil
+// IsEmailVerified reports whether the email address has been confirmed.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
-func validatePassword(password string) error {
-	if password == "" {
-		return errors.New("password is required")
-	}
-	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters")
-	}
-	return nil
+// GetFullName returns the full name of the user
+func (u *User) GetFullName() string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
 }
 
-// ParseUserIDToUint converts UserID to uint for database operations
-// This helper function is used by infrastructure layer to convert domain types
-func ParseUserIDToUint(id UserID) (uint, error) {
-	if id == "" {
-		return 0, errors.New("empty user ID")
-	}
-	
-	parsedID, err := strconv.ParseUint(string(id), 10, 32)
-	if err != nil {
-		return 0, errors.New("invalid user ID format")
-	}
-	
-	return uint(parsedID), nil
+// IsValid checks if the user entity is valid
+func (u *User) IsValid() bool {
+	return !u.Username.IsZero() && !u.Email.IsZero() && u.Password != ""
 }
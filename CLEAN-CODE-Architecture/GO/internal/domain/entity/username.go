@@ -0,0 +1,210 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 32
+)
+
+// reservedUsernames cannot be registered because they could be mistaken for
+// the service itself or collide with routes. Entries are compared by
+// skeleton, so look-alikes such as "adm1n" or "r00t" are caught too.
+var reservedUsernames = []string{
+	"admin", "administrator", "root", "system", "support", "help", "security",
+	"moderator", "staff", "api", "www", "mail", "postmaster", "abuse",
+	"noreply", "me", "login", "register", "null", "undefined", "anonymous",
+}
+
+// reservedSkeletons holds the skeleton of every reserved username.
+var reservedSkeletons = func() map[string]bool {
+	set := make(map[string]bool, len(reservedUsernames))
+	for _, name := range reservedUsernames {
+		set[skeleton(name)] = true
+	}
+	return set
+}()
+
+// confusables maps non-Latin letters that render like Latin letters to the
+// letter they imitate. Digits that commonly stand in for letters, and the
+// i/l pair, are folded too so the reserved-name check cannot be bypassed.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x',
+	'і': 'i', 'ј': 'j', 'ѕ': 's', 'һ': 'h', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ӏ': 'l',
+	// Greek
+	'α': 'a', 'ο': 'o', 'ν': 'v', 'ι': 'i', 'κ': 'k', 'ρ': 'p', 'υ': 'u', 'χ': 'x',
+	// Digits
+	'0': 'o', '1': 'l', 'i': 'l',
+}
+
+// Username is a normalized account handle that passed the domain rules.
+// It is obtained through NewUsername, or RestoreUsername for stored values.
+type Username struct {
+	value string
+}
+
+// NewUsername validates and normalizes raw:
+//   - Unicode NFKC normalization and lower-casing, so full-width or
+//     compatibility forms collapse to one canonical spelling
+//   - letters, digits and the separators '.', '_' and '-' only; it must start
+//     and end with a letter or digit and may not repeat separators
+//   - letters from scripts no language mixes (such as Latin with Cyrillic),
+//     or non-Latin letters that all imitate Latin ones, are rejected as
+//     confusable; Japanese, Chinese and Korean names may mix their scripts
+//   - reserved names (and their look-alikes) are rejected
+func NewUsername(raw string) (Username, error) {
+	value := strings.ToLower(norm.NFKC.String(strings.TrimSpace(raw)))
+	if value == "" {
+		return Username{}, errors.New("username is required")
+	}
+
+	length := utf8.RuneCountInString(value)
+	if length < minUsernameLength {
+		return Username{}, errors.New("username must be at least 3 characters")
+	}
+	if length > maxUsernameLength {
+		return Username{}, errors.New("username must be at most 32 characters")
+	}
+
+	if err := validateUsernameCharset(value); err != nil {
+		return Username{}, err
+	}
+	if isConfusable(value) {
+		return Username{}, errors.New("username contains characters that can be confused with other letters")
+	}
+	if reservedSkeletons[skeleton(value)] {
+		return Username{}, errors.New("username is reserved")
+	}
+
+	return Username{value: value}, nil
+}
+
+// RestoreUsername rebuilds a Username read back from storage without
+// applying the rules again. Rows written before a rule was added or
+// tightened must keep loading; only repositories should call it.
+func RestoreUsername(stored string) Username {
+	return Username{value: stored}
+}
+
+// String returns the normalized username.
+func (u Username) String() string {
+	return u.value
+}
+
+// IsZero reports whether the username is unset.
+func (u Username) IsZero() bool {
+	return u.value == ""
+}
+
+func isUsernameSeparator(r rune) bool {
+	return r == '.' || r == '_' || r == '-'
+}
+
+func validateUsernameCharset(value string) error {
+	var prev rune
+	for i, r := range value {
+		switch {
+		case unicode.IsLetter(r), unicode.Is(unicode.Nd, r):
+		case isUsernameSeparator(r):
+			if i == 0 || isUsernameSeparator(prev) {
+				return errors.New("username may not start with or repeat '.', '_' or '-'")
+			}
+		default:
+			return errors.New("username may only contain letters, digits, '.', '_' and '-'")
+		}
+		prev = r
+	}
+	if isUsernameSeparator(prev) {
+		return errors.New("username may not end with '.', '_' or '-'")
+	}
+	return nil
+}
+
+// allowedScriptMixes are the combinations of scripts a username may mix,
+// after UTS #39's "highly restrictive" level: the scripts written together
+// in Japanese, Chinese and Korean, each optionally with Latin. Any other mix,
+// such as Latin with Cyrillic, is how look-alike spoofs are built.
+var allowedScriptMixes = []map[string]bool{
+	{"Latin": true, "Han": true, "Hiragana": true, "Katakana": true},
+	{"Latin": true, "Han": true, "Bopomofo": true},
+	{"Latin": true, "Han": true, "Hangul": true},
+}
+
+// isConfusable reports whether value mixes scripts in a way no language
+// does (see allowedScriptMixes), or consists solely of non-Latin letters
+// that imitate Latin ones (a whole-script spoof such as Cyrillic "аdmin").
+// Characters shared by scripts, such as digits and the Katakana-Hiragana
+// prolonged sound mark, do not count towards a script.
+func isConfusable(value string) bool {
+	scripts := make(map[string]bool)
+	allMimicLatin := true
+	for _, r := range value {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		s := scriptOf(r)
+		if s == "Common" || s == "Inherited" {
+			continue
+		}
+		scripts[s] = true
+		if _, ok := confusables[r]; !ok || s == "Latin" {
+			allMimicLatin = false
+		}
+	}
+
+	switch {
+	case len(scripts) == 0:
+		return false
+	case len(scripts) == 1:
+		return !scripts["Latin"] && allMimicLatin
+	}
+	for _, allowed := range allowedScriptMixes {
+		if isSubset(scripts, allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSubset(scripts, of map[string]bool) bool {
+	for s := range scripts {
+		if !of[s] {
+			return false
+		}
+	}
+	return true
+}
+
+// scriptOf returns the Unicode script name for r.
+func scriptOf(r rune) string {
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+// skeleton maps confusable characters to their Latin counterpart and drops
+// separators, giving a form suitable for comparing against reserved names.
+func skeleton(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if isUsernameSeparator(r) {
+			continue
+		}
+		if latin, ok := confusables[r]; ok {
+			r = latin
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
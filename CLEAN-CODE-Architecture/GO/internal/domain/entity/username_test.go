@@ -0,0 +1,32 @@
+package entity
+
+import "testing"
+
+func TestNewUsernameScripts(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		valid    bool
+	}{
+		{"latin", "alice.smith", true},
+		{"cyrillic", "наталья", true},
+		{"greek", "σωκράτης", true},
+		{"japanese kanji and hiragana", "やまだ太郎", true},
+		{"japanese with katakana and prolonged sound mark", "田中スーパー", true},
+		{"japanese with latin", "taro山田", true},
+		{"korean hangul and han", "김민준金", true},
+		{"chinese han and bopomofo", "王ㄅㄆ", true},
+		{"latin and cyrillic", "pаypal", false},
+		{"latin and greek", "gοogle", false},
+		{"hangul and katakana", "김カタ", false},
+		{"whole-script cyrillic spoof", "аре", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewUsername(tt.username)
+			if valid := err == nil; valid != tt.valid {
+				t.Errorf("NewUsername(%q) error = %v, want valid %v", tt.username, err, tt.valid)
+			}
+		})
+	}
+}
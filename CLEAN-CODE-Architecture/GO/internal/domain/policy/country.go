@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"auth-module/internal/domain/service"
+)
+
+// ErrCountryNotAllowed is returned for logins from a country the policy
+// refuses.
+var ErrCountryNotAllowed = errors.New("logins from this country are not allowed")
+
+// CountryPolicyConfig configures a CountryPolicy. Countries are ISO 3166-1
+// alpha-2 codes.
+type CountryPolicyConfig struct {
+	// Allowed, when not empty, are the only countries logins may come from.
+	Allowed []string
+	// Denied are countries logins may not come from.
+	Denied []string
+	// AllowUnknown lets logins through when the country of the client is
+	// unknown, such as from private addresses. It only matters with an
+	// allow list; addresses of unknown location are never denied.
+	AllowUnknown bool
+}
+
+// CountryPolicy decides whether a login may come from where the client
+// is. The location is looked up through a service.GeoLocator, so the policy
+// is only as accurate as its database.
+type CountryPolicy struct {
+	allowed      map[string]bool
+	denied       map[string]bool
+	allowUnknown bool
+	locator      service.GeoLocator
+}
+
+// NewCountryPolicy builds a policy from cfg. It returns nil without error
+// when cfg neither allows nor denies any country, as there is nothing to
+// enforce; otherwise locator must be non-nil.
+func NewCountryPolicy(cfg CountryPolicyConfig, locator service.GeoLocator) (*CountryPolicy, error) {
+	allowed, err := countrySet(cfg.Allowed)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := countrySet(cfg.Denied)
+	if err != nil {
+		return nil, err
+	}
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil, nil
+	}
+	if locator == nil {
+		return nil, errors.New("country policy needs a geolocation database")
+	}
+	return &CountryPolicy{allowed: allowed, denied: denied, allowUnknown: cfg.AllowUnknown, locator: locator}, nil
+}
+
+// Check returns nil when a login from ip is allowed, or
+// ErrCountryNotAllowed. Denied countries are refused even when allowed.
+func (p *CountryPolicy) Check(ip string) error {
+	location := p.locator.Locate(ip)
+	switch {
+	case !location.Known():
+		if len(p.allowed) > 0 && !p.allowUnknown {
+			return ErrCountryNotAllowed
+		}
+	case p.denied[location.CountryCode]:
+		return ErrCountryNotAllowed
+	case len(p.allowed) > 0 && !p.allowed[location.CountryCode]:
+		return ErrCountryNotAllowed
+	}
+	return nil
+}
+
+// countrySet validates and upper-cases country codes, skipping blanks.
+func countrySet(codes []string) (map[string]bool, error) {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, fmt.Errorf("invalid country code %q", code)
+		}
+		set[code] = true
+	}
+	return set, nil
+}
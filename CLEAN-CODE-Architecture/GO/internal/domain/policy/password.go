@@ -0,0 +1,263 @@
+// Package policy holds configurable business policies that use cases enforce.
+// It is part of the Domain Layer: rules are pure Go and any I/O they need
+// (such as a breached-password lookup) is reached through a service port.
+package policy
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"auth-module/internal/domain/service"
+)
+
+// Violation codes returned in PasswordViolation.Code. Clients can key
+// translations or UI hints off these.
+const (
+	CodeTooShort      = "too_short"
+	CodeTooLong       = "too_long"
+	CodeMissingUpper  = "missing_uppercase"
+	CodeMissingLower  = "missing_lowercase"
+	CodeMissingDigit  = "missing_digit"
+	CodeMissingSymbol = "missing_symbol"
+	CodeContainsName  = "contains_username"
+	CodeContainsEmail = "contains_email"
+	CodeTooWeak       = "too_weak"
+	CodeBreached      = "breached"
+)
+
+// PasswordCandidate is a password together with the account data it must
+// not resemble.
+type PasswordCandidate struct {
+	Password string
+	Username string
+	Email    string
+}
+
+// PasswordViolation is one reason a password was rejected.
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError is returned when a password breaks one or more rules.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return "password does not meet the policy: " + strings.Join(msgs, "; ")
+}
+
+// PasswordRule is a single pluggable check. Rules that need I/O return an
+// error for infrastructure failures; rule violations are returned as values.
+type PasswordRule interface {
+	Check(ctx context.Context, c PasswordCandidate) ([]PasswordViolation, error)
+}
+
+// PasswordPolicyConfig configures the built-in rules.
+type PasswordPolicyConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// ForbidPersonalInfo rejects passwords containing the username or the
+	// local part of the email address.
+	ForbidPersonalInfo bool
+	// MinStrength is the minimum EstimateStrength score (0-4).
+	MinStrength int
+}
+
+// DefaultPasswordPolicyConfig returns the policy used when nothing is configured.
+// MaxLength (in bytes) leaves room for passphrases while bounding the work a
+// request can ask of the hasher. Argon2id hashes every byte; deployments
+// that hash new passwords with bcrypt must lower it to bcrypt's 72.
+func DefaultPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength:          8,
+		MaxLength:          128,
+		RequireUpper:       false,
+		RequireLower:       false,
+		RequireDigit:       false,
+		RequireSymbol:      false,
+		ForbidPersonalInfo: true,
+		MinStrength:        2,
+	}
+}
+
+// PasswordPolicy runs an ordered set of rules against password candidates.
+// Registration, password change and password reset all go through the same
+// PasswordPolicy so they cannot drift apart.
+type PasswordPolicy struct {
+	rules []PasswordRule
+}
+
+// NewPasswordPolicy builds the built-in rules from cfg. When breached is
+// non-nil, passwords found in the breached-password list are rejected too.
+func NewPasswordPolicy(cfg PasswordPolicyConfig, breached service.BreachedPasswordChecker) *PasswordPolicy {
+	p := &PasswordPolicy{}
+	p.Use(LengthRule{Min: cfg.MinLength, Max: cfg.MaxLength})
+	p.Use(CharacterClassRule{
+		Upper:  cfg.RequireUpper,
+		Lower:  cfg.RequireLower,
+		Digit:  cfg.RequireDigit,
+		Symbol: cfg.RequireSymbol,
+	})
+	if cfg.ForbidPersonalInfo {
+		p.Use(PersonalInfoRule{})
+	}
+	if cfg.MinStrength > 0 {
+		p.Use(StrengthRule{MinScore: cfg.MinStrength})
+	}
+	if breached != nil {
+		p.Use(BreachedRule{Checker: breached})
+	}
+	return p
+}
+
+// Use appends a custom rule to the policy.
+func (p *PasswordPolicy) Use(rule PasswordRule) {
+	p.rules = append(p.rules, rule)
+}
+
+// Validate runs every rule and returns a *PasswordPolicyError listing all
+// violations, nil when the password is acceptable, or the first
+// infrastructure error raised by a rule.
+func (p *PasswordPolicy) Validate(ctx context.Context, c PasswordCandidate) error {
+	var violations []PasswordViolation
+	for _, rule := range p.rules {
+		found, err := rule.Check(ctx, c)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, found...)
+	}
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// LengthRule bounds the password length. Min counts characters; Max counts
+// bytes because that is what hashing algorithms consume. Zero disables a bound.
+type LengthRule struct {
+	Min int
+	Max int
+}
+
+func (r LengthRule) Check(_ context.Context, c PasswordCandidate) ([]PasswordViolation, error) {
+	if r.Min > 0 && utf8.RuneCountInString(c.Password) < r.Min {
+		return []PasswordViolation{{Code: CodeTooShort, Message: "password is too short"}}, nil
+	}
+	if r.Max > 0 && len(c.Password) > r.Max {
+		return []PasswordViolation{{Code: CodeTooLong, Message: "password is too long"}}, nil
+	}
+	return nil, nil
+}
+
+// CharacterClassRule requires at least one character from each enabled class.
+type CharacterClassRule struct {
+	Upper  bool
+	Lower  bool
+	Digit  bool
+	Symbol bool
+}
+
+func (r CharacterClassRule) Check(_ context.Context, c PasswordCandidate) ([]PasswordViolation, error) {
+	classes := classify(c.Password)
+	var out []PasswordViolation
+	if r.Upper && !classes.upper {
+		out = append(out, PasswordViolation{Code: CodeMissingUpper, Message: "password must contain an uppercase letter"})
+	}
+	if r.Lower && !classes.lower {
+		out = append(out, PasswordViolation{Code: CodeMissingLower, Message: "password must contain a lowercase letter"})
+	}
+	if r.Digit && !classes.digit {
+		out = append(out, PasswordViolation{Code: CodeMissingDigit, Message: "password must contain a digit"})
+	}
+	if r.Symbol && !classes.symbol {
+		out = append(out, PasswordViolation{Code: CodeMissingSymbol, Message: "password must contain a symbol"})
+	}
+	return out, nil
+}
+
+// PersonalInfoRule rejects passwords that contain the username or the local
+// part of the email address, compared case-insensitively.
+type PersonalInfoRule struct{}
+
+// minPersonalInfoLength avoids rejecting passwords over very short names.
+const minPersonalInfoLength = 3
+
+func (PersonalInfoRule) Check(_ context.Context, c PasswordCandidate) ([]PasswordViolation, error) {
+	password := strings.ToLower(c.Password)
+	var out []PasswordViolation
+
+	if name := strings.ToLower(c.Username); len(name) >= minPersonalInfoLength && strings.Contains(password, name) {
+		out = append(out, PasswordViolation{Code: CodeContainsName, Message: "password must not contain your username"})
+	}
+
+	local := strings.ToLower(c.Email)
+	if at := strings.LastIndexByte(local, '@'); at >= 0 {
+		local = local[:at]
+	}
+	if len(local) >= minPersonalInfoLength && strings.Contains(password, local) {
+		out = append(out, PasswordViolation{Code: CodeContainsEmail, Message: "password must not contain your email address"})
+	}
+	return out, nil
+}
+
+// StrengthRule rejects passwords whose EstimateStrength score is below MinScore.
+type StrengthRule struct {
+	MinScore int
+}
+
+func (r StrengthRule) Check(_ context.Context, c PasswordCandidate) ([]PasswordViolation, error) {
+	if EstimateStrength(c.Password) < r.MinScore {
+		return []PasswordViolation{{Code: CodeTooWeak, Message: "password is too easy to guess"}}, nil
+	}
+	return nil, nil
+}
+
+// BreachedRule rejects passwords that appear in a known breach corpus.
+type BreachedRule struct {
+	Checker service.BreachedPasswordChecker
+}
+
+func (r BreachedRule) Check(ctx context.Context, c PasswordCandidate) ([]PasswordViolation, error) {
+	breached, err := r.Checker.IsBreached(ctx, c.Password)
+	if err != nil {
+		return nil, err
+	}
+	if breached {
+		return []PasswordViolation{{Code: CodeBreached, Message: "password has appeared in a data breach"}}, nil
+	}
+	return nil, nil
+}
+
+type charClasses struct {
+	upper, lower, digit, symbol bool
+}
+
+func classify(password string) charClasses {
+	var c charClasses
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			c.upper = true
+		case unicode.IsLower(r):
+			c.lower = true
+		case unicode.IsDigit(r):
+			c.digit = true
+		default:
+			c.symbol = true
+		}
+	}
+	return c
+}
@@ -0,0 +1,149 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/service"
+)
+
+// RegistrationMode says who may create an account.
+type RegistrationMode string
+
+const (
+	// RegistrationOpen lets anyone register.
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationRestricted lets anyone with an email in an allowed domain
+	// register.
+	RegistrationRestricted RegistrationMode = "restricted"
+	// RegistrationInviteOnly only creates accounts from invitations.
+	RegistrationInviteOnly RegistrationMode = "invite_only"
+	// RegistrationClosed creates no accounts at all, not even from
+	// invitations. Admins can still import users.
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+// ParseRegistrationMode validates a registration mode name.
+func ParseRegistrationMode(s string) (RegistrationMode, error) {
+	switch m := RegistrationMode(s); m {
+	case RegistrationOpen, RegistrationRestricted, RegistrationInviteOnly, RegistrationClosed:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown registration mode %q", s)
+}
+
+// RegistrationChannel is how an account is being created.
+type RegistrationChannel string
+
+const (
+	// ChannelSelfService is registration through /register.
+	ChannelSelfService RegistrationChannel = "self_service"
+	// ChannelInvitation is accepting an admin's invitation.
+	ChannelInvitation RegistrationChannel = "invitation"
+)
+
+var (
+	// ErrRegistrationClosed is returned when no accounts may be created.
+	ErrRegistrationClosed = errors.New("registration is closed")
+	// ErrInvitationRequired is returned for self-service registration when
+	// accounts are only created from invitations.
+	ErrInvitationRequired = errors.New("registration requires an invitation")
+	// ErrEmailDomainNotAllowed is returned for self-service registration
+	// with an email outside the allowed domains.
+	ErrEmailDomainNotAllowed = errors.New("email domain is not allowed to register")
+	// ErrDisposableEmail is returned for self-service registration with a
+	// disposable email address.
+	ErrDisposableEmail = errors.New("disposable email addresses cannot register")
+)
+
+// RegistrationPolicyConfig configures a RegistrationPolicy.
+type RegistrationPolicyConfig struct {
+	Mode RegistrationMode
+	// AllowedDomains are the email domains that may register in
+	// RegistrationRestricted mode. A domain also allows its subdomains.
+	AllowedDomains []string
+}
+
+// RegistrationPolicy decides whether an account may be created. Every use
+// case that creates accounts on a user's behalf checks it, so /register and
+// invitations cannot disagree. Invitations are only refused when
+// registration is closed: the admin chose the address, so domain rules do
+// not apply to them.
+type RegistrationPolicy struct {
+	mode       RegistrationMode
+	allowed    []string
+	disposable service.DisposableEmailChecker
+}
+
+// NewRegistrationPolicy builds a policy from cfg. When disposable is
+// non-nil, self-service registration with a disposable address is refused.
+// Restricted mode needs at least one valid allowed domain.
+func NewRegistrationPolicy(cfg RegistrationPolicyConfig, disposable service.DisposableEmailChecker) (*RegistrationPolicy, error) {
+	if cfg.Mode == "" {
+		cfg.Mode = RegistrationOpen
+	}
+	if _, err := ParseRegistrationMode(string(cfg.Mode)); err != nil {
+		return nil, err
+	}
+
+	p := &RegistrationPolicy{mode: cfg.Mode, disposable: disposable}
+	for _, domain := range cfg.AllowedDomains {
+		if strings.TrimSpace(domain) == "" {
+			continue
+		}
+		normalized, err := entity.NormalizeEmailDomain(domain)
+		if err != nil {
+			return nil, fmt.Errorf("allowed domain %q: %w", domain, err)
+		}
+		p.allowed = append(p.allowed, normalized)
+	}
+	if p.mode == RegistrationRestricted && len(p.allowed) == 0 {
+		return nil, errors.New("restricted registration needs at least one allowed domain")
+	}
+	return p, nil
+}
+
+// Mode returns the configured registration mode.
+func (p *RegistrationPolicy) Mode() RegistrationMode {
+	return p.mode
+}
+
+// Check returns nil when an account for email may be created through
+// channel, or one of ErrRegistrationClosed, ErrInvitationRequired,
+// ErrEmailDomainNotAllowed and ErrDisposableEmail. The mode is checked
+// before the email, and an unparseable email is left to entity validation.
+func (p *RegistrationPolicy) Check(channel RegistrationChannel, email string) error {
+	if p.mode == RegistrationClosed {
+		return ErrRegistrationClosed
+	}
+	if channel == ChannelInvitation {
+		return nil
+	}
+	if p.mode == RegistrationInviteOnly {
+		return ErrInvitationRequired
+	}
+
+	parsed, err := entity.NewEmail(email)
+	if err != nil {
+		return nil
+	}
+	domain := parsed.Domain()
+	if p.mode == RegistrationRestricted && !p.isAllowed(domain) {
+		return ErrEmailDomainNotAllowed
+	}
+	if p.disposable != nil && p.disposable.IsDisposable(domain) {
+		return ErrDisposableEmail
+	}
+	return nil
+}
+
+func (p *RegistrationPolicy) isAllowed(domain string) bool {
+	for _, allowed := range p.allowed {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
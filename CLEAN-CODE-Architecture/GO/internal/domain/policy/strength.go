@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"math"
+	"unicode"
+)
+
+// Character pool sizes used by the entropy estimate.
+const (
+	poolLower   = 26
+	poolUpper   = 26
+	poolDigit   = 10
+	poolSymbol  = 33
+	poolUnicode = 100 // rough allowance for letters outside ASCII
+)
+
+// EstimateStrength scores a password from 0 (trivial) to 4 (very strong)
+// using EstimateEntropy. The bands are deliberately conservative: it is a
+// guard against obviously weak choices, not a replacement for the breached
+// password check.
+func EstimateStrength(password string) int {
+	bits := EstimateEntropy(password)
+	switch {
+	case bits < 28:
+		return 0
+	case bits < 40:
+		return 1
+	case bits < 60:
+		return 2
+	case bits < 80:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// EstimateEntropy approximates the password's entropy in bits as
+// effective length × log2(character pool). Characters that repeat the
+// previous one or continue a run ("aaa", "abc", "321") only count a quarter,
+// since attackers try those patterns first.
+func EstimateEntropy(password string) float64 {
+	var (
+		pool      int
+		classes   charClasses
+		nonASCII  bool
+		effective float64
+		prev      rune = -1
+	)
+
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			nonASCII = true
+		case unicode.IsUpper(r):
+			classes.upper = true
+		case unicode.IsLower(r):
+			classes.lower = true
+		case unicode.IsDigit(r):
+			classes.digit = true
+		default:
+			classes.symbol = true
+		}
+
+		if prev >= 0 && (r == prev || r == prev+1 || r == prev-1) {
+			effective += 0.25
+		} else {
+			effective++
+		}
+		prev = r
+	}
+
+	if classes.lower {
+		pool += poolLower
+	}
+	if classes.upper {
+		pool += poolUpper
+	}
+	if classes.digit {
+		pool += poolDigit
+	}
+	if classes.symbol {
+		pool += poolSymbol
+	}
+	if nonASCII {
+		pool += poolUnicode
+	}
+	if pool == 0 {
+		return 0
+	}
+	return effective * math.Log2(float64(pool))
+}
@@ -0,0 +1,53 @@
+package repository
+
+// Errors returned by repository implementations.
+// - Adapters translate storage-specific failures (e.g. Postgres unique violations)
+//   into these domain errors so use cases never inspect driver error codes.
+
+import (
+	"errors"
+	"fmt"
+
+	"auth-module/internal/domain/entity"
+)
+
+// ErrNotFound is returned by writes that reference a record that does not
+// exist, such as a membership for an unknown group or user.
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is matched (via errors.Is) by every ConflictError.
+var ErrConflict = errors.New("conflict")
+
+// ConflictError reports that a write was rejected because a unique field
+// (such as email or username) is already used by another record.
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Field + " is already in use"
+}
+
+// Is lets callers test for any conflict with errors.Is(err, ErrConflict).
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ErrVersionConflict is matched (via errors.Is) by every VersionConflictError.
+var ErrVersionConflict = errors.New("version conflict")
+
+// VersionConflictError reports that an update was based on a stale copy:
+// the record was changed or deleted after Version was read.
+type VersionConflictError struct {
+	ID      entity.UserID
+	Version int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("user %s was modified concurrently (update based on version %d)", e.ID, e.Version)
+}
+
+// Is lets callers test with errors.Is(err, ErrVersionConflict).
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"auth-module/internal/domain/entity"
+)
+
+// GroupRepository stores groups and their memberships. Like UserRepository,
+// every method is scoped to the tenant in ctx and returns ErrNoTenant
+// without one.
+type GroupRepository interface {
+	// Create stores group in the context's tenant; a taken name is a
+	// *ConflictError on "name".
+	Create(ctx context.Context, group *entity.Group) error
+	GetByID(ctx context.Context, id entity.GroupID) (*entity.Group, error)
+
+	// AddMember adds a user to a group, or changes the role of an existing
+	// member. It returns ErrNotFound unless both the user and the group
+	// belong to the context's tenant.
+	AddMember(ctx context.Context, membership *entity.Membership) error
+	// GetMembership returns nil when the user is not a member.
+	GetMembership(ctx context.Context, group entity.GroupID, user entity.UserID) (*entity.Membership, error)
+	// RemoveMember reports whether the user was a member.
+	RemoveMember(ctx context.Context, group entity.GroupID, user entity.UserID) (bool, error)
+	// ListMembers returns one page of a group's members ordered by
+	// (JoinedAt, UserID).
+	ListMembers(ctx context.Context, query MemberPageQuery) (*MemberPage, error)
+	// ListUserGroups returns every group user belongs to, ordered by name.
+	ListUserGroups(ctx context.Context, user entity.UserID) ([]UserGroup, error)
+}
+
+// MemberCursor is the position after the last member of a page.
+type MemberCursor struct {
+	JoinedAt time.Time
+	UserID   entity.UserID
+}
+
+// MemberPageQuery selects a page of a group's members.
+type MemberPageQuery struct {
+	GroupID entity.GroupID
+	Limit   int
+	// After is nil for the first page.
+	After *MemberCursor
+}
+
+// MemberPage is one page of members plus the cursor of the next page, which
+// is nil on the last one.
+type MemberPage struct {
+	Members []*entity.Membership
+	Next    *MemberCursor
+}
+
+// UserGroup is a group seen from one of its members.
+type UserGroup struct {
+	Group *entity.Group
+	Role  entity.GroupRole
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"auth-module/internal/domain/entity"
+)
+
+// ImpersonationFilter selects impersonations by the admin or the user
+// impersonated; nil fields match everything.
+type ImpersonationFilter struct {
+	ActorID  *entity.UserID
+	TargetID *entity.UserID
+}
+
+// ImpersonationRepository stores the impersonation audit trail. Every method
+// is scoped to the tenant in ctx and returns ErrNoTenant without one.
+type ImpersonationRepository interface {
+	Create(ctx context.Context, impersonation *entity.Impersonation) error
+	GetByID(ctx context.Context, id entity.ImpersonationID) (*entity.Impersonation, error)
+	// Update stores the end of an impersonation.
+	Update(ctx context.Context, impersonation *entity.Impersonation) error
+	// List returns the impersonations matching filter, most recent first.
+	List(ctx context.Context, filter ImpersonationFilter) ([]*entity.Impersonation, error)
+}
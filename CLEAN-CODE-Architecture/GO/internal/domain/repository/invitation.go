@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"auth-module/internal/domain/entity"
+)
+
+// InvitationRepository stores invitations. Every method except
+// GetByTokenHash is scoped to the tenant in ctx and returns ErrNoTenant
+// without one.
+type InvitationRepository interface {
+	// Create stores invitation in the context's tenant. A second pending
+	// invitation for the same email is a *ConflictError on "invitation".
+	Create(ctx context.Context, invitation *entity.Invitation) error
+	GetByID(ctx context.Context, id entity.InvitationID) (*entity.Invitation, error)
+	// GetPending returns the pending invitation for email, expired or not,
+	// or nil.
+	GetPending(ctx context.Context, email entity.Email) (*entity.Invitation, error)
+	// GetByTokenHash finds an invitation in any tenant: the link token is
+	// what tells an accepting invitee's request which tenant it is for.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error)
+	Update(ctx context.Context, invitation *entity.Invitation) error
+	// List returns the tenant's invitations, newest first.
+	List(ctx context.Context) ([]*entity.Invitation, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"auth-module/internal/domain/entity"
+)
+
+// LoginFamiliarity tells how a new login compares with the user's earlier
+// successful logins.
+type LoginFamiliarity struct {
+	// PreviousLogins is false for the user's first successful login
+	PreviousLogins bool
+	KnownDevice    bool
+	KnownIPRange   bool
+}
+
+// LoginAttemptQuery selects a page of a user's login attempts, newest
+// first. Before, when set, excludes that attempt and everything after it.
+type LoginAttemptQuery struct {
+	UserID entity.UserID
+	Before entity.LoginAttemptID
+	Limit  int
+}
+
+// LoginAttemptRepository stores the login history. Methods are scoped to
+// the tenant in ctx and return ErrNoTenant without one.
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *entity.LoginAttempt) error
+	List(ctx context.Context, query LoginAttemptQuery) ([]*entity.LoginAttempt, error)
+	// Familiarity compares fingerprint and ipRange with the user's earlier
+	// successful logins.
+	Familiarity(ctx context.Context, user entity.UserID, fingerprint, ipRange string) (LoginFamiliarity, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"auth-module/internal/domain/entity"
+)
+
+// MagicLinkRepository stores magic links. Every method except
+// GetByTokenHash is scoped to the tenant in ctx and returns ErrNoTenant
+// without one.
+type MagicLinkRepository interface {
+	// CreateWithinLimit stores link in the context's tenant unless its user
+	// already has limit links created at or after since, and reports
+	// whether it did. Counting and storing are one atomic step, so
+	// concurrent calls for one user never store more than limit links.
+	CreateWithinLimit(ctx context.Context, link *entity.MagicLink, since time.Time, limit int) (bool, error)
+	// GetByTokenHash finds a link in any tenant, or returns nil: the link
+	// token is what tells a sign-in request which tenant it is for.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MagicLink, error)
+	// Use marks the link used at now unless it already was, and reports
+	// whether this call did. Of concurrent calls for one link only one
+	// succeeds.
+	Use(ctx context.Context, id entity.MagicLinkID, now time.Time) (bool, error)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"time"
+
+	"auth-module/internal/domain/entity"
+)
+
+// CursorDirection says which side of a cursor a page is read from.
+type CursorDirection string
+
+const (
+	// CursorNext reads the page that follows the cursor.
+	CursorNext CursorDirection = "next"
+	// CursorPrev reads the page that precedes the cursor.
+	CursorPrev CursorDirection = "prev"
+)
+
+// UserSortField is one of the allow-listed columns users can be ordered by.
+type UserSortField string
+
+const (
+	SortByCreatedAt UserSortField = "created_at"
+	SortByUsername  UserSortField = "username"
+	SortByEmail     UserSortField = "email"
+	SortByLastLogin UserSortField = "last_login"
+	// SortByRelevance orders by how well users match UserFilter.Search and
+	// is only valid together with a search.
+	SortByRelevance UserSortField = "relevance"
+)
+
+// Valid reports whether f is an allow-listed sort field.
+func (f UserSortField) Valid() bool {
+	switch f {
+	case SortByCreatedAt, SortByUsername, SortByEmail, SortByLastLogin, SortByRelevance:
+		return true
+	}
+	return false
+}
+
+// UserSort orders a listing. Ties are always broken by id in the same
+// direction, so the order is total and safe for keyset pagination.
+type UserSort struct {
+	Field UserSortField
+	Desc  bool
+}
+
+// DefaultUserSort is the oldest-first order used when none is requested.
+var DefaultUserSort = UserSort{Field: SortByCreatedAt}
+
+// RelevanceSort puts the best search matches first.
+var RelevanceSort = UserSort{Field: SortByRelevance, Desc: true}
+
+// UserFilter narrows a listing. Zero-valued fields do not filter.
+type UserFilter struct {
+	// CreatedFrom and CreatedTo bound created_at as [from, to).
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// EmailDomain matches the normalized domain part of the email exactly.
+	EmailDomain string
+	// Phone matches the phone number exactly.
+	Phone entity.PhoneNumber
+	// Verified selects users whose email is (or is not) verified.
+	Verified *bool
+	Role     *entity.Role
+	Status   *entity.AccountStatus
+	// Search optionally restricts the listing to users matching the text.
+	Search string
+}
+
+// UserCursor is a position in a sorted user listing. It identifies the last
+// (or first) user of a page by its sort key and id.
+type UserCursor struct {
+	Sort UserSort
+	// Key is the boundary user's sort value, as returned by SortKey or
+	// reported in UserPage.Keys.
+	Key       string
+	ID        entity.UserID
+	Direction CursorDirection
+}
+
+// SortKey returns the value of field for user in the canonical string form
+// stored in cursors. Timestamps use RFC 3339 with nanoseconds; a missing
+// last login sorts as the Unix epoch. Relevance is not a property of the user
+// and is reported by the repository in UserPage.Keys instead.
+func SortKey(user *entity.User, field UserSortField) string {
+	switch field {
+	case SortByUsername:
+		return user.Username.String()
+	case SortByEmail:
+		return user.Email.String()
+	case SortByLastLogin:
+		if user.LastLoginAt == nil {
+			return time.Unix(0, 0).UTC().Format(time.RFC3339Nano)
+		}
+		return user.LastLoginAt.UTC().Format(time.RFC3339Nano)
+	default:
+		return user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// UserPageQuery selects a page of users.
+type UserPageQuery struct {
+	Limit int
+	// Cursor is nil for the first page. When set, its Sort takes precedence
+	// over the query's Sort so a page sequence keeps a consistent order.
+	Cursor *UserCursor
+	Filter UserFilter
+	Sort   UserSort
+}
+
+// UserPage is one page of users plus whether more exist on either side.
+type UserPage struct {
+	Users []*entity.User
+	Sort  UserSort
+	// Limit is the page size the query was run with.
+	Limit int
+	// Keys holds each user's sort key when it cannot be derived from the
+	// user (relevance); it is nil otherwise.
+	Keys    []string
+	HasNext bool
+	HasPrev bool
+}
+
+// NextCursor returns the cursor for the following page, or nil if there is none.
+func (p *UserPage) NextCursor() *UserCursor {
+	if !p.HasNext || len(p.Users) == 0 {
+		return nil
+	}
+	return p.cursorAt(len(p.Users)-1, CursorNext)
+}
+
+// PrevCursor returns the cursor for the preceding page, or nil if there is none.
+func (p *UserPage) PrevCursor() *UserCursor {
+	if !p.HasPrev || len(p.Users) == 0 {
+		return nil
+	}
+	return p.cursorAt(0, CursorPrev)
+}
+
+func (p *UserPage) cursorAt(i int, direction CursorDirection) *UserCursor {
+	key := SortKey(p.Users[i], p.Sort.Field)
+	if p.Keys != nil {
+		key = p.Keys[i]
+	}
+	return &UserCursor{
+		Sort:      p.Sort,
+		Key:       key,
+		ID:        p.Users[i].ID,
+		Direction: direction,
+	}
+}
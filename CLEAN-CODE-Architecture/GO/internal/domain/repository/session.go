@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"auth-module/internal/domain/entity"
+)
+
+// SessionRepository stores sessions and their refresh tokens. Methods are
+// scoped to the tenant in ctx and return ErrNoTenant without one, except
+// GetRefreshToken and TouchLastSeen, which work across tenants.
+type SessionRepository interface {
+	// Create stores a new session together with its first refresh token.
+	Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error
+	GetByID(ctx context.Context, id entity.SessionID) (*entity.Session, error)
+	// ListByUser returns the user's sessions that are not revoked and have
+	// not expired at now, most recently seen first.
+	ListByUser(ctx context.Context, user entity.UserID, now time.Time) ([]*entity.Session, error)
+	// Update stores the revocation of a session.
+	Update(ctx context.Context, session *entity.Session) error
+
+	// GetRefreshToken finds a refresh token in any tenant: the token is what
+	// tells a refresh request which tenant it is for.
+	GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	// UseRefreshToken marks the token used at now. It reports false when the
+	// token was already used, which makes concurrent refreshes with the same
+	// token fail for all but one.
+	UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (bool, error)
+	// CreateRefreshToken stores the next token of a session's family.
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error
+
+	// TouchLastSeen moves the last-seen time of each session forward to the
+	// given time, in any tenant. Unknown sessions are skipped.
+	TouchLastSeen(ctx context.Context, seen map[entity.SessionID]time.Time) error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"auth-module/internal/domain/entity"
+)
+
+// ErrNoTenant is returned by tenant-scoped repositories when the context
+// does not name a tenant. They fail closed rather than read across tenants.
+var ErrNoTenant = errors.New("no tenant selected")
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose repository calls are restricted to
+// tenant. Every UserRepository method only sees and writes that tenant's
+// users.
+func WithTenant(ctx context.Context, tenant entity.TenantID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by WithTenant.
+func TenantFromContext(ctx context.Context) (entity.TenantID, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(entity.TenantID)
+	return tenant, ok && tenant != ""
+}
+
+// RequireTenant is TenantFromContext returning ErrNoTenant when unset.
+func RequireTenant(ctx context.Context) (entity.TenantID, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return "", ErrNoTenant
+	}
+	return tenant, nil
+}
+
+// TenantRepository stores organizations. Unlike UserRepository it is not
+// scoped to a tenant.
+type TenantRepository interface {
+	// Create stores tenant; a taken slug is a *ConflictError on "slug".
+	Create(ctx context.Context, tenant *entity.Tenant) error
+	GetByID(ctx context.Context, id entity.TenantID) (*entity.Tenant, error)
+	GetBySlug(ctx context.Context, slug entity.TenantSlug) (*entity.Tenant, error)
+	List(ctx context.Context) ([]*entity.Tenant, error)
+	Update(ctx context.Context, tenant *entity.Tenant) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import "context"
+
+// TxManager runs a unit of work atomically across repository calls.
+//   - Use cases depend on this port to group several writes (and the reads
+//     they are based on) without knowing how the store implements transactions.
+//   - The transaction travels in the context: repository calls made with the
+//     ctx handed to fn take part in it, calls made with any other ctx do not.
+type TxManager interface {
+	// WithinTx runs fn in a transaction, committing if fn returns nil and
+	// rolling back if it returns an error or panics. A call nested inside
+	// another WithinTx joins the outer transaction; if the inner fn fails,
+	// only its own changes are rolled back and the outer fn decides whether
+	// to carry on.
+	//
+	// The ctx passed to fn must not be used after fn returns or shared with
+	// other goroutines.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -5,6 +5,8 @@ package repository
 // - It uses the Repository Pattern to abstract data storage details from business logic.
 // - Enables Dependency Injection: use cases depend on this interface, not a concrete DB.
 // - Makes the code testable and decoupled from infrastructure (e.g., Postgres, MongoDB, etc).
+// - Every method is scoped to the tenant in ctx (see WithTenant) and returns
+//   ErrNoTenant without one; users of other tenants are invisible.
 
 import (
 	"auth-module/internal/domain/entity"
@@ -14,14 +16,35 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) (*entity.User, error)
 	GetByID(ctx context.Context, id entity.UserID) (*entity.User, error)
-	GetByEmail(ctx context.Context, email string) (*entity.User, error)
-	GetByUsername(ctx context.Context, username string) (*entity.User, error)
+	GetByEmail(ctx context.Context, email entity.Email) (*entity.User, error)
+	// GetByEmails returns the users registered with any of emails, in no
+	// particular order; emails nobody registered are skipped.
+	GetByEmails(ctx context.Context, emails []entity.Email) ([]*entity.User, error)
+	GetByUsername(ctx context.Context, username entity.Username) (*entity.User, error)
+	// Update stores user only if the stored version still equals
+	// user.Version, then increments user.Version. Otherwise it returns a
+	// *VersionConflictError and changes nothing.
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id entity.UserID) error
-	List(ctx context.Context, limit, offset int) ([]*entity.User, error)
 	
 	// Additional methods for better user management
 	Count(ctx context.Context) (int64, error)
-	Search(ctx context.Context, query string, limit, offset int) ([]*entity.User, error)
 	GetByEmailOrUsername(ctx context.Context, emailOrUsername string) (*entity.User, error)
+	// GetByLegacyID finds an account by its pre-UUID integer ID.
+	GetByLegacyID(ctx context.Context, legacyID int64) (*entity.User, error)
+
+	// ListPage returns one filtered, sorted page of users, positioned by a
+	// keyset cursor rather than an offset.
+	ListPage(ctx context.Context, query UserPageQuery) (*UserPage, error)
+	// CountMatching counts the users selected by filter.
+	CountMatching(ctx context.Context, filter UserFilter) (int64, error)
+
+	// UpsertBatch stores users in one transaction. When an email is already
+	// registered only the existing account's profile (username, names,
+	// phone and address) is updated; its password, role and status are
+	// kept, since those only change through their own use cases. A user
+	// that cannot be stored (e.g. its username belongs to another account)
+	// does not abort the batch; its error is reported at the same index in
+	// errs. err is only set when the batch as a whole failed.
+	UpsertBatch(ctx context.Context, users []*entity.User) (errs []error, err error)
 }
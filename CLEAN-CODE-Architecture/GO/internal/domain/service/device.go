@@ -0,0 +1,9 @@
+package service
+
+import "auth-module/internal/domain/entity"
+
+// DeviceDetector describes the client behind a User-Agent header.
+// Implementations live in the infrastructure layer.
+type DeviceDetector interface {
+	Detect(userAgent string) entity.Device
+}
@@ -0,0 +1,8 @@
+package service
+
+// DisposableEmailChecker reports whether an email domain belongs to a
+// disposable (throwaway) email provider. domain is the normalized domain of
+// the address. Implementations live in the infrastructure layer.
+type DisposableEmailChecker interface {
+	IsDisposable(domain string) bool
+}
@@ -0,0 +1,11 @@
+package service
+
+import "auth-module/internal/domain/entity"
+
+// GeoLocator finds where IP addresses are. It sits on the login path, so
+// implementations answer from local data without network calls.
+// Implementations live in the infrastructure layer.
+type GeoLocator interface {
+	// Locate returns the zero Location for addresses it does not know.
+	Locate(ip string) entity.Location
+}
@@ -0,0 +1,16 @@
+package service
+
+import "context"
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Implementations live in the infrastructure layer;
+// Send returns once the message is handed to the mail system.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"auth-module/internal/domain/entity"
+)
+
+// LoginNotifier tells users about sign-ins to their account they may not
+// have made themselves. Implementations live in the infrastructure layer.
+type LoginNotifier interface {
+	// NotifyLogin reports attempt, a successful login with risk flags.
+	NotifyLogin(ctx context.Context, user *entity.User, attempt *entity.LoginAttempt) error
+}
@@ -0,0 +1,39 @@
+package service
+
+// Domain service ports.
+// - Interfaces here describe capabilities the use cases need (hashing, etc.)
+//   without tying them to a concrete library.
+// - Implementations live in pkg/ or the infrastructure layer and are injected from main.
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrHasherOverloaded is returned by a PasswordHasher that is at capacity.
+// Callers should fail fast (e.g. HTTP 503) rather than queue more work.
+var ErrHasherOverloaded = errors.New("password hashing is at capacity, try again later")
+
+// PasswordHasher turns plaintext passwords into storable hashes and verifies them.
+// Hash and Compare are CPU-heavy; implementations may bound concurrency and
+// return ErrHasherOverloaded or the context's error instead of doing the work.
+// Compare reports a mismatch as (false, nil).
+type PasswordHasher interface {
+	Hash(ctx context.Context, password string) (string, error)
+	Compare(ctx context.Context, password, hash string) (bool, error)
+	// CompareDummy does the same work as Compare against a hash that never
+	// matches, with the algorithm and cost of a stored hash. Login calls it
+	// for unknown accounts so response time does not reveal whether an
+	// email is registered; identifier is the looked-up email, and the same
+	// identifier always costs the same.
+	CompareDummy(ctx context.Context, identifier, password string) error
+	// NeedsRehash reports whether hash was made with an outdated algorithm
+	// or parameters and should be replaced once the plaintext is known.
+	NeedsRehash(hash string) bool
+}
+
+// BreachedPasswordChecker reports whether a password is known to have
+// appeared in a data breach.
+type BreachedPasswordChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
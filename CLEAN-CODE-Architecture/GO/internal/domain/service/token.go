@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"auth-module/internal/domain/entity"
+)
+
+// ErrInvalidToken is returned for malformed, forged or expired tokens.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// AccessClaims are what an access token asserts about its bearer.
+type AccessClaims struct {
+	UserID entity.UserID
+	// TenantID is the tenant the user belongs to; the token is only valid
+	// for requests to that tenant.
+	TenantID entity.TenantID
+	Role     entity.Role
+	// Groups lists the names of the user's groups when the token was issued
+	// with them; it is nil otherwise.
+	Groups []string
+	// SessionID is the session the token was issued for; impersonation
+	// tokens belong to no session.
+	SessionID entity.SessionID
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// ActorID and ImpersonationID are set on impersonation tokens: ActorID
+	// is the admin acting as UserID.
+	ActorID         entity.UserID
+	ImpersonationID entity.ImpersonationID
+}
+
+// Impersonated reports whether the token was issued for an admin acting as
+// the user.
+func (c *AccessClaims) Impersonated() bool {
+	return c.ImpersonationID != ""
+}
+
+// TokenService issues and verifies the short-lived access tokens that
+// authenticate API requests.
+type TokenService interface {
+	// IssueAccessToken signs a token for user within session. groups, when
+	// non-nil, is carried as the groups claim.
+	IssueAccessToken(user *entity.User, session entity.SessionID, groups []string) (token string, claims AccessClaims, err error)
+	// IssueImpersonationToken signs a token for the impersonated user that
+	// names the admin as its actor and expires with the impersonation.
+	IssueImpersonationToken(user *entity.User, impersonation *entity.Impersonation) (token string, claims AccessClaims, err error)
+	// ParseAccessToken returns ErrInvalidToken for any token it cannot
+	// trust. ctx is the request's; implementations that look the bearer up
+	// use it for those lookups.
+	ParseAccessToken(ctx context.Context, token string) (*AccessClaims, error)
+}
@@ -0,0 +1,94 @@
+// Package breach provides an offline breached-password list.
+// This is part of the Infrastructure Layer: it implements the
+// service.BreachedPasswordChecker port on top of a local file.
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	sha1HexLength = 40
+	prefixLength  = 5
+)
+
+// FileChecker answers breached-password lookups from a local list of SHA-1
+// hashes in the Have I Been Pwned format: one hex SHA-1 per line, optionally
+// followed by ":count". Blank lines and lines starting with '#' are ignored.
+//
+// Hashes are bucketed by their first five hex characters, mirroring the
+// k-anonymity range API: a lookup hashes the password, selects the bucket for
+// its prefix and only compares suffixes within it. Plaintext passwords are
+// never stored.
+type FileChecker struct {
+	buckets map[string]map[string]struct{}
+	count   int
+}
+
+// LoadFile reads the breached-password list at path.
+func LoadFile(path string) (*FileChecker, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	c := &FileChecker{buckets: make(map[string]map[string]struct{})}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.IndexByte(text, ':'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.ToUpper(text)
+		if len(text) != sha1HexLength {
+			return nil, fmt.Errorf("breached password list line %d: expected a SHA-1 hex digest", line)
+		}
+		if _, err := hex.DecodeString(text); err != nil {
+			return nil, fmt.Errorf("breached password list line %d: %w", line, err)
+		}
+		c.add(text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+	return c, nil
+}
+
+// Len returns the number of hashes loaded.
+func (c *FileChecker) Len() int {
+	return c.count
+}
+
+// IsBreached reports whether password's SHA-1 appears in the list.
+func (c *FileChecker) IsBreached(_ context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	bucket := c.buckets[digest[:prefixLength]]
+	_, found := bucket[digest[prefixLength:]]
+	return found, nil
+}
+
+func (c *FileChecker) add(digest string) {
+	prefix, suffix := digest[:prefixLength], digest[prefixLength:]
+	bucket, ok := c.buckets[prefix]
+	if !ok {
+		bucket = make(map[string]struct{})
+		c.buckets[prefix] = bucket
+	}
+	if _, dup := bucket[suffix]; !dup {
+		bucket[suffix] = struct{}{}
+		c.count++
+	}
+}
@@ -1,9 +1,11 @@
 package database
 
 import (
+	"auth-module/internal/domain/entity"
 	"auth-module/internal/infrastructure/database/models"
 	"fmt"
 	"log"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -21,26 +23,213 @@ func NewMigrator(db *gorm.DB) *Migrator {
 // RunMigrations executes all database migrations
 func (m *Migrator) RunMigrations() error {
 	log.Println("Running database migrations...")
-	
-	err := m.db.AutoMigrate(
+
+	// citext backs the case-insensitive unique indexes on users and
+	// pg_trgm the similarity search
+	for _, extension := range []string{"citext", "pg_trgm"} {
+		if err := m.db.Exec("CREATE EXTENSION IF NOT EXISTS " + extension).Error; err != nil {
+			return fmt.Errorf("failed to enable %s extension: %w", extension, err)
+		}
+	}
+
+	if err := m.migrateLegacyUserIDs(); err != nil {
+		return fmt.Errorf("failed to convert user IDs to UUIDs: %w", err)
+	}
+
+	if err := m.db.AutoMigrate(&models.TenantModel{}); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	defaultTenant, err := m.ensureDefaultTenant()
+	if err != nil {
+		return fmt.Errorf("failed to create the default tenant: %w", err)
+	}
+	if err := m.migrateUsersToTenants(defaultTenant); err != nil {
+		return fmt.Errorf("failed to assign users to the default tenant: %w", err)
+	}
+
+	err = m.db.AutoMigrate(
 		&models.UserModel{},
+		&models.GroupModel{},
+		&models.MembershipModel{},
+		&models.InvitationModel{},
+		&models.ImpersonationModel{},
+		&models.SessionModel{},
+		&models.RefreshTokenModel{},
+		&models.LoginAttemptModel{},
+		&models.MagicLinkModel{},
 		// Add other models here as you create them
 	)
 	
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
+
+	if err := m.addForeignKey("users", "fk_users_tenant", "FOREIGN KEY (tenant_id) REFERENCES tenants (id)"); err != nil {
+		return fmt.Errorf("failed to add users tenant key: %w", err)
+	}
+	// Deleting a user or group deletes its memberships, and deleting a user
+	// its sessions, login history and magic links; invitations to a deleted group still create the account
+	for _, key := range []struct{ table, name, definition string }{
+		{"groups", "fk_groups_tenant", "FOREIGN KEY (tenant_id) REFERENCES tenants (id)"},
+		{"group_members", "fk_group_members_group", "FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE"},
+		{"group_members", "fk_group_members_user", "FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE"},
+		{"invitations", "fk_invitations_tenant", "FOREIGN KEY (tenant_id) REFERENCES tenants (id)"},
+		{"invitations", "fk_invitations_group", "FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE SET NULL"},
+		{"impersonations", "fk_impersonations_tenant", "FOREIGN KEY (tenant_id) REFERENCES tenants (id)"},
+		{"sessions", "fk_sessions_tenant", "FOREIGN KEY (tenant_id) REFERENCES tenants (id)"},
+		{"sessions", "fk_sessions_user", "FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE"},
+		{"refresh_tokens", "fk_refresh_tokens_session", "FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE"},
+		{"login_attempts", "fk_login_attempts_tenant", "FOREIGN KEY (tenant_id) REFERENCES tenants (id)"},
+		{"login_attempts", "fk_login_attempts_user", "FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE"},
+		{"magic_links", "fk_magic_links_tenant", "FOREIGN KEY (tenant_id) REFERENCES tenants (id)"},
+		{"magic_links", "fk_magic_links_user", "FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE"},
+	} {
+		if err := m.addForeignKey(key.table, key.name, key.definition); err != nil {
+			return fmt.Errorf("failed to add %s: %w", key.name, err)
+		}
+	}
+
+	// Expression indexes are beyond what AutoMigrate can express
+	for _, statement := range models.UserSearchIndexes {
+		if err := m.db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
 	
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
+// migrateLegacyUserIDs converts an integer users.id column to UUIDs. Each
+// account keeps its old ID in legacy_id, and its new UUIDv7 is derived from
+// created_at so ID order still follows signup order. The conversion runs in
+// one transaction and is skipped once id is a uuid column or if the table
+// does not exist yet.
+func (m *Migrator) migrateLegacyUserIDs() error {
+	var dataType string
+	err := m.db.Raw(`SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'id'`).Scan(&dataType).Error
+	if err != nil {
+		return err
+	}
+	if dataType != "integer" && dataType != "bigint" {
+		return nil
+	}
+
+	log.Println("Converting integer user IDs to UUIDs...")
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE users ADD COLUMN IF NOT EXISTS legacy_id bigint, ADD COLUMN new_id uuid").Error; err != nil {
+			return err
+		}
+
+		var rows []struct {
+			ID        int64
+			CreatedAt time.Time
+		}
+		if err := tx.Raw("SELECT id, created_at FROM users").Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			newID := string(entity.NewUserIDAt(row.CreatedAt))
+			if err := tx.Exec("UPDATE users SET new_id = ?, legacy_id = id WHERE id = ?", newID, row.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// Dropping id also drops its sequence, the primary key and the
+		// keyset index; AutoMigrate recreates the index afterwards
+		for _, statement := range []string{
+			"ALTER TABLE users DROP COLUMN id",
+			"ALTER TABLE users RENAME COLUMN new_id TO id",
+			"ALTER TABLE users ALTER COLUMN id SET NOT NULL",
+			"ALTER TABLE users ADD PRIMARY KEY (id)",
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		log.Printf("Converted %d user IDs", len(rows))
+		return nil
+	})
+}
+
+// ensureDefaultTenant creates the tenant named entity.DefaultTenantSlug if
+// it does not exist yet and returns its ID.
+func (m *Migrator) ensureDefaultTenant() (string, error) {
+	var model models.TenantModel
+	err := m.db.Where("slug = ?", entity.DefaultTenantSlug).Limit(1).Find(&model).Error
+	if err != nil || model.ID != "" {
+		return model.ID, err
+	}
+
+	tenant, err := entity.NewTenant(entity.DefaultTenantSlug, "Default")
+	if err != nil {
+		return "", err
+	}
+	log.Println("Creating the default tenant...")
+	model = *models.TenantFromEntity(tenant)
+	return model.ID, m.db.Create(&model).Error
+}
+
+// migrateUsersToTenants adds users.tenant_id to a users table created before
+// multi-tenancy, assigning every existing account to the default tenant, and
+// drops the global unique and keyset indexes that the per-tenant ones
+// replace. It runs in one transaction and is skipped once the column exists
+// or if the table does not exist yet.
+func (m *Migrator) migrateUsersToTenants(defaultTenant string) error {
+	if !m.db.Migrator().HasTable(&models.UserModel{}) || m.db.Migrator().HasColumn(&models.UserModel{}, "TenantID") {
+		return nil
+	}
+
+	log.Println("Assigning existing users to the default tenant...")
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE users ADD COLUMN tenant_id uuid").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE users SET tenant_id = ?", defaultTenant).Error; err != nil {
+			return err
+		}
+		for _, statement := range []string{
+			"ALTER TABLE users ALTER COLUMN tenant_id SET NOT NULL",
+			"DROP INDEX IF EXISTS idx_users_email",
+			"DROP INDEX IF EXISTS idx_users_username",
+			"DROP INDEX IF EXISTS idx_users_created_at_id",
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// addForeignKey adds a named constraint to table unless it already exists.
+func (m *Migrator) addForeignKey(table, name, definition string) error {
+	var exists bool
+	if err := m.db.Raw("SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = ?)", name).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return m.db.Exec("ALTER TABLE " + table + " ADD CONSTRAINT " + name + " " + definition).Error
+}
+
 // DropTables drops all tables (useful for development)
 func (m *Migrator) DropTables() error {
 	log.Println("Dropping database tables...")
 	
 	err := m.db.Migrator().DropTable(
+		&models.MagicLinkModel{},
+		&models.LoginAttemptModel{},
+		&models.RefreshTokenModel{},
+		&models.SessionModel{},
+		&models.ImpersonationModel{},
+		&models.InvitationModel{},
+		&models.MembershipModel{},
+		&models.GroupModel{},
 		&models.UserModel{},
+		&models.TenantModel{},
 	)
 	
 	if err != nil {
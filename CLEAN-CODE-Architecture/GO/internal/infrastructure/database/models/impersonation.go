@@ -0,0 +1,64 @@
+package models
+
+import (
+	"auth-module/internal/domain/entity"
+	"time"
+)
+
+// ImpersonationModel is the database form of entity.Impersonation.
+// ActorID and TargetID have no foreign keys on purpose: the audit trail must
+// survive the deletion of either account.
+type ImpersonationModel struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	TenantID  string    `gorm:"type:uuid;not null;index:idx_impersonations_tenant_started_at,priority:1"`
+	ActorID   string    `gorm:"type:uuid;not null;index"`
+	TargetID  string    `gorm:"type:uuid;not null;index"`
+	Reason    string    `gorm:"type:varchar(500);not null"`
+	StartedAt time.Time `gorm:"not null;index:idx_impersonations_tenant_started_at,priority:2"`
+	ExpiresAt time.Time `gorm:"not null"`
+	EndedAt   *time.Time
+	EndedBy   *string `gorm:"type:uuid"`
+}
+
+// TableName returns the table name for GORM
+func (ImpersonationModel) TableName() string {
+	return "impersonations"
+}
+
+// ToEntity converts the GORM model to a domain entity.
+func (m *ImpersonationModel) ToEntity() *entity.Impersonation {
+	impersonation := &entity.Impersonation{
+		ID:        entity.ImpersonationID(m.ID),
+		TenantID:  entity.TenantID(m.TenantID),
+		ActorID:   entity.UserID(m.ActorID),
+		TargetID:  entity.UserID(m.TargetID),
+		Reason:    m.Reason,
+		StartedAt: m.StartedAt,
+		ExpiresAt: m.ExpiresAt,
+		EndedAt:   m.EndedAt,
+	}
+	if m.EndedBy != nil {
+		user := entity.UserID(*m.EndedBy)
+		impersonation.EndedBy = &user
+	}
+	return impersonation
+}
+
+// ImpersonationFromEntity converts a domain entity to a GORM model.
+func ImpersonationFromEntity(i *entity.Impersonation) *ImpersonationModel {
+	model := &ImpersonationModel{
+		ID:        string(i.ID),
+		TenantID:  string(i.TenantID),
+		ActorID:   string(i.ActorID),
+		TargetID:  string(i.TargetID),
+		Reason:    i.Reason,
+		StartedAt: i.StartedAt,
+		ExpiresAt: i.ExpiresAt,
+		EndedAt:   i.EndedAt,
+	}
+	if i.EndedBy != nil {
+		user := string(*i.EndedBy)
+		model.EndedBy = &user
+	}
+	return model
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"auth-module/internal/domain/entity"
+	"fmt"
+	"time"
+)
+
+// InvitationModel is the database form of entity.Invitation.
+// idx_invitations_pending_email is a partial unique index, so an email can
+// have any number of closed invitations but only one pending. TokenHash is
+// unique across tenants because accepting looks it up without a tenant.
+type InvitationModel struct {
+	ID         string    `gorm:"type:uuid;primaryKey"`
+	TenantID   string    `gorm:"type:uuid;not null;index:idx_invitations_tenant_created_at,priority:1;uniqueIndex:idx_invitations_pending_email,priority:1,where:status = 'pending'"`
+	Email      string    `gorm:"type:citext;not null;uniqueIndex:idx_invitations_pending_email,priority:2"`
+	InvitedBy  string    `gorm:"type:uuid;not null"`
+	Role       string    `gorm:"type:varchar(20);not null;default:user"`
+	GroupID    *string   `gorm:"type:uuid"`
+	TokenHash  string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_invitations_token_hash"`
+	Status     string    `gorm:"type:varchar(20);not null;default:pending"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	SentAt     *time.Time
+	AcceptedAt *time.Time
+	AcceptedBy *string   `gorm:"type:uuid"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index:idx_invitations_tenant_created_at,priority:2"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (InvitationModel) TableName() string {
+	return "invitations"
+}
+
+// InvitationPendingEmailIndex is the partial unique index on pending
+// invitations per (tenant_id, email).
+const InvitationPendingEmailIndex = "idx_invitations_pending_email"
+
+// ToEntity converts the GORM model to a domain entity.
+func (m *InvitationModel) ToEntity() (*entity.Invitation, error) {
+	role, err := entity.ParseRole(m.Role)
+	if err != nil {
+		return nil, fmt.Errorf("invitation %s: %w", m.ID, err)
+	}
+	status, err := entity.ParseInvitationStatus(m.Status)
+	if err != nil {
+		return nil, fmt.Errorf("invitation %s: %w", m.ID, err)
+	}
+
+	invitation := &entity.Invitation{
+		ID:         entity.InvitationID(m.ID),
+		TenantID:   entity.TenantID(m.TenantID),
+		Email:      entity.RestoreEmail(m.Email),
+		InvitedBy:  entity.UserID(m.InvitedBy),
+		Role:       role,
+		TokenHash:  m.TokenHash,
+		Status:     status,
+		ExpiresAt:  m.ExpiresAt,
+		SentAt:     m.SentAt,
+		AcceptedAt: m.AcceptedAt,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+	if m.GroupID != nil {
+		group := entity.GroupID(*m.GroupID)
+		invitation.GroupID = &group
+	}
+	if m.AcceptedBy != nil {
+		user := entity.UserID(*m.AcceptedBy)
+		invitation.AcceptedBy = &user
+	}
+	return invitation, nil
+}
+
+// InvitationFromEntity converts a domain entity to a GORM model.
+func InvitationFromEntity(i *entity.Invitation) *InvitationModel {
+	model := &InvitationModel{
+		ID:         string(i.ID),
+		TenantID:   string(i.TenantID),
+		Email:      i.Email.String(),
+		InvitedBy:  string(i.InvitedBy),
+		Role:       string(i.Role),
+		TokenHash:  i.TokenHash,
+		Status:     string(i.Status),
+		ExpiresAt:  i.ExpiresAt,
+		SentAt:     i.SentAt,
+		AcceptedAt: i.AcceptedAt,
+		CreatedAt:  i.CreatedAt,
+		UpdatedAt:  i.UpdatedAt,
+	}
+	if i.GroupID != nil {
+		group := string(*i.GroupID)
+		model.GroupID = &group
+	}
+	if i.AcceptedBy != nil {
+		user := string(*i.AcceptedBy)
+		model.AcceptedBy = &user
+	}
+	return model
+}
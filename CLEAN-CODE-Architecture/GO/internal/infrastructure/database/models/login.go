@@ -0,0 +1,141 @@
+package models
+
+import (
+	"auth-module/internal/domain/entity"
+	"strings"
+	"time"
+)
+
+// LoginAttemptModel is the database form of entity.LoginAttempt.
+// idx_login_attempts_tenant_user_id pages through a user's history and
+// backs the familiarity checks together with the fingerprint and range
+// indexes. Deleting the user deletes the history (see the migrator).
+type LoginAttemptModel struct {
+	ID            string    `gorm:"type:uuid;primaryKey;index:idx_login_attempts_tenant_user_id,priority:3"`
+	TenantID      string    `gorm:"type:uuid;not null;index:idx_login_attempts_tenant_user_id,priority:1"`
+	UserID        *string   `gorm:"type:uuid;index:idx_login_attempts_tenant_user_id,priority:2;index:idx_login_attempts_user_fingerprint,priority:1;index:idx_login_attempts_user_ip_range,priority:1"`
+	Identifier    string    `gorm:"type:varchar(254);not null"`
+	Success       bool      `gorm:"not null"`
+	FailureReason string    `gorm:"type:varchar(40)"`
+	IP            string    `gorm:"type:varchar(45)"`
+	IPRange       string    `gorm:"type:varchar(49);index:idx_login_attempts_user_ip_range,priority:2"`
+	CountryCode   string    `gorm:"type:varchar(2)"`
+	Country       string    `gorm:"type:varchar(100)"`
+	City          string    `gorm:"type:varchar(100)"`
+	UserAgent     string    `gorm:"type:varchar(512)"`
+	DeviceType    string    `gorm:"type:varchar(20);not null;default:unknown"`
+	DeviceBrowser string    `gorm:"type:varchar(50)"`
+	DeviceOS      string    `gorm:"type:varchar(50)"`
+	Fingerprint   string    `gorm:"type:varchar(64);index:idx_login_attempts_user_fingerprint,priority:2"`
+	RiskFlags     string    `gorm:"type:varchar(100)"`
+	SessionID     *string   `gorm:"type:uuid"`
+	CreatedAt     time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for GORM
+func (LoginAttemptModel) TableName() string {
+	return "login_attempts"
+}
+
+// ToEntity converts the GORM model to a domain entity.
+func (m *LoginAttemptModel) ToEntity() *entity.LoginAttempt {
+	attempt := &entity.LoginAttempt{
+		ID:            entity.LoginAttemptID(m.ID),
+		TenantID:      entity.TenantID(m.TenantID),
+		Identifier:    m.Identifier,
+		Success:       m.Success,
+		FailureReason: m.FailureReason,
+		IP:            m.IP,
+		IPRange:       m.IPRange,
+		Location: entity.Location{
+			CountryCode: m.CountryCode,
+			Country:     m.Country,
+			City:        m.City,
+		},
+		UserAgent: m.UserAgent,
+		Device: entity.Device{
+			Type:    entity.DeviceType(m.DeviceType),
+			Browser: m.DeviceBrowser,
+			OS:      m.DeviceOS,
+		},
+		Fingerprint: m.Fingerprint,
+		RiskFlags:   splitRiskFlags(m.RiskFlags),
+		CreatedAt:   m.CreatedAt,
+	}
+	if m.UserID != nil {
+		id := entity.UserID(*m.UserID)
+		attempt.UserID = &id
+	}
+	if m.SessionID != nil {
+		id := entity.SessionID(*m.SessionID)
+		attempt.SessionID = &id
+	}
+	return attempt
+}
+
+// LoginAttemptFromEntity converts a domain entity to a GORM model. The
+// identifier and User-Agent are cut to the column sizes.
+func LoginAttemptFromEntity(a *entity.LoginAttempt) *LoginAttemptModel {
+	model := &LoginAttemptModel{
+		ID:            string(a.ID),
+		TenantID:      string(a.TenantID),
+		Identifier:    truncate(a.Identifier, 254),
+		Success:       a.Success,
+		FailureReason: a.FailureReason,
+		IP:            a.IP,
+		IPRange:       a.IPRange,
+		CountryCode:   a.Location.CountryCode,
+		Country:       truncate(a.Location.Country, 100),
+		City:          truncate(a.Location.City, 100),
+		UserAgent:     truncate(a.UserAgent, 512),
+		DeviceType:    string(a.Device.Type),
+		DeviceBrowser: a.Device.Browser,
+		DeviceOS:      a.Device.OS,
+		Fingerprint:   a.Fingerprint,
+		RiskFlags:     joinRiskFlags(a.RiskFlags),
+		CreatedAt:     a.CreatedAt,
+	}
+	if a.UserID != nil {
+		id := string(*a.UserID)
+		model.UserID = &id
+	}
+	if a.SessionID != nil {
+		id := string(*a.SessionID)
+		model.SessionID = &id
+	}
+	return model
+}
+
+// joinRiskFlags stores risk flags as a comma-separated list.
+func joinRiskFlags(flags []entity.RiskFlag) string {
+	names := make([]string, len(flags))
+	for i, flag := range flags {
+		names[i] = string(flag)
+	}
+	return strings.Join(names, ",")
+}
+
+func splitRiskFlags(s string) []entity.RiskFlag {
+	if s == "" {
+		return nil
+	}
+	names := strings.Split(s, ",")
+	flags := make([]entity.RiskFlag, len(names))
+	for i, name := range names {
+		flags[i] = entity.RiskFlag(name)
+	}
+	return flags
+}
+
+// truncate cuts s to at most n characters, the unit of varchar(n), without
+// splitting one. Invalid UTF-8, which Postgres refuses, becomes U+FFFD.
+func truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
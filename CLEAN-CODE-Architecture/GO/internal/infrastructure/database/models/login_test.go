@@ -0,0 +1,24 @@
+package models
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"Mozilla/5.0", 20, "Mozilla/5.0"},
+		{"Mozilla/5.0", 7, "Mozilla"},
+		{"São Paulo", 3, "São"},
+		{"東京都", 2, "東京"},
+		{"東京都", 3, "東京都"},
+		{"abc", 0, ""},
+		{"ab\xffcd", 4, "ab�c"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"auth-module/internal/domain/entity"
+	"time"
+)
+
+// MagicLinkModel is the database form of entity.MagicLink.
+// idx_magic_links_tenant_user_created counts a user's recent links for
+// throttling; deleting the user deletes them (see the migrator).
+type MagicLinkModel struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	TenantID  string    `gorm:"type:uuid;not null;index:idx_magic_links_tenant_user_created,priority:1"`
+	UserID    string    `gorm:"type:uuid;not null;index:idx_magic_links_tenant_user_created,priority:2"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	IP        string    `gorm:"type:varchar(45)"`
+	CreatedAt time.Time `gorm:"not null;index:idx_magic_links_tenant_user_created,priority:3"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+// TableName returns the table name for GORM
+func (MagicLinkModel) TableName() string {
+	return "magic_links"
+}
+
+// ToEntity converts the GORM model to a domain entity.
+func (m *MagicLinkModel) ToEntity() *entity.MagicLink {
+	return &entity.MagicLink{
+		ID:        entity.MagicLinkID(m.ID),
+		TenantID:  entity.TenantID(m.TenantID),
+		UserID:    entity.UserID(m.UserID),
+		TokenHash: m.TokenHash,
+		IP:        m.IP,
+		CreatedAt: m.CreatedAt,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    m.UsedAt,
+	}
+}
+
+// MagicLinkFromEntity converts a domain entity to a GORM model.
+func MagicLinkFromEntity(l *entity.MagicLink) *MagicLinkModel {
+	return &MagicLinkModel{
+		ID:        string(l.ID),
+		TenantID:  string(l.TenantID),
+		UserID:    string(l.UserID),
+		TokenHash: l.TokenHash,
+		IP:        l.IP,
+		CreatedAt: l.CreatedAt,
+		ExpiresAt: l.ExpiresAt,
+		UsedAt:    l.UsedAt,
+	}
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"auth-module/internal/domain/entity"
+	"time"
+)
+
+// SessionModel is the database form of entity.Session.
+// idx_sessions_tenant_user lists a user's sessions; deleting the user
+// deletes them (see the migrator).
+type SessionModel struct {
+	ID            string    `gorm:"type:uuid;primaryKey"`
+	TenantID      string    `gorm:"type:uuid;not null;index:idx_sessions_tenant_user,priority:1"`
+	UserID        string    `gorm:"type:uuid;not null;index:idx_sessions_tenant_user,priority:2"`
+	DeviceType    string    `gorm:"type:varchar(20);not null;default:unknown"`
+	DeviceBrowser string    `gorm:"type:varchar(50)"`
+	DeviceOS      string    `gorm:"type:varchar(50)"`
+	UserAgent     string    `gorm:"type:varchar(512)"`
+	IP            string    `gorm:"type:varchar(45)"`
+	CountryCode   string    `gorm:"type:varchar(2)"`
+	Country       string    `gorm:"type:varchar(100)"`
+	City          string    `gorm:"type:varchar(100)"`
+	CreatedAt     time.Time `gorm:"not null"`
+	LastSeenAt    time.Time `gorm:"not null"`
+	ExpiresAt     time.Time `gorm:"not null"`
+	RevokedAt     *time.Time
+	RiskFlags     string `gorm:"type:varchar(100)"`
+}
+
+// TableName returns the table name for GORM
+func (SessionModel) TableName() string {
+	return "sessions"
+}
+
+// ToEntity converts the GORM model to a domain entity.
+func (m *SessionModel) ToEntity() *entity.Session {
+	return &entity.Session{
+		ID:       entity.SessionID(m.ID),
+		TenantID: entity.TenantID(m.TenantID),
+		UserID:   entity.UserID(m.UserID),
+		Device: entity.Device{
+			Type:    entity.DeviceType(m.DeviceType),
+			Browser: m.DeviceBrowser,
+			OS:      m.DeviceOS,
+		},
+		UserAgent:  m.UserAgent,
+		IP:         m.IP,
+		Location:   m.location(),
+		CreatedAt:  m.CreatedAt,
+		LastSeenAt: m.LastSeenAt,
+		ExpiresAt:  m.ExpiresAt,
+		RevokedAt:  m.RevokedAt,
+		RiskFlags:  splitRiskFlags(m.RiskFlags),
+	}
+}
+
+func (m *SessionModel) location() entity.Location {
+	return entity.Location{CountryCode: m.CountryCode, Country: m.Country, City: m.City}
+}
+
+// SessionFromEntity converts a domain entity to a GORM model. The
+// User-Agent is cut to the column size.
+func SessionFromEntity(s *entity.Session) *SessionModel {
+	return &SessionModel{
+		ID:            string(s.ID),
+		TenantID:      string(s.TenantID),
+		UserID:        string(s.UserID),
+		DeviceType:    string(s.Device.Type),
+		DeviceBrowser: s.Device.Browser,
+		DeviceOS:      s.Device.OS,
+		UserAgent:     truncate(s.UserAgent, 512),
+		IP:            s.IP,
+		CountryCode:   s.Location.CountryCode,
+		Country:       truncate(s.Location.Country, 100),
+		City:          truncate(s.Location.City, 100),
+		CreatedAt:     s.CreatedAt,
+		LastSeenAt:    s.LastSeenAt,
+		ExpiresAt:     s.ExpiresAt,
+		RevokedAt:     s.RevokedAt,
+		RiskFlags:     joinRiskFlags(s.RiskFlags),
+	}
+}
+
+// RefreshTokenModel is the database form of entity.RefreshToken. TokenHash
+// is the primary key because tokens are only ever looked up by it.
+type RefreshTokenModel struct {
+	TokenHash string    `gorm:"type:varchar(64);primaryKey"`
+	SessionID string    `gorm:"type:uuid;not null;index"`
+	TenantID  string    `gorm:"type:uuid;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+// TableName returns the table name for GORM
+func (RefreshTokenModel) TableName() string {
+	return "refresh_tokens"
+}
+
+// ToEntity converts the GORM model to a domain entity.
+func (m *RefreshTokenModel) ToEntity() *entity.RefreshToken {
+	return &entity.RefreshToken{
+		TokenHash: m.TokenHash,
+		SessionID: entity.SessionID(m.SessionID),
+		TenantID:  entity.TenantID(m.TenantID),
+		CreatedAt: m.CreatedAt,
+		UsedAt:    m.UsedAt,
+	}
+}
+
+// RefreshTokenFromEntity converts a domain entity to a GORM model.
+func RefreshTokenFromEntity(t *entity.RefreshToken) *RefreshTokenModel {
+	return &RefreshTokenModel{
+		TokenHash: t.TokenHash,
+		SessionID: string(t.SessionID),
+		TenantID:  string(t.TenantID),
+		CreatedAt: t.CreatedAt,
+		UsedAt:    t.UsedAt,
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"auth-module/internal/domain/entity"
+	"fmt"
+	"time"
+)
+
+// TenantModel is the database form of entity.Tenant. Slugs use citext so
+// the unique index ignores case, like usernames and emails.
+type TenantModel struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	Slug      string    `gorm:"type:citext;not null;uniqueIndex:idx_tenants_slug"`
+	Name      string    `gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (TenantModel) TableName() string {
+	return "tenants"
+}
+
+// TenantSlugIndex is the unique index on tenants.slug.
+const TenantSlugIndex = "idx_tenants_slug"
+
+// ToEntity converts the GORM model to a domain entity, re-validating the slug.
+func (m *TenantModel) ToEntity() (*entity.Tenant, error) {
+	slug, err := entity.NewTenantSlug(m.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", m.ID, err)
+	}
+	return &entity.Tenant{
+		ID:        entity.TenantID(m.ID),
+		Slug:      slug,
+		Name:      m.Name,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}, nil
+}
+
+// TenantFromEntity converts a domain entity to a GORM model.
+func TenantFromEntity(t *entity.Tenant) *TenantModel {
+	return &TenantModel{
+		ID:        string(t.ID),
+		Slug:      t.Slug.String(),
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
@@ -4,7 +4,7 @@ package models
 
 import (
 	"auth-module/internal/domain/entity"
-	"strconv"
+	"fmt"
 	"time"
 )
 
@@ -13,18 +13,46 @@ import (
 // - Contains GORM-specific tags and database mapping rules
 // - Acts as a Data Transfer Object (DTO) between domain and database
 // - Keeps infrastructure concerns isolated from domain logic
+//
+// Username and Email use citext so that equality checks and the unique
+// indexes below are case-insensitive. The unique indexes are the source of
+// truth for "already registered"; see UniqueIndexField.
+// Every query is restricted to one tenant, so tenant_id leads the indexes:
+// emails and usernames are unique per tenant, and
+// idx_users_tenant_created_at_id backs keyset pagination in the default
+// (created_at, id) order.
+// IDs are UUIDs generated by the domain. LegacyID keeps the integer ID of
+// accounts created before the switch to UUIDs.
+// Phone and Address hold ciphertext written by the Postgres adapter (see
+// package encryption); PhoneIndex is the blind index used to look up phones.
 type UserModel struct {
-	ID         uint      `gorm:"primaryKey;autoIncrement"`
-	Username   string    `gorm:"type:varchar(100);not null;uniqueIndex"`
-	FirstName  string    `gorm:"type:varchar(100)"`
-	LastName   string    `gorm:"type:varchar(100)"`
-	Email      string    `gorm:"type:varchar(100);unique;not null"`
-	Phone      string    `gorm:"type:varchar(20)"`
-	Address    string    `gorm:"type:varchar(255)"`
-	Password   string    `gorm:"type:varchar(255);not null"`
-	ProfilePic string    `gorm:"type:varchar(255)"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
-	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	ID         string  `gorm:"type:uuid;primaryKey;index:idx_users_tenant_created_at_id,priority:3"`
+	TenantID   string  `gorm:"type:uuid;not null;uniqueIndex:idx_users_tenant_email,priority:1;uniqueIndex:idx_users_tenant_username,priority:1;index:idx_users_tenant_created_at_id,priority:1"`
+	LegacyID   *int64  `gorm:"uniqueIndex:idx_users_legacy_id"`
+	Username   string  `gorm:"type:citext;not null;uniqueIndex:idx_users_tenant_username,priority:2"`
+	FirstName  string  `gorm:"type:varchar(100)"`
+	LastName   string  `gorm:"type:varchar(100)"`
+	Email      string  `gorm:"type:citext;not null;uniqueIndex:idx_users_tenant_email,priority:2"`
+	Phone      string  `gorm:"type:text"`
+	PhoneIndex *string `gorm:"column:phone_bidx;type:varchar(64);index"`
+	Address    string  `gorm:"type:text"`
+	Password   string  `gorm:"type:varchar(255);not null"`
+	ProfilePic string  `gorm:"type:varchar(255)"`
+	Role       string  `gorm:"type:varchar(20);not null;default:user;index"`
+	Status     string  `gorm:"type:varchar(20);not null;default:active;index"`
+	// StatusReason, StatusUntil and StatusChangedAt describe the last status
+	// change; SessionsRevokedAt is the session revocation cutoff
+	StatusReason      string `gorm:"type:varchar(500)"`
+	StatusUntil       *time.Time
+	StatusChangedAt   *time.Time
+	SessionsRevokedAt *time.Time
+	// EmailVerifiedAt and LastLoginAt are NULL until the event happens
+	EmailVerifiedAt *time.Time
+	LastLoginAt     *time.Time
+	// Version backs optimistic concurrency control; see PostgresUserRepo.Update
+	Version   int64     `gorm:"not null;default:1"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_users_tenant_created_at_id,priority:2"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 }
 
 // TableName returns the table name for GORM
@@ -32,47 +60,114 @@ func (UserModel) TableName() string {
 	return "users"
 }
 
+// Unique index names on the users table, referenced when translating
+// constraint violations back into field-level conflicts.
+const (
+	UserEmailIndex    = "idx_users_tenant_email"
+	UserUsernameIndex = "idx_users_tenant_username"
+)
+
+// UniqueIndexField maps a unique index name on the users, tenants, groups or
+// invitations table to the domain field it protects. It returns "" for unknown indexes.
+func UniqueIndexField(index string) string {
+	switch index {
+	case UserEmailIndex:
+		return "email"
+	case UserUsernameIndex:
+		return "username"
+	case TenantSlugIndex:
+		return "slug"
+	case GroupNameIndex:
+		return "name"
+	case InvitationPendingEmailIndex:
+		return "invitation"
+	}
+	return ""
+}
+
 // ToEntity converts the GORM model to a domain entity.
 // This method ensures that infrastructure details don't leak into the domain layer.
 // The domain layer remains clean and unaware of how the data is stored.
-func (m *UserModel) ToEntity() *entity.User {
-	return &entity.User{
-		ID:         entity.UserID(strconv.FormatUint(uint64(m.ID), 10)),
-		Username:   m.Username,
-		FirstName:  m.FirstName,
-		LastName:   m.LastName,
-		Email:      m.Email,
-		Phone:      m.Phone,
-		Address:    m.Address,
-		Password:   m.Password,
-		ProfilePic: m.ProfilePic,
-		CreatedAt:  m.CreatedAt,
-		UpdatedAt:  m.UpdatedAt,
+// Username, email and phone are restored as stored rather than validated
+// again, so rows from before a rule was tightened keep loading; they are
+// only checked when changed. Unknown roles and statuses are errors.
+func (m *UserModel) ToEntity() (*entity.User, error) {
+	role, err := entity.ParseRole(m.Role)
+	if err != nil {
+		return nil, fmt.Errorf("user %s: %w", m.ID, err)
+	}
+	status, err := entity.ParseAccountStatus(m.Status)
+	if err != nil {
+		return nil, fmt.Errorf("user %s: %w", m.ID, err)
 	}
+
+	return &entity.User{
+		ID:                entity.UserID(m.ID),
+		TenantID:          entity.TenantID(m.TenantID),
+		Username:          entity.RestoreUsername(m.Username),
+		FirstName:         m.FirstName,
+		LastName:          m.LastName,
+		Email:             entity.RestoreEmail(m.Email),
+		Phone:             entity.RestorePhoneNumber(m.Phone),
+		Address:           m.Address,
+		Password:          m.Password,
+		ProfilePic:        m.ProfilePic,
+		Role:              role,
+		Status:            status,
+		StatusReason:      m.StatusReason,
+		StatusUntil:       m.StatusUntil,
+		StatusChangedAt:   m.StatusChangedAt,
+		SessionsRevokedAt: m.SessionsRevokedAt,
+		EmailVerifiedAt:   m.EmailVerifiedAt,
+		LastLoginAt:       m.LastLoginAt,
+		Version:           m.Version,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}, nil
 }
 
 // FromEntity converts a domain entity to a GORM model.
 // This function handles the infrastructure concern of preparing
 // domain data for database storage, keeping the domain entity clean.
+// LegacyID is not part of the entity and is left nil; writes must not
+// overwrite it.
 func FromEntity(u *entity.User) *UserModel {
-	var id uint
-	if u.ID != "" {
-		if parsedID, err := strconv.ParseUint(string(u.ID), 10, 32); err == nil {
-			id = uint(parsedID)
-		}
-	}
-	
 	return &UserModel{
-		ID:         id,
-		Username:   u.Username,
-		FirstName:  u.FirstName,
-		LastName:   u.LastName,
-		Email:      u.Email,
-		Phone:      u.Phone,
-		Address:    u.Address,
-		Password:   u.Password,
-		ProfilePic: u.ProfilePic,
-		CreatedAt:  u.CreatedAt,
-		UpdatedAt:  u.UpdatedAt,
+		ID:                string(u.ID),
+		TenantID:          string(u.TenantID),
+		Username:          u.Username.String(),
+		FirstName:         u.FirstName,
+		LastName:          u.LastName,
+		Email:             u.Email.String(),
+		Phone:             u.Phone.String(),
+		Address:           u.Address,
+		Password:          u.Password,
+		ProfilePic:        u.ProfilePic,
+		Role:              string(u.Role),
+		Status:            string(u.Status),
+		StatusReason:      u.StatusReason,
+		StatusUntil:       u.StatusUntil,
+		StatusChangedAt:   u.StatusChangedAt,
+		SessionsRevokedAt: u.SessionsRevokedAt,
+		EmailVerifiedAt:   u.EmailVerifiedAt,
+		LastLoginAt:       u.LastLoginAt,
+		Version:           u.Version,
+		CreatedAt:         u.CreatedAt,
+		UpdatedAt:         u.UpdatedAt,
 	}
 }
+
+// UserSearchDocument is the expression user search matches against. The
+// trigram index in UserSearchIndexes is built on exactly this expression, so
+// queries must use it verbatim for the planner to pick the index.
+const UserSearchDocument = "(lower(username::text) || ' ' || lower(email::text) || ' ' || " +
+	"lower(coalesce(first_name, '')) || ' ' || lower(coalesce(last_name, '')))"
+
+// UserSearchIndexes creates the indexes behind user search: a trigram GIN
+// index for similarity matching and pattern-ops indexes for prefix matching
+// on username and email. They need the pg_trgm extension.
+var UserSearchIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_users_search_trgm ON users USING gin (" + UserSearchDocument + " gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON users (lower(username::text) text_pattern_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_users_email_prefix ON users (lower(email::text) text_pattern_ops)",
+}
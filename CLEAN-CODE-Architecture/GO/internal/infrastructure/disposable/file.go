@@ -0,0 +1,69 @@
+// Package disposable provides an offline list of disposable email domains.
+// This is part of the Infrastructure Layer: it implements the
+// service.DisposableEmailChecker port on top of a local file.
+package disposable
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+)
+
+// FileList answers lookups from a list of domains, one per line, such as
+// the community-maintained disposable-email-domains list. Blank lines and
+// lines starting with '#' are ignored. A listed domain also covers its
+// subdomains.
+type FileList struct {
+	domains map[string]struct{}
+}
+
+// LoadFile reads the domain list at path.
+func LoadFile(path string) (*FileList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open disposable domain list: %w", err)
+	}
+	defer f.Close()
+
+	l := &FileList{domains: make(map[string]struct{})}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		domain, err := entity.NormalizeEmailDomain(text)
+		if err != nil {
+			return nil, fmt.Errorf("disposable domain list line %d: %w", line, err)
+		}
+		l.domains[domain] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read disposable domain list: %w", err)
+	}
+	return l, nil
+}
+
+// Len returns the number of domains loaded.
+func (l *FileList) Len() int {
+	return len(l.domains)
+}
+
+// IsDisposable reports whether domain or one of its parent domains is listed.
+func (l *FileList) IsDisposable(domain string) bool {
+	for {
+		if _, found := l.domains[domain]; found {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			return false
+		}
+		domain = parent
+	}
+}
@@ -0,0 +1,168 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// envelopePrefix marks an encrypted value; anything else is legacy
+	// plaintext written before encryption was enabled.
+	envelopePrefix    = "enc:v1:"
+	envelopeSeparator = ":"
+)
+
+// ErrDecrypt is returned when a stored value cannot be decrypted: its key is
+// missing from the keyring, or it was tampered with or moved to another field.
+var ErrDecrypt = errors.New("cannot decrypt field value")
+
+// FieldCipher encrypts column values with envelope encryption. Every value
+// gets a fresh data key (DEK); the value is sealed with AES-256-GCM under the
+// DEK and the DEK is sealed under the keyring's primary key. The stored form
+// is
+//
+//	enc:v1:<key ID>:<base64 sealed DEK>:<base64 sealed value>
+//
+// The field name is bound to the ciphertext as associated data, so a value
+// copied into another column fails to decrypt. Rotating keys only requires
+// re-sealing values under the new primary key; see NeedsReencrypt.
+type FieldCipher struct {
+	keyring *Keyring
+}
+
+// NewFieldCipher creates a FieldCipher using keyring.
+func NewFieldCipher(keyring *Keyring) *FieldCipher {
+	return &FieldCipher{keyring: keyring}
+}
+
+// Encrypt seals plaintext for field under the primary key. The empty string
+// stays empty so unset fields remain recognizable.
+func (c *FieldCipher) Encrypt(field, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	keyID := c.keyring.primary
+	sealedDEK, err := seal(c.keyring.keys[keyID], dek, []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	sealedValue, err := seal(dek, []byte(plaintext), []byte(field))
+	if err != nil {
+		return "", err
+	}
+
+	return envelopePrefix + keyID + envelopeSeparator +
+		base64.RawStdEncoding.EncodeToString(sealedDEK) + envelopeSeparator +
+		base64.RawStdEncoding.EncodeToString(sealedValue), nil
+}
+
+// Decrypt opens a value produced by Encrypt for the same field. Values
+// without the envelope prefix are returned unchanged: they predate
+// encryption and are sealed by the next write or re-encryption run.
+func (c *FieldCipher) Decrypt(field, stored string) (string, error) {
+	if !strings.HasPrefix(stored, envelopePrefix) {
+		return stored, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(stored, envelopePrefix), envelopeSeparator)
+	if len(parts) != 3 {
+		return "", fmt.Errorf("%w: malformed envelope", ErrDecrypt)
+	}
+	kek, ok := c.keyring.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown key %q", ErrDecrypt, parts[0])
+	}
+	sealedDEK, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed envelope", ErrDecrypt)
+	}
+	sealedValue, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed envelope", ErrDecrypt)
+	}
+
+	dek, err := open(kek, sealedDEK, []byte(parts[0]))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	plaintext, err := open(dek, sealedValue, []byte(field))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencrypt reports whether stored is plaintext or sealed under a key
+// other than the primary one.
+func (c *FieldCipher) NeedsReencrypt(stored string) bool {
+	if stored == "" {
+		return false
+	}
+	if !strings.HasPrefix(stored, envelopePrefix) {
+		return true
+	}
+	keyID, _, _ := strings.Cut(strings.TrimPrefix(stored, envelopePrefix), envelopeSeparator)
+	return keyID != c.keyring.primary
+}
+
+// BlindIndex returns a keyed hash of value for exact-match lookups on an
+// encrypted field: equal values give equal indexes, but the index reveals
+// nothing else without the blind index key. Callers must normalize value
+// first. The empty string has no index.
+func (c *FieldCipher) BlindIndex(field, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.keyring.blindIndexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// seal encrypts plaintext with AES-GCM and prepends the random nonce.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open reverses seal.
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
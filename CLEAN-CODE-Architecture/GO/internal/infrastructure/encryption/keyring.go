@@ -0,0 +1,89 @@
+// Package encryption encrypts individual column values at rest.
+// This is part of the Infrastructure Layer: the Postgres adapter uses it to
+// keep personal data such as phone numbers and addresses out of the database
+// in plaintext, while the domain keeps working with plain values.
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// keySize is the length of every key in the keyring: AES-256 for key
+// encryption keys, HMAC-SHA256 for the blind index key.
+const keySize = 32
+
+// Keyring holds the key encryption keys (KEKs) by ID. New values are always
+// encrypted under the primary key; the other keys are kept so values written
+// before a rotation can still be decrypted until they are re-encrypted.
+type Keyring struct {
+	primary       string
+	keys          map[string][]byte
+	blindIndexKey []byte
+}
+
+// keyringFile is the on-disk form of a Keyring:
+//
+//	{
+//	  "primary": "2025-06",
+//	  "keys": {"2025-01": "<base64>", "2025-06": "<base64>"},
+//	  "blind_index_key": "<base64>"
+//	}
+//
+// Every key is 32 random bytes in standard base64, e.g. from
+// `openssl rand -base64 32`.
+type keyringFile struct {
+	Primary       string            `json:"primary"`
+	Keys          map[string]string `json:"keys"`
+	BlindIndexKey string            `json:"blind_index_key"`
+}
+
+// LoadKeyring reads the keyring file at path.
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyring: %w", err)
+	}
+
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse keyring %s: %w", path, err)
+	}
+
+	k := &Keyring{primary: file.Primary, keys: make(map[string][]byte, len(file.Keys))}
+	for id, encoded := range file.Keys {
+		if id == "" || strings.Contains(id, envelopeSeparator) {
+			return nil, fmt.Errorf("keyring %s: key ID %q must be non-empty and must not contain %q", path, id, envelopeSeparator)
+		}
+		if k.keys[id], err = decodeKey(encoded); err != nil {
+			return nil, fmt.Errorf("keyring %s: key %q: %w", path, id, err)
+		}
+	}
+	if _, ok := k.keys[k.primary]; !ok {
+		return nil, fmt.Errorf("keyring %s: primary key %q is not in keys", path, k.primary)
+	}
+	if k.blindIndexKey, err = decodeKey(file.BlindIndexKey); err != nil {
+		return nil, fmt.Errorf("keyring %s: blind_index_key: %w", path, err)
+	}
+	return k, nil
+}
+
+// PrimaryKeyID returns the ID of the key new values are encrypted with.
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, errors.New("key must be 32 bytes")
+	}
+	return key, nil
+}
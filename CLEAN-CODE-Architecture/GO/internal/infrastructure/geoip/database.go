@@ -0,0 +1,108 @@
+// Package geoip locates IP addresses with a local MaxMind-format (.mmdb)
+// database, such as GeoLite2-City or GeoLite2-Country.
+// This is part of the Infrastructure Layer: it implements the
+// service.GeoLocator port. Lookups read the memory-mapped file and never
+// touch the network.
+//   - Reload swaps in a new copy of the file without a restart, so the
+//     database can be updated in place and the server sent SIGHUP.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"auth-module/internal/domain/entity"
+)
+
+// record is the part of a GeoIP2 City or Country record that is read.
+// Country databases have no city.
+type record struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// Database answers lookups from one .mmdb file.
+type Database struct {
+	path string
+
+	mu     sync.RWMutex
+	reader *maxminddb.Reader
+}
+
+// Open opens the database at path.
+func Open(path string) (*Database, error) {
+	reader, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{path: path, reader: reader}, nil
+}
+
+func open(path string) (*maxminddb.Reader, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open geoip database %s: %w", path, err)
+	}
+	return reader, nil
+}
+
+// Reload opens the file again and switches lookups to it. The old copy
+// keeps serving if the new one cannot be opened.
+func (d *Database) Reload() error {
+	reader, err := open(d.path)
+	if err != nil {
+		return err
+	}
+	// Taking the write lock waits for lookups still reading the old copy
+	d.mu.Lock()
+	old := d.reader
+	d.reader = reader
+	d.mu.Unlock()
+	return old.Close()
+}
+
+// Describe returns the database type and build date, for logging.
+func (d *Database) Describe() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	built := time.Unix(int64(d.reader.Metadata.BuildEpoch), 0).UTC()
+	return fmt.Sprintf("%s built %s", d.reader.Metadata.DatabaseType, built.Format(time.DateOnly))
+}
+
+// Locate returns the location of ip with English names, or the zero
+// Location when ip is malformed or not in the database.
+func (d *Database) Locate(ip string) entity.Location {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return entity.Location{}
+	}
+
+	var r record
+	d.mu.RLock()
+	err := d.reader.Lookup(parsed, &r)
+	d.mu.RUnlock()
+	if err != nil {
+		return entity.Location{}
+	}
+	return entity.Location{
+		CountryCode: r.Country.ISOCode,
+		Country:     r.Country.Names["en"],
+		City:        r.City.Names["en"],
+	}
+}
+
+// Close releases the database file.
+func (d *Database) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reader.Close()
+}
@@ -0,0 +1,19 @@
+package mail
+
+import (
+	"context"
+	"log"
+
+	"auth-module/internal/domain/service"
+)
+
+// LogMailer implements service.Mailer by writing messages to the log. It is
+// meant for development, where links in the log stand in for real email;
+// never use it in production, as messages may carry secret links.
+type LogMailer struct{}
+
+// Send logs msg.
+func (LogMailer) Send(_ context.Context, msg service.Message) error {
+	log.Printf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package notify delivers security notifications to users.
+// This is part of the Infrastructure Layer: it implements the
+// service.LoginNotifier port on top of a service.Mailer, so the delivery
+// channel can be swapped without touching the use cases.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/service"
+)
+
+// MailLoginNotifier emails users about unusual logins.
+type MailLoginNotifier struct {
+	mailer service.Mailer
+}
+
+// NewMailLoginNotifier creates a notifier sending through mailer.
+func NewMailLoginNotifier(mailer service.Mailer) *MailLoginNotifier {
+	return &MailLoginNotifier{mailer: mailer}
+}
+
+// NotifyLogin emails the user what was unusual about attempt and where it
+// came from.
+func (n *MailLoginNotifier) NotifyLogin(ctx context.Context, user *entity.User, attempt *entity.LoginAttempt) error {
+	var reasons []string
+	for _, flag := range attempt.RiskFlags {
+		switch flag {
+		case entity.RiskNewDevice:
+			reasons = append(reasons, "a device you have not used before")
+		case entity.RiskNewIPRange:
+			reasons = append(reasons, "a network you have not used before")
+		}
+	}
+	return n.mailer.Send(ctx, service.Message{
+		To:      user.Email.String(),
+		Subject: "New sign-in to your account",
+		Body: fmt.Sprintf("There was a new sign-in to your account from %s.\n\n"+
+			"Time:    %s\n"+
+			"Device:  %s\n"+
+			"Address: %s\n\n"+
+			"If this was you, no action is needed. If not, change your password "+
+			"and sign out the session from your list of sessions.\n",
+			strings.Join(reasons, " and "),
+			attempt.CreatedAt.UTC().Format("2 January 2006 15:04 MST"),
+			describeDevice(attempt.Device),
+			attempt.IP),
+	})
+}
+
+func describeDevice(device entity.Device) string {
+	switch {
+	case device.Browser != "" && device.OS != "":
+		return device.Browser + " on " + device.OS
+	case device.Browser != "":
+		return device.Browser
+	case device.OS != "":
+		return device.OS
+	case device.Type != "" && device.Type != entity.DeviceUnknown:
+		return string(device.Type)
+	}
+	return "unknown device"
+}
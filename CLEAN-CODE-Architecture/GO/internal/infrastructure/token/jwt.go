@@ -1,11 +1,167 @@
 package token
 
-func GenerateJWT(userID string) (string, error) {
-	// Generate JWT token
-	return "", nil
+// JWTService implements service.TokenService with HS256-signed JWTs.
+// - The role, tenant and optionally the group names are carried in the token
+//   so authorization checks do not need a database round trip on every
+//   request.
+// - Tokens are short-lived; a role or group change takes effect when the
+//   token expires. Suspensions and revoked sessions are enforced on top of
+//   this by auth.ActiveAccountTokens.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/service"
+)
+
+// DefaultAccessTokenTTL is how long an access token stays valid.
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+// JWTService signs and verifies access tokens.
+type JWTService struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewJWTService creates a JWTService. secret must be non-empty.
+func NewJWTService(secret []byte, issuer string, ttl time.Duration) (*JWTService, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("jwt secret must not be empty")
+	}
+	if ttl <= 0 {
+		ttl = DefaultAccessTokenTTL
+	}
+	return &JWTService{secret: secret, issuer: issuer, ttl: ttl, now: time.Now}, nil
+}
+
+// accessTokenClaims is the JWT body of an access token. Tokens of a
+// session name it in the sid claim (OpenID Connect). Impersonation
+// tokens carry the admin in the act claim (RFC 8693) and the impersonation
+// ID as jti.
+type accessTokenClaims struct {
+	Role    string      `json:"role"`
+	Tenant  string      `json:"tid"`
+	Groups  []string    `json:"groups,omitempty"`
+	Session string      `json:"sid,omitempty"`
+	Actor   *actorClaim `json:"act,omitempty"`
+	jwt.RegisteredClaims
 }
 
-func ValidateJWT(token string) (string, error) {
-	// Validate JWT token
-	return "", nil
+// actorClaim is the RFC 8693 act claim.
+type actorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// IssueAccessToken returns a signed token for user in session, with a
+// groups claim when groups is non-nil.
+func (s *JWTService) IssueAccessToken(user *entity.User, session entity.SessionID, groups []string) (string, service.AccessClaims, error) {
+	now := s.now()
+	claims := service.AccessClaims{
+		UserID:    user.ID,
+		TenantID:  user.TenantID,
+		Role:      user.Role,
+		Groups:    groups,
+		SessionID: session,
+		IssuedAt:  now.Truncate(time.Second),
+		ExpiresAt: now.Add(s.ttl).Truncate(time.Second),
+	}
+	return s.sign(claims)
+}
+
+// IssueImpersonationToken returns a signed token for user acting through
+// impersonation. It expires with the impersonation and carries no groups
+// claim.
+func (s *JWTService) IssueImpersonationToken(user *entity.User, impersonation *entity.Impersonation) (string, service.AccessClaims, error) {
+	return s.sign(service.AccessClaims{
+		UserID:          user.ID,
+		TenantID:        user.TenantID,
+		Role:            user.Role,
+		IssuedAt:        s.now().Truncate(time.Second),
+		ExpiresAt:       impersonation.ExpiresAt.Truncate(time.Second),
+		ActorID:         impersonation.ActorID,
+		ImpersonationID: impersonation.ID,
+	})
+}
+
+func (s *JWTService) sign(claims service.AccessClaims) (string, service.AccessClaims, error) {
+	body := accessTokenClaims{
+		Role:    string(claims.Role),
+		Tenant:  string(claims.TenantID),
+		Groups:  claims.Groups,
+		Session: string(claims.SessionID),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   string(claims.UserID),
+			IssuedAt:  jwt.NewNumericDate(claims.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(claims.ExpiresAt),
+		},
+	}
+	if claims.Impersonated() {
+		body.Actor = &actorClaim{Subject: string(claims.ActorID)}
+		body.ID = string(claims.ImpersonationID)
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, body).SignedString(s.secret)
+	if err != nil {
+		return "", service.AccessClaims{}, fmt.Errorf("sign access token: %w", err)
+	}
+	return signed, claims, nil
+}
+
+// ParseAccessToken verifies the signature, issuer and expiry of token.
+// The token is self-contained, so ctx is not used.
+func (s *JWTService) ParseAccessToken(ctx context.Context, token string) (*service.AccessClaims, error) {
+	var claims accessTokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil {
+		return nil, service.ErrInvalidToken
+	}
+
+	role, err := entity.ParseRole(claims.Role)
+	if err != nil || claims.Subject == "" || claims.IssuedAt == nil {
+		return nil, service.ErrInvalidToken
+	}
+	tenant, err := entity.ParseTenantID(claims.Tenant)
+	if err != nil {
+		return nil, service.ErrInvalidToken
+	}
+	parsed := &service.AccessClaims{
+		UserID:    entity.UserID(claims.Subject),
+		TenantID:  tenant,
+		Role:      role,
+		Groups:    claims.Groups,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if claims.Session != "" {
+		session, err := entity.ParseSessionID(claims.Session)
+		if err != nil {
+			return nil, service.ErrInvalidToken
+		}
+		parsed.SessionID = session
+	}
+	if claims.Actor != nil {
+		impersonation, err := entity.ParseImpersonationID(claims.ID)
+		if err != nil || claims.Actor.Subject == "" {
+			return nil, service.ErrInvalidToken
+		}
+		parsed.ActorID = entity.UserID(claims.Actor.Subject)
+		parsed.ImpersonationID = impersonation
+	}
+	return parsed, nil
 }
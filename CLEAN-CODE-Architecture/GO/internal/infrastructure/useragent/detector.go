@@ -0,0 +1,94 @@
+// Package useragent recognizes browsers, operating systems and device types
+// from User-Agent headers.
+// This is part of the Infrastructure Layer: it implements the
+// service.DeviceDetector port with a small set of substring rules. It only
+// needs to be good enough to label a session ("Firefox on Windows"), not to
+// make security decisions.
+package useragent
+
+import (
+	"strings"
+
+	"auth-module/internal/domain/entity"
+)
+
+// rule names the product when the User-Agent contains any of its markers.
+type rule struct {
+	name    string
+	markers []string
+}
+
+// Order matters: many browsers also claim to be Chrome or Safari, and
+// Android and iOS user agents mention Linux and Mac OS X.
+var (
+	browsers = []rule{
+		{"Edge", []string{"Edg/", "EdgA/", "EdgiOS/", "Edge/"}},
+		{"Opera", []string{"OPR/", "Opera"}},
+		{"Samsung Internet", []string{"SamsungBrowser/"}},
+		{"Firefox", []string{"Firefox/", "FxiOS/"}},
+		{"Chrome", []string{"Chrome/", "CriOS/"}},
+		{"Safari", []string{"Safari/"}},
+	}
+	systems = []rule{
+		{"Android", []string{"Android"}},
+		{"iOS", []string{"iPhone", "iPad", "iPod"}},
+		{"Windows", []string{"Windows"}},
+		{"ChromeOS", []string{"CrOS"}},
+		{"macOS", []string{"Macintosh", "Mac OS X"}},
+		{"Linux", []string{"Linux"}},
+	}
+	botMarkers = []string{"bot", "crawler", "spider", "curl/", "wget/", "python-requests", "go-http-client"}
+)
+
+// Detector implements service.DeviceDetector.
+type Detector struct{}
+
+// NewDetector creates a Detector.
+func NewDetector() Detector {
+	return Detector{}
+}
+
+// Detect returns what userAgent says about the client.
+func (Detector) Detect(userAgent string) entity.Device {
+	if userAgent == "" {
+		return entity.Device{Type: entity.DeviceUnknown}
+	}
+	device := entity.Device{
+		Browser: match(browsers, userAgent),
+		OS:      match(systems, userAgent),
+	}
+
+	lower := strings.ToLower(userAgent)
+	switch {
+	case containsAny(lower, botMarkers):
+		device.Type = entity.DeviceBot
+	case strings.Contains(userAgent, "iPad") || strings.Contains(userAgent, "Tablet") ||
+		device.OS == "Android" && !strings.Contains(userAgent, "Mobile"):
+		device.Type = entity.DeviceTablet
+	case strings.Contains(userAgent, "Mobi") || device.OS == "iOS":
+		device.Type = entity.DeviceMobile
+	case device.OS != "":
+		device.Type = entity.DeviceDesktop
+	default:
+		device.Type = entity.DeviceUnknown
+	}
+	return device
+}
+
+func match(rules []rule, userAgent string) string {
+	for _, r := range rules {
+		if containsAny(userAgent, r.markers) {
+			return r.name
+		}
+	}
+	return ""
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	userUseCase "auth-module/internal/usecase/user"
+)
+
+// importColumns are the columns an import header may name, in any order.
+// Columns left out are imported as empty values.
+var importColumns = map[string]func(*userUseCase.ImportRow, string){
+	"username":      func(r *userUseCase.ImportRow, v string) { r.Username = v },
+	"email":         func(r *userUseCase.ImportRow, v string) { r.Email = v },
+	"password":      func(r *userUseCase.ImportRow, v string) { r.Password = v },
+	"first_name":    func(r *userUseCase.ImportRow, v string) { r.FirstName = v },
+	"last_name":     func(r *userUseCase.ImportRow, v string) { r.LastName = v },
+	"phone":         func(r *userUseCase.ImportRow, v string) { r.Phone = v },
+	"address":       func(r *userUseCase.ImportRow, v string) { r.Address = v },
+	"role":          func(r *userUseCase.ImportRow, v string) { r.Role = v },
+	"status":        func(r *userUseCase.ImportRow, v string) { r.Status = v },
+	"status_reason": func(r *userUseCase.ImportRow, v string) { r.StatusReason = v },
+}
+
+// CSVReader decodes import rows from CSV with a header row.
+type CSVReader struct {
+	csv     *csv.Reader
+	setters []func(*userUseCase.ImportRow, string)
+}
+
+// NewCSVReader reads and checks the header row of r. Unknown or repeated
+// column names are rejected so a typo cannot silently drop a field. A UTF-8
+// byte order mark, as written by spreadsheet exports, is ignored.
+func NewCSVReader(r io.Reader) (*CSVReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv: missing header row")
+		}
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+
+	setters := make([]func(*userUseCase.ImportRow, string), len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		setter, ok := importColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("csv header: unknown column %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("csv header: duplicate column %q", name)
+		}
+		seen[name] = true
+		setters[i] = setter
+	}
+	reader.FieldsPerRecord = len(header)
+	return &CSVReader{csv: reader, setters: setters}, nil
+}
+
+// Next returns the next row. Records with a parse error or the wrong number
+// of fields are reported as *userUseCase.MalformedRowError.
+func (r *CSVReader) Next() (userUseCase.ImportRow, error) {
+	record, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return userUseCase.ImportRow{}, &userUseCase.MalformedRowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return userUseCase.ImportRow{}, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	row := userUseCase.ImportRow{Line: line}
+	for i, value := range record {
+		r.setters[i](&row, value)
+	}
+	return row, nil
+}
+
+// exportColumns is the CSV header written by CSVWriter.
+var exportColumns = []string{
+	"id", "username", "email", "first_name", "last_name", "phone", "address",
+	"role", "status", "email_verified", "last_login_at", "created_at", "updated_at",
+}
+
+// CSVWriter encodes exported users as CSV with a header row.
+type CSVWriter struct {
+	csv *csv.Writer
+}
+
+// NewCSVWriter writes the header row to w.
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return &CSVWriter{csv: writer}, nil
+}
+
+// Write encodes one user. Free-text values that a spreadsheet would
+// evaluate as a formula are prefixed with a single quote.
+func (w *CSVWriter) Write(user *entity.User) error {
+	r := NewExportRecord(user)
+	return w.csv.Write([]string{
+		r.ID, r.Username, r.Email,
+		neutralizeFormula(r.FirstName), neutralizeFormula(r.LastName),
+		r.Phone, neutralizeFormula(r.Address),
+		r.Role, r.Status, strconv.FormatBool(r.EmailVerified),
+		r.LastLoginAt, r.CreatedAt, r.UpdatedAt,
+	})
+}
+
+// Flush writes buffered rows to the underlying writer.
+func (w *CSVWriter) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// neutralizeFormula guards against CSV formula injection.
+func neutralizeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,118 @@
+// Package bulk reads and writes the file formats of bulk user import and
+// export: CSV with a header row, and NDJSON (one JSON object per line).
+// It is shared by the admin HTTP API and the CLI.
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	userUseCase "auth-module/internal/usecase/user"
+)
+
+// Format is a bulk file format.
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatCSV, FormatNDJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format %q (use csv or ndjson)", name)
+}
+
+// FormatFromContentType maps a request Content-Type to a format.
+func FormatFromContentType(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unsupported content type %q", contentType)
+}
+
+// ContentType is the media type written for f.
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// NewReader returns an import source decoding f from r.
+func NewReader(f Format, r io.Reader) (userUseCase.ImportSource, error) {
+	if f == FormatCSV {
+		return NewCSVReader(r)
+	}
+	return NewNDJSONReader(r), nil
+}
+
+// Writer encodes exported users.
+type Writer interface {
+	Write(user *entity.User) error
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+}
+
+// NewWriter returns a Writer encoding f to w.
+func NewWriter(f Format, w io.Writer) (Writer, error) {
+	if f == FormatCSV {
+		return NewCSVWriter(w)
+	}
+	return NewNDJSONWriter(w), nil
+}
+
+// ExportRecord is the exported view of a user. Password hashes are never
+// exported.
+type ExportRecord struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Phone         string `json:"phone"`
+	Address       string `json:"address"`
+	Role          string `json:"role"`
+	Status        string `json:"status"`
+	EmailVerified bool   `json:"email_verified"`
+	LastLoginAt   string `json:"last_login_at"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// NewExportRecord converts user to its exported form.
+func NewExportRecord(user *entity.User) ExportRecord {
+	record := ExportRecord{
+		ID:            string(user.ID),
+		Username:      user.Username.String(),
+		Email:         user.Email.String(),
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Phone:         user.Phone.String(),
+		Address:       user.Address,
+		Role:          string(user.Role),
+		Status:        string(user.Status),
+		EmailVerified: user.IsEmailVerified(),
+		CreatedAt:     user.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     user.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if user.LastLoginAt != nil {
+		record.LastLoginAt = user.LastLoginAt.UTC().Format(time.RFC3339)
+	}
+	return record
+}
@@ -0,0 +1,73 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"auth-module/internal/domain/entity"
+	userUseCase "auth-module/internal/usecase/user"
+)
+
+// maxNDJSONLine bounds a single NDJSON record.
+const maxNDJSONLine = 1 << 20
+
+// NDJSONReader decodes import rows from newline-delimited JSON.
+type NDJSONReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewNDJSONReader reads rows from r. Blank lines are skipped.
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	return &NDJSONReader{scanner: scanner}
+}
+
+// Next returns the next row. Lines that are not a JSON object of known
+// fields are reported as *userUseCase.MalformedRowError.
+func (r *NDJSONReader) Next() (userUseCase.ImportRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		row := userUseCase.ImportRow{Line: r.line}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			return userUseCase.ImportRow{}, &userUseCase.MalformedRowError{Line: r.line, Err: err}
+		}
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return userUseCase.ImportRow{}, err
+	}
+	return userUseCase.ImportRow{}, io.EOF
+}
+
+// NDJSONWriter encodes exported users as newline-delimited JSON.
+type NDJSONWriter struct {
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewNDJSONWriter writes records to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	buf := bufio.NewWriter(w)
+	return &NDJSONWriter{buf: buf, encoder: json.NewEncoder(buf)}
+}
+
+// Write encodes one user as a line of JSON.
+func (w *NDJSONWriter) Write(user *entity.User) error {
+	return w.encoder.Encode(NewExportRecord(user))
+}
+
+// Flush writes buffered records to the underlying writer.
+func (w *NDJSONWriter) Flush() error {
+	return w.buf.Flush()
+}
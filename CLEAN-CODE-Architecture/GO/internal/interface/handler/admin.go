@@ -0,0 +1,306 @@
+package handler
+
+// This file contains the admin-only HTTP handlers: bulk user import and
+// export, lookups by legacy ID, and suspending and reinstating accounts. Routes are wrapped in RequireRole(..., entity.RoleAdmin, ...).
+// - Both directions stream, so the server's read and write timeouts are
+//   lifted for these requests.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+	"auth-module/internal/interface/bulk"
+	userUseCase "auth-module/internal/usecase/user"
+)
+
+// exportFlushEvery is how many users are written between flushes.
+const exportFlushEvery = 500
+
+// ImportUsersHandler handles POST /api/admin/users/import
+// The body is CSV (Content-Type: text/csv) or NDJSON
+// (Content-Type: application/x-ndjson); ?format=csv|ndjson overrides the
+// content type. Rows are upserted by email; an existing account only has its
+// profile updated. The response is the import report with an entry per
+// rejected row.
+func ImportUsersHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.BulkUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	format, err := importFormat(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	extendDeadlines(w)
+	source, err := bulk.NewReader(format, r.Body)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	report, err := uc.Import(r.Context(), source)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrHasherOverloaded) {
+			status = http.StatusServiceUnavailable
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  "Import aborted: " + err.Error(),
+			"report": report,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ExportUsersHandler handles GET /api/admin/users/export
+// Query parameters: format=ndjson (default) or csv, plus the filters of
+// ListUsersHandler and q to restrict to a search. Users are streamed oldest
+// first; password hashes are never included.
+func ExportUsersHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.BulkUseCase) {
+	query := r.URL.Query()
+
+	format := bulk.FormatNDJSON
+	if v := query.Get("format"); v != "" {
+		f, err := bulk.ParseFormat(v)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			writeBadRequest(w, err)
+			return
+		}
+		format = f
+	}
+	filter, err := ParseUserFilter(query)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeBadRequest(w, err)
+		return
+	}
+	filter.Search = query.Get("q")
+
+	extendDeadlines(w)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="users.`+string(format)+`"`)
+
+	writer, err := bulk.NewWriter(format, w)
+	if err != nil {
+		log.Printf("Export failed: %v", err)
+		return
+	}
+	rc := http.NewResponseController(w)
+	written := 0
+	err = uc.Export(r.Context(), filter, func(user *entity.User) error {
+		if err := writer.Write(user); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	// The status line has already been sent, so a failure can only cut the
+	// stream short
+	if err != nil {
+		log.Printf("Export failed after %d users: %v", written, err)
+	}
+}
+
+// GetUserByLegacyIDHandler handles GET /api/admin/users/legacy/{id}
+// It resolves an integer ID from before the switch to UUIDs. Only admins
+// may use it, so the old sequential IDs cannot be walked.
+func GetUserByLegacyIDHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	legacyID, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/admin/users/legacy/"), 10, 64)
+	if err != nil || legacyID <= 0 {
+		writeBadRequest(w, errors.New("legacy user ID must be a positive integer"))
+		return
+	}
+
+	user, err := uc.GetUserByLegacyID(r.Context(), legacyID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if user == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertUserToResponse(user))
+}
+
+// importFormat picks the import format from ?format= or the Content-Type.
+func importFormat(r *http.Request) (bulk.Format, error) {
+	if v := r.URL.Query().Get("format"); v != "" {
+		return bulk.ParseFormat(v)
+	}
+	return bulk.FormatFromContentType(r.Header.Get("Content-Type"))
+}
+
+// extendDeadlines lifts the server's read and write timeouts for a
+// streaming request. Unsupported writers keep the defaults.
+func extendDeadlines(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+}
+
+// SuspendUserRequest is the body of POST /api/admin/users/{id}/suspend.
+// Until is an RFC 3339 time; without it the suspension is indefinite.
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+	Until  string `json:"until"`
+}
+
+// SuspendUserHandler handles POST /api/admin/users/{id}/suspend
+// The user's sessions are revoked and they cannot sign in until reinstated
+// or the suspension ends.
+func SuspendUserHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := parseAdminUserPath(r.URL.Path, "/suspend")
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	var req SuspendUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+	var until *time.Time
+	if req.Until != "" {
+		t, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil {
+			writeValidationError(w, entity.NewFieldError("until", errors.New("until must be an RFC 3339 time")))
+			return
+		}
+		until = &t
+	}
+
+	user, err := uc.SuspendUser(r.Context(), ClaimsFromContext(r.Context()).UserID, id, req.Reason, until)
+	if err != nil {
+		writeStatusChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertUserToResponse(user))
+}
+
+// StatusReasonRequest is the body of POST /api/admin/users/{id}/lock and
+// /ban.
+type StatusReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// LockUserHandler handles POST /api/admin/users/{id}/lock
+// The user's sessions are revoked and they cannot sign in until reinstated.
+func LockUserHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
+	changeStatusWithReason(w, r, "/lock", uc.LockUser)
+}
+
+// BanUserHandler handles POST /api/admin/users/{id}/ban
+// The user's sessions are revoked and they cannot sign in until reinstated.
+func BanUserHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
+	changeStatusWithReason(w, r, "/ban", uc.BanUser)
+}
+
+// changeStatusWithReason applies change, a status use case that only takes
+// a reason, to the user named in the path.
+func changeStatusWithReason(w http.ResponseWriter, r *http.Request, action string, change func(ctx context.Context, actor, id entity.UserID, reason string) (*entity.User, error)) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := parseAdminUserPath(r.URL.Path, action)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	var req StatusReasonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+
+	user, err := change(r.Context(), ClaimsFromContext(r.Context()).UserID, id, req.Reason)
+	if err != nil {
+		writeStatusChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertUserToResponse(user))
+}
+
+// ReinstateUserHandler handles POST /api/admin/users/{id}/reinstate
+func ReinstateUserHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := parseAdminUserPath(r.URL.Path, "/reinstate")
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	user, err := uc.ReinstateUser(r.Context(), ClaimsFromContext(r.Context()).UserID, id)
+	if err != nil {
+		writeStatusChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertUserToResponse(user))
+}
+
+// parseAdminUserPath extracts {id} from /api/admin/users/{id}{action}.
+func parseAdminUserPath(path, action string) (entity.UserID, error) {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(path, "/api/admin/users/"), action)
+	if !ok {
+		return "", errors.New("expected /api/admin/users/{id}" + action)
+	}
+	return entity.ParseUserID(id)
+}
+
+// writeStatusChangeError maps account status use case errors to 403, 404,
+// 409 and 422.
+func writeStatusChangeError(w http.ResponseWriter, err error) {
+	var verr *entity.ValidationError
+	switch {
+	case errors.Is(err, userUseCase.ErrOwnAccount):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.Is(err, userUseCase.ErrUserNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidStatusTransition),
+		errors.Is(err, repository.ErrVersionConflict):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to change account status"})
+	}
+}
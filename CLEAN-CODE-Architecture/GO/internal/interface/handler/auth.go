@@ -8,10 +8,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"auth-module/internal/domain/entity"
-	"auth-module/internal/interface/repository/postgres"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
 	"auth-module/internal/usecase/auth"
 )
 
@@ -26,7 +29,8 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-func RegisterHandler(w http.ResponseWriter, r *http.Request) {
+// RegisterHandlerWithRepo handles POST /register through the RegisterUseCase.
+func RegisterHandlerWithRepo(w http.ResponseWriter, r *http.Request, uc *auth.RegisterUseCase) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var req RegisterRequest
@@ -36,32 +40,40 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create user entity
-	user := &entity.User{
+	user, err := uc.Register(r.Context(), auth.RegisterInput{
 		Username: req.Username,
 		Email:    req.Email,
 		Password: req.Password,
+	})
+	if err != nil {
+		writeRegisterError(w, err)
+		return
 	}
 
-	// Initialize repository
-	repo := postgres.NewUserRepo(nil) // Pass the appropriate DB connection
-
-	// Call register use case
-	err := auth.Register(r.Context(), repo, user)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	if uc.ConcealsExistingEmail() {
+		// Same answer whether the account was created or the email was
+		// already registered.
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Registration received",
+			"email":   req.Email,
+		})
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "User registered successfully",
-		"email":   user.Email,
+		"email":   user.Email.String(),
 	})
 }
 
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
+// LoginHandlerWithRepo handles POST /login through the LoginUseCase and
+// starts a session for the device. It returns a bearer access token for
+// the API and a refresh token for POST /token/refresh. Unknown emails and
+// wrong passwords get the same 401 response; stopped accounts and clients
+// in a refused country get 403 with a code such as account_suspended.
+func LoginHandlerWithRepo(w http.ResponseWriter, r *http.Request, uc *auth.LoginUseCase, sessions *auth.SessionUseCase) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var req LoginRequest
@@ -71,87 +83,178 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Initialize repository
-	repo := postgres.NewUserRepo(nil) // Pass the appropriate DB connection
-
-	// Call login use case
-	success, err := auth.Login(r.Context(), repo, req.Email, req.Password)
-	if err != nil {
+	client := clientInfo(r)
+	user, err := uc.Login(r.Context(), req.Email, req.Password, client)
+	switch {
+	case errors.Is(err, service.ErrHasherOverloaded):
+		writeOverloaded(w)
+		return
+	case errors.Is(err, auth.ErrInvalidCredentials):
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
+	case accountStatusCode(err) != "":
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": accountStatusCode(err)})
+		return
+	case errors.Is(err, policy.ErrCountryNotAllowed):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": "country_not_allowed"})
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Login failed"})
+		return
 	}
 
-	if !success {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid credentials"})
+	tokens, err := sessions.Start(r.Context(), user, client)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Login failed"})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	writeTokens(w, tokens, map[string]interface{}{
 		"message": "Login successful",
-		"email":   req.Email,
+		"email":   user.Email.String(),
 	})
 }
 
-func RegisterHandlerWithRepo(w http.ResponseWriter, r *http.Request, repo *postgres.UserRepo) {
-	w.Header().Set("Content-Type", "application/json")
+// clientInfo describes the client of r for the login history and sessions.
+func clientInfo(r *http.Request) auth.ClientInfo {
+	return auth.ClientInfo{
+		UserAgent:      r.UserAgent(),
+		IP:             ClientIPFromContext(r.Context()),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+	}
+}
 
-	var req RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+// writeRegisterError renders a registration failure. Domain validation and
+// password policy failures become 422 with a message per field, and
+// unique-field conflicts become 409 with the offending field so clients can
+// highlight it.
+func writeRegisterError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrHasherOverloaded) {
+		writeOverloaded(w)
 		return
 	}
 
-	user := &entity.User{
-		Username: req.Username,
-		Email:    req.Email,
-		Password: req.Password,
+	if writeRegistrationPolicyError(w, err) {
+		return
 	}
 
-	err := auth.Register(r.Context(), repo, user)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	var verr *entity.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "User registered successfully",
-		"email":   user.Email,
+	var perr *policy.PasswordPolicyError
+	if errors.As(err, &perr) {
+		writePasswordPolicyError(w, perr)
+		return
+	}
+
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": conflict.Error(),
+			"field": conflict.Field,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// writeValidationError renders a domain validation failure as 422 with one
+// message per invalid field.
+func writeValidationError(w http.ResponseWriter, verr *entity.ValidationError) {
+	fields := make(map[string]string, len(verr.Fields))
+	for _, f := range verr.Fields {
+		fields[f.Field] = f.Message
+	}
+
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  "Validation failed",
+		"fields": fields,
 	})
 }
 
-func LoginHandlerWithRepo(w http.ResponseWriter, r *http.Request, repo *postgres.UserRepo) {
+// writeRegistrationPolicyError renders a registration policy refusal and
+// reports whether err was one. Refusals that depend only on the mode are 403;
+// those about the address are 422 on the email field, like other email
+// validation failures.
+func writeRegistrationPolicyError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, policy.ErrRegistrationClosed),
+		errors.Is(err, policy.ErrInvitationRequired):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.Is(err, policy.ErrEmailDomainNotAllowed),
+		errors.Is(err, policy.ErrDisposableEmail):
+		writeValidationError(w, entity.NewFieldError("email", err))
+	default:
+		return false
+	}
+	return true
+}
+
+// writePasswordPolicyError renders a password policy failure as 422. The
+// structured violations let clients show every unmet requirement.
+func writePasswordPolicyError(w http.ResponseWriter, perr *policy.PasswordPolicyError) {
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":      "Validation failed",
+		"fields":     map[string]string{"password": perr.Violations[0].Message},
+		"violations": perr.Violations,
+	})
+}
+
+// writeOverloaded tells the client the password hashing pool is saturated.
+// Failing fast keeps a login storm from tying up connections and CPU.
+func writeOverloaded(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]string{"error": service.ErrHasherOverloaded.Error()})
+}
+
+// ChangePasswordRequest is the body of POST /api/users/me/password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ChangePasswordHandler handles POST /api/users/me/password
+// The new password goes through the same policy as registration. Admins
+// impersonating the user are turned away by ForbidImpersonation.
+func ChangePasswordHandler(w http.ResponseWriter, r *http.Request, uc *auth.PasswordUseCase) {
 	w.Header().Set("Content-Type", "application/json")
 
-	var req LoginRequest
+	var req ChangePasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		writeBadRequest(w, errors.New("Invalid request body"))
 		return
 	}
 
-	success, err := auth.Login(r.Context(), repo, req.Email, req.Password)
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
+	err := uc.ChangePassword(r.Context(), ClaimsFromContext(r.Context()).UserID, req.CurrentPassword, req.NewPassword)
+	switch {
+	case errors.Is(err, auth.ErrInvalidCurrentPassword):
+		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
-	}
-
-	if !success {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid credentials"})
+	case errors.Is(err, auth.ErrUserNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		writeRegisterError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Login successful",
-		"email":   req.Email,
-	})
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password changed"})
 }
-
@@ -0,0 +1,145 @@
+package handler
+
+// This file converts keyset pagination cursors to and from the opaque
+// tokens exposed in API responses.
+// - Tokens are signed with HMAC-SHA256 so clients cannot forge positions or
+//   learn anything from tampering.
+// - The token format is an HTTP concern; use cases only see repository.UserCursor
+//   and repository.MemberCursor.
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// ErrInvalidCursor is returned for malformed or tampered cursor tokens.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorCodec signs and verifies pagination cursor tokens.
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec creates a CursorCodec using secret as the HMAC key.
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// cursorPayload is the JSON body of a token. The sort travels with the
+// cursor so that later pages keep the order of the first one.
+type cursorPayload struct {
+	SortField string `json:"s"`
+	SortDesc  bool   `json:"desc,omitempty"`
+	Key       string `json:"k"`
+	ID        string `json:"id"`
+	Direction string `json:"d"`
+}
+
+// Encode returns the token for cursor, or "" when cursor is nil.
+func (c *CursorCodec) Encode(cursor *repository.UserCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return c.seal(cursorPayload{
+		SortField: string(cursor.Sort.Field),
+		SortDesc:  cursor.Sort.Desc,
+		Key:       cursor.Key,
+		ID:        string(cursor.ID),
+		Direction: string(cursor.Direction),
+	})
+}
+
+// Decode verifies token and returns the cursor it encodes. An empty token
+// decodes to a nil cursor (the first page).
+func (c *CursorCodec) Decode(token string) (*repository.UserCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	var p cursorPayload
+	if err := c.open(token, &p); err != nil {
+		return nil, err
+	}
+	direction := repository.CursorDirection(p.Direction)
+	if direction != repository.CursorNext && direction != repository.CursorPrev {
+		return nil, ErrInvalidCursor
+	}
+	field := repository.UserSortField(p.SortField)
+	if !field.Valid() {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.UserCursor{
+		Sort:      repository.UserSort{Field: field, Desc: p.SortDesc},
+		Key:       p.Key,
+		ID:        entity.UserID(p.ID),
+		Direction: direction,
+	}, nil
+}
+
+// memberCursorPayload is the JSON body of a group member cursor token.
+type memberCursorPayload struct {
+	JoinedAt time.Time `json:"j"`
+	UserID   string    `json:"u"`
+}
+
+// EncodeMember returns the token for a group member cursor, or "" when
+// cursor is nil.
+func (c *CursorCodec) EncodeMember(cursor *repository.MemberCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return c.seal(memberCursorPayload{JoinedAt: cursor.JoinedAt, UserID: string(cursor.UserID)})
+}
+
+// DecodeMember verifies token and returns the member cursor it encodes. An
+// empty token decodes to a nil cursor (the first page).
+func (c *CursorCodec) DecodeMember(token string) (*repository.MemberCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	var p memberCursorPayload
+	if err := c.open(token, &p); err != nil {
+		return nil, err
+	}
+	return &repository.MemberCursor{JoinedAt: p.JoinedAt, UserID: entity.UserID(p.UserID)}, nil
+}
+
+// seal encodes v as a signed token.
+func (c *CursorCodec) seal(v interface{}) string {
+	body, _ := json.Marshal(v)
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// open verifies token and decodes its payload into v.
+func (c *CursorCodec) open(token string, v interface{}) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return ErrInvalidCursor
+	}
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func (c *CursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
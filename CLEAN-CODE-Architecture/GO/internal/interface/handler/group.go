@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	groupUseCase "auth-module/internal/usecase/group"
+)
+
+// GroupResponse is the API representation of a group.
+type GroupResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+func convertGroupToResponse(group *entity.Group) GroupResponse {
+	return GroupResponse{
+		ID:          string(group.ID),
+		Name:        group.Name.String(),
+		Description: group.Description,
+		CreatedAt:   group.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   group.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// MembershipResponse is the API representation of a group membership.
+type MembershipResponse struct {
+	GroupID  string `json:"group_id"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+func convertMembershipToResponse(membership *entity.Membership) MembershipResponse {
+	return MembershipResponse{
+		GroupID:  string(membership.GroupID),
+		UserID:   string(membership.UserID),
+		Role:     string(membership.Role),
+		JoinedAt: membership.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// UserGroupResponse is a group in the list of a user's groups.
+type UserGroupResponse struct {
+	GroupResponse
+	Role string `json:"role"`
+}
+
+// CreateGroupRequest is the body of POST /api/groups.
+type CreateGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AddMemberRequest is the body of POST /api/groups/{id}/members. Role
+// defaults to "member".
+type AddMemberRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// CreateGroupHandler handles POST /api/groups (admin only).
+func CreateGroupHandler(w http.ResponseWriter, r *http.Request, uc *groupUseCase.GroupUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req CreateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+
+	group, err := uc.CreateGroup(r.Context(), actorFromRequest(r), req.Name, req.Description)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(convertGroupToResponse(group))
+}
+
+// AddGroupMemberHandler handles POST /api/groups/{id}/members. Adding an
+// existing member changes their role. Admins and group owners only.
+func AddGroupMemberHandler(w http.ResponseWriter, r *http.Request, uc *groupUseCase.GroupUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, rest, err := parseGroupPath(r.URL.Path)
+	if err != nil || rest != "members" {
+		writeBadRequest(w, errors.New("expected /api/groups/{id}/members"))
+		return
+	}
+	var req AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+	userID, err := entity.ParseUserID(req.UserID)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	role := entity.GroupRoleMember
+	if req.Role != "" {
+		if role, err = entity.ParseGroupRole(req.Role); err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+	}
+
+	membership, err := uc.AddMember(r.Context(), actorFromRequest(r), groupID, userID, role)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertMembershipToResponse(membership))
+}
+
+// RemoveGroupMemberHandler handles DELETE /api/groups/{id}/members/{userID}.
+// Admins and group owners may remove anyone; members may remove themselves.
+func RemoveGroupMemberHandler(w http.ResponseWriter, r *http.Request, uc *groupUseCase.GroupUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, rest, err := parseGroupPath(r.URL.Path)
+	member, ok := strings.CutPrefix(rest, "members/")
+	if err != nil || !ok {
+		writeBadRequest(w, errors.New("expected /api/groups/{id}/members/{user_id}"))
+		return
+	}
+	userID, err := entity.ParseUserID(member)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	if err := uc.RemoveMember(r.Context(), actorFromRequest(r), groupID, userID); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListGroupMembersHandler handles GET /api/groups/{id}/members
+// Query parameters: limit (default 10, max 100) and cursor, the next_cursor
+// of the previous page. Admins and members of the group only.
+func ListGroupMembersHandler(w http.ResponseWriter, r *http.Request, uc *groupUseCase.GroupUseCase, cursors *CursorCodec) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, rest, err := parseGroupPath(r.URL.Path)
+	if err != nil || rest != "members" {
+		writeBadRequest(w, errors.New("expected /api/groups/{id}/members"))
+		return
+	}
+	limit := 10
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	after, err := cursors.DecodeMember(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	page, err := uc.ListMembers(r.Context(), actorFromRequest(r), repository.MemberPageQuery{
+		GroupID: groupID,
+		Limit:   limit,
+		After:   after,
+	})
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	members := make([]MembershipResponse, len(page.Members))
+	for i, membership := range page.Members {
+		members[i] = convertMembershipToResponse(membership)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"members":     members,
+		"next_cursor": cursors.EncodeMember(page.Next),
+		"has_more":    page.Next != nil,
+	})
+}
+
+// ListUserGroupsHandler handles GET /api/users/{id}/groups. Users may list
+// their own groups and admins anyone's.
+func ListUserGroupsHandler(w http.ResponseWriter, r *http.Request, uc *groupUseCase.GroupUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/users/"), "/groups")
+	userID, err := entity.ParseUserID(path)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	groups, err := uc.ListUserGroups(r.Context(), actorFromRequest(r), userID)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	responses := make([]UserGroupResponse, len(groups))
+	for i, g := range groups {
+		responses[i] = UserGroupResponse{GroupResponse: convertGroupToResponse(g.Group), Role: string(g.Role)}
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"groups": responses})
+}
+
+// actorFromRequest returns the authenticated caller set by RequireAuth.
+func actorFromRequest(r *http.Request) groupUseCase.Actor {
+	claims := ClaimsFromContext(r.Context())
+	if claims == nil {
+		return groupUseCase.Actor{}
+	}
+	return groupUseCase.Actor{UserID: claims.UserID, Role: claims.Role}
+}
+
+// parseGroupPath splits /api/groups/{id}/rest into the group ID and rest.
+func parseGroupPath(path string) (entity.GroupID, string, error) {
+	id, rest, _ := strings.Cut(strings.TrimPrefix(path, "/api/groups/"), "/")
+	groupID, err := entity.ParseGroupID(id)
+	return groupID, rest, err
+}
+
+// writeGroupError maps group use case errors to 403, 404, 409 and 422.
+func writeGroupError(w http.ResponseWriter, err error) {
+	var verr *entity.ValidationError
+	var conflict *repository.ConflictError
+	switch {
+	case errors.Is(err, groupUseCase.ErrForbidden):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Insufficient permissions"})
+	case errors.Is(err, groupUseCase.ErrGroupNotFound),
+		errors.Is(err, groupUseCase.ErrUserNotFound),
+		errors.Is(err, groupUseCase.ErrNotMember):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	case errors.As(err, &conflict):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": conflict.Error(),
+			"field": conflict.Field,
+		})
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Group request failed"})
+	}
+}
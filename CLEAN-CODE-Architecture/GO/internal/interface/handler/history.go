@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/usecase/auth"
+)
+
+// LoginAttemptResponse is the API representation of a login attempt.
+type LoginAttemptResponse struct {
+	ID            string            `json:"id"`
+	Success       bool              `json:"success"`
+	FailureReason string            `json:"failure_reason,omitempty"`
+	IP            string            `json:"ip"`
+	Location      *LocationResponse `json:"location,omitempty"`
+	UserAgent     string            `json:"user_agent"`
+	Device        DeviceResponse    `json:"device"`
+	Fingerprint   string            `json:"device_fingerprint"`
+	RiskFlags     []string          `json:"risk_flags,omitempty"`
+	SessionID     string            `json:"session_id,omitempty"`
+	CreatedAt     string            `json:"created_at"`
+}
+
+func convertLoginAttemptToResponse(attempt *entity.LoginAttempt) LoginAttemptResponse {
+	response := LoginAttemptResponse{
+		ID:            string(attempt.ID),
+		Success:       attempt.Success,
+		FailureReason: attempt.FailureReason,
+		IP:            attempt.IP,
+		Location:      convertLocationToResponse(attempt.Location),
+		UserAgent:     attempt.UserAgent,
+		Device:        convertDeviceToResponse(attempt.Device),
+		Fingerprint:   attempt.Fingerprint,
+		RiskFlags:     riskFlagNames(attempt.RiskFlags),
+		CreatedAt:     attempt.CreatedAt.Format(time.RFC3339),
+	}
+	if attempt.SessionID != nil {
+		response.SessionID = string(*attempt.SessionID)
+	}
+	return response
+}
+
+func riskFlagNames(flags []entity.RiskFlag) []string {
+	if len(flags) == 0 {
+		return nil
+	}
+	names := make([]string, len(flags))
+	for i, flag := range flags {
+		names[i] = string(flag)
+	}
+	return names
+}
+
+// ListMyLoginsHandler handles GET /api/users/me/logins
+// Query parameters: limit (default 20, max 100) and cursor, the
+// next_cursor of the previous page. Attempts are listed newest first,
+// failed ones included.
+func ListMyLoginsHandler(w http.ResponseWriter, r *http.Request, history *auth.LoginHistory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	limit := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	var before entity.LoginAttemptID
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		id, err := entity.ParseLoginAttemptID(cursor)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+		before = id
+	}
+
+	page, err := history.List(r.Context(), ClaimsFromContext(r.Context()).UserID, before, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list logins"})
+		return
+	}
+
+	logins := make([]LoginAttemptResponse, len(page.Attempts))
+	for i, attempt := range page.Attempts {
+		logins[i] = convertLoginAttemptToResponse(attempt)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"logins":      logins,
+		"next_cursor": string(page.Next),
+		"has_more":    page.Next != "",
+	})
+}
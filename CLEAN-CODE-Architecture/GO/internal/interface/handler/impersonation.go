@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/usecase/auth"
+)
+
+// ImpersonationResponse is the API representation of an impersonation.
+type ImpersonationResponse struct {
+	ID        string  `json:"id"`
+	ActorID   string  `json:"actor_id"`
+	UserID    string  `json:"user_id"`
+	Reason    string  `json:"reason"`
+	Active    bool    `json:"active"`
+	StartedAt string  `json:"started_at"`
+	ExpiresAt string  `json:"expires_at"`
+	EndedAt   *string `json:"ended_at,omitempty"`
+	EndedBy   *string `json:"ended_by,omitempty"`
+}
+
+func convertImpersonationToResponse(impersonation *entity.Impersonation, now time.Time) ImpersonationResponse {
+	response := ImpersonationResponse{
+		ID:        string(impersonation.ID),
+		ActorID:   string(impersonation.ActorID),
+		UserID:    string(impersonation.TargetID),
+		Reason:    impersonation.Reason,
+		Active:    impersonation.Active(now),
+		StartedAt: impersonation.StartedAt.Format(time.RFC3339),
+		ExpiresAt: impersonation.ExpiresAt.Format(time.RFC3339),
+		EndedAt:   formatOptionalTime(impersonation.EndedAt),
+	}
+	if impersonation.EndedBy != nil {
+		by := string(*impersonation.EndedBy)
+		response.EndedBy = &by
+	}
+	return response
+}
+
+// ImpersonateRequest is the body of POST /api/admin/impersonate/{id}.
+type ImpersonateRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonateUserHandler handles POST /api/admin/impersonate/{id}
+// It returns a short-lived access token for the user whose act claim names
+// the admin. The token cannot be used for sensitive actions such as
+// changing the password.
+func ImpersonateUserHandler(w http.ResponseWriter, r *http.Request, uc *auth.ImpersonationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	target, err := entity.ParseUserID(strings.TrimPrefix(r.URL.Path, "/api/admin/impersonate/"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	var req ImpersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+
+	started, err := uc.Start(r.Context(), ClaimsFromContext(r.Context()).UserID, target, req.Reason)
+	if err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  started.Token,
+		"token_type":    "Bearer",
+		"expires_in":    int(time.Until(started.Claims.ExpiresAt).Seconds()),
+		"impersonation": convertImpersonationToResponse(started.Impersonation, time.Now()),
+	})
+}
+
+// EndImpersonationHandler handles POST /api/impersonation/end
+// It is called with the impersonation token and ends that impersonation.
+func EndImpersonationHandler(w http.ResponseWriter, r *http.Request, uc *auth.ImpersonationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ClaimsFromContext(r.Context())
+	if !claims.Impersonated() {
+		writeBadRequest(w, errors.New("token is not an impersonation token"))
+		return
+	}
+
+	impersonation, err := uc.End(r.Context(), claims.ImpersonationID, claims.ActorID)
+	if err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertImpersonationToResponse(impersonation, time.Now()))
+}
+
+// EndImpersonationByIDHandler handles DELETE /api/admin/impersonations/{id}
+// Any admin of the tenant can end a running impersonation.
+func EndImpersonationByIDHandler(w http.ResponseWriter, r *http.Request, uc *auth.ImpersonationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := entity.ParseImpersonationID(strings.TrimPrefix(r.URL.Path, "/api/admin/impersonations/"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	impersonation, err := uc.End(r.Context(), id, ClaimsFromContext(r.Context()).UserID)
+	if err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertImpersonationToResponse(impersonation, time.Now()))
+}
+
+// ListImpersonationsHandler handles GET /api/admin/impersonations
+// The optional actor_id and user_id parameters select the impersonations by
+// an admin or of a user.
+func ListImpersonationsHandler(w http.ResponseWriter, r *http.Request, uc *auth.ImpersonationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var filter repository.ImpersonationFilter
+	if v := r.URL.Query().Get("actor_id"); v != "" {
+		actor, err := entity.ParseUserID(v)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+		filter.ActorID = &actor
+	}
+	if v := r.URL.Query().Get("user_id"); v != "" {
+		target, err := entity.ParseUserID(v)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+		filter.TargetID = &target
+	}
+
+	impersonations, err := uc.List(r.Context(), filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list impersonations"})
+		return
+	}
+
+	now := time.Now()
+	responses := make([]ImpersonationResponse, len(impersonations))
+	for i, impersonation := range impersonations {
+		responses[i] = convertImpersonationToResponse(impersonation, now)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"impersonations": responses})
+}
+
+// writeImpersonationError maps impersonation use case errors to 403, 404,
+// 409 and 422. Stopped target accounts and ended impersonations are 409
+// with a code.
+func writeImpersonationError(w http.ResponseWriter, err error) {
+	if code := accountStatusCode(err); code != "" {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": code})
+		return
+	}
+
+	var verr *entity.ValidationError
+	switch {
+	case errors.Is(err, auth.ErrCannotImpersonate):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.Is(err, auth.ErrUserNotFound), errors.Is(err, auth.ErrImpersonationNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Impersonation request failed"})
+	}
+}
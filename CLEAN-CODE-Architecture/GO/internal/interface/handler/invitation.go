@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	invitationUseCase "auth-module/internal/usecase/invitation"
+)
+
+// InvitationResponse is the API representation of an invitation. The link
+// token is never returned; it only travels by email.
+type InvitationResponse struct {
+	ID         string  `json:"id"`
+	Email      string  `json:"email"`
+	InvitedBy  string  `json:"invited_by"`
+	Role       string  `json:"role"`
+	GroupID    *string `json:"group_id,omitempty"`
+	Status     string  `json:"status"`
+	ExpiresAt  string  `json:"expires_at"`
+	SentAt     *string `json:"sent_at,omitempty"`
+	AcceptedAt *string `json:"accepted_at,omitempty"`
+	AcceptedBy *string `json:"accepted_by,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+func convertInvitationToResponse(invitation *entity.Invitation, now time.Time) InvitationResponse {
+	response := InvitationResponse{
+		ID:         string(invitation.ID),
+		Email:      invitation.Email.String(),
+		InvitedBy:  string(invitation.InvitedBy),
+		Role:       string(invitation.Role),
+		Status:     string(invitation.State(now)),
+		ExpiresAt:  invitation.ExpiresAt.Format(time.RFC3339),
+		SentAt:     formatOptionalTime(invitation.SentAt),
+		AcceptedAt: formatOptionalTime(invitation.AcceptedAt),
+		CreatedAt:  invitation.CreatedAt.Format(time.RFC3339),
+	}
+	if invitation.GroupID != nil {
+		group := string(*invitation.GroupID)
+		response.GroupID = &group
+	}
+	if invitation.AcceptedBy != nil {
+		user := string(*invitation.AcceptedBy)
+		response.AcceptedBy = &user
+	}
+	return response
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+// CreateInvitationRequest is the body of POST /api/admin/invitations.
+type CreateInvitationRequest struct {
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	GroupID string `json:"group_id"`
+}
+
+// AcceptInvitationRequest is the body of POST /invitations/{token}/accept.
+type AcceptInvitationRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CreateInvitationHandler handles POST /api/admin/invitations
+// The link is emailed to the invitee. When the email cannot be sent the
+// invitation is still created and the response is 502; resend it later.
+func CreateInvitationHandler(w http.ResponseWriter, r *http.Request, uc *invitationUseCase.InvitationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+	input := invitationUseCase.InviteInput{
+		Email:     req.Email,
+		InvitedBy: ClaimsFromContext(r.Context()).UserID,
+	}
+	if req.Role != "" {
+		role, err := entity.ParseRole(req.Role)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+		input.Role = role
+	}
+	if req.GroupID != "" {
+		group, err := entity.ParseGroupID(req.GroupID)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+		input.GroupID = &group
+	}
+
+	invitation, err := uc.Invite(r.Context(), input)
+	if err != nil && !errors.Is(err, invitationUseCase.ErrDeliveryFailed) {
+		writeInvitationError(w, err)
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      err.Error(),
+			"invitation": convertInvitationToResponse(invitation, time.Now()),
+		})
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(convertInvitationToResponse(invitation, time.Now()))
+}
+
+// ListInvitationsHandler handles GET /api/admin/invitations
+// The optional status parameter (pending, accepted, revoked or expired)
+// filters by current state.
+func ListInvitationsHandler(w http.ResponseWriter, r *http.Request, uc *invitationUseCase.InvitationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var status *entity.InvitationStatus
+	if v := r.URL.Query().Get("status"); v != "" {
+		parsed, err := entity.ParseInvitationStatus(v)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+		status = &parsed
+	}
+
+	invitations, err := uc.ListInvitations(r.Context(), status)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list invitations"})
+		return
+	}
+
+	now := time.Now()
+	responses := make([]InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = convertInvitationToResponse(invitation, now)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"invitations": responses})
+}
+
+// ResendInvitationHandler handles POST /api/admin/invitations/{id}/resend
+// A new link replaces the old one and the expiry restarts.
+func ResendInvitationHandler(w http.ResponseWriter, r *http.Request, uc *invitationUseCase.InvitationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/admin/invitations/")
+	id, err := entity.ParseInvitationID(strings.TrimSuffix(path, "/resend"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	invitation, err := uc.Resend(r.Context(), id)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertInvitationToResponse(invitation, time.Now()))
+}
+
+// RevokeInvitationHandler handles DELETE /api/admin/invitations/{id}
+// The invitation is kept for the record with status revoked.
+func RevokeInvitationHandler(w http.ResponseWriter, r *http.Request, uc *invitationUseCase.InvitationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := entity.ParseInvitationID(strings.TrimPrefix(r.URL.Path, "/api/admin/invitations/"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	invitation, err := uc.Revoke(r.Context(), id)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertInvitationToResponse(invitation, time.Now()))
+}
+
+// AcceptInvitationHandler handles POST /invitations/{token}/accept
+// It creates the invited account in the invitation's tenant unless
+// registration is closed. Unknown links get 404, and used, revoked or
+// expired ones 410.
+func AcceptInvitationHandler(w http.ResponseWriter, r *http.Request, uc *invitationUseCase.InvitationUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	token, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/invitations/"), "/accept")
+	if !ok || token == "" || strings.Contains(token, "/") {
+		writeBadRequest(w, errors.New("expected /invitations/{token}/accept"))
+		return
+	}
+	var req AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+
+	user, err := uc.Accept(r.Context(), invitationUseCase.AcceptInput{
+		Token:    token,
+		Username: req.Username,
+		Password: req.Password,
+	})
+	switch {
+	case errors.Is(err, invitationUseCase.ErrInvitationNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invitation not found"})
+		return
+	case errors.Is(err, entity.ErrInvitationExpired), errors.Is(err, entity.ErrInvitationNotPending):
+		w.WriteHeader(http.StatusGone)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		writeRegisterError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Invitation accepted",
+		"user":    convertUserToResponse(user),
+	})
+}
+
+// writeInvitationError maps invitation use case errors to 403, 404, 409 and
+// 422.
+func writeInvitationError(w http.ResponseWriter, err error) {
+	if writeRegistrationPolicyError(w, err) {
+		return
+	}
+
+	var verr *entity.ValidationError
+	switch {
+	case errors.Is(err, invitationUseCase.ErrInvitationNotFound),
+		errors.Is(err, invitationUseCase.ErrGroupNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.Is(err, invitationUseCase.ErrAlreadyInvited),
+		errors.Is(err, invitationUseCase.ErrAlreadyRegistered),
+		errors.Is(err, entity.ErrInvitationNotPending):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.Is(err, invitationUseCase.ErrDeliveryFailed):
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invitation request failed"})
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/usecase/auth"
+)
+
+// MagicLinkRequest is the body of POST /login/magic-link.
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+// RequestMagicLinkHandler handles POST /login/magic-link
+// It emails a sign-in link to the account with the email. The response is
+// 202 whether or not a link was sent, so it does not reveal which emails
+// are registered.
+func RequestMagicLinkHandler(w http.ResponseWriter, r *http.Request, uc *auth.MagicLinkUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+
+	uc.Request(r.Context(), req.Email, clientInfo(r))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "If the email is registered, a sign-in link has been sent",
+	})
+}
+
+// VerifyMagicLinkHandler handles GET /login/magic-link/verify?token=...
+// It signs in with the link and returns the same tokens as POST /login.
+// Unknown, expired and used links get 401 with a code; stopped accounts
+// and clients in a refused country get 403 as on POST /login.
+func VerifyMagicLinkHandler(w http.ResponseWriter, r *http.Request, uc *auth.MagicLinkUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+	// The response carries tokens in answer to a GET
+	w.Header().Set("Cache-Control", "no-store")
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		writeBadRequest(w, errors.New("token is required"))
+		return
+	}
+
+	tokens, err := uc.Verify(r.Context(), token, clientInfo(r))
+	if err != nil {
+		writeMagicLinkError(w, err)
+		return
+	}
+	writeTokens(w, tokens, map[string]interface{}{
+		"message": "Login successful",
+	})
+}
+
+// writeMagicLinkError maps magic link sign-in errors to 401 and 403 with a
+// code, or 500.
+func writeMagicLinkError(w http.ResponseWriter, err error) {
+	status, code := http.StatusUnauthorized, ""
+	switch {
+	case errors.Is(err, auth.ErrInvalidMagicLink):
+		code = "invalid_magic_link"
+	case errors.Is(err, entity.ErrMagicLinkExpired):
+		code = "magic_link_expired"
+	case errors.Is(err, entity.ErrMagicLinkUsed):
+		code = "magic_link_used"
+	case errors.Is(err, policy.ErrCountryNotAllowed):
+		status, code = http.StatusForbidden, "country_not_allowed"
+	case accountStatusCode(err) != "":
+		status, code = http.StatusForbidden, accountStatusCode(err)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Login failed"})
+		return
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": code})
+}
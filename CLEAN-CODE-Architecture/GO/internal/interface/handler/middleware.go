@@ -0,0 +1,125 @@
+package handler
+
+// This file holds HTTP middleware for authentication and authorization.
+// - Access tokens are verified through the service.TokenService port.
+// - Verified claims travel in the request context to the wrapped handler.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+	"auth-module/internal/usecase/auth"
+)
+
+type claimsContextKey struct{}
+
+// ClaimsFromContext returns the access claims stored by RequireAuth, or nil.
+func ClaimsFromContext(ctx context.Context) *service.AccessClaims {
+	claims, _ := ctx.Value(claimsContextKey{}).(*service.AccessClaims)
+	return claims
+}
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer"
+// access token with 401 and otherwise passes the claims on to next. Behind
+// RequireTenant, a token issued for another tenant is rejected too.
+func RequireAuth(tokens service.TokenService, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || raw == "" {
+			writeUnauthorized(w, "Missing bearer token")
+			return
+		}
+		claims, err := tokens.ParseAccessToken(r.Context(), raw)
+		if err != nil {
+			writeTokenError(w, err)
+			return
+		}
+		if tenant, ok := repository.TenantFromContext(r.Context()); ok && tenant != claims.TenantID {
+			writeUnauthorized(w, "Token belongs to another tenant")
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims)))
+	}
+}
+
+// RequireRole is RequireAuth plus a 403 for bearers without role.
+func RequireRole(tokens service.TokenService, role entity.Role, next http.HandlerFunc) http.HandlerFunc {
+	return RequireAuth(tokens, func(w http.ResponseWriter, r *http.Request) {
+		if ClaimsFromContext(r.Context()).Role != role {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Insufficient permissions"})
+			return
+		}
+		next(w, r)
+	})
+}
+
+// ForbidImpersonation rejects impersonation tokens with 403 before next,
+// which must run behind RequireAuth. Wrap sensitive actions, such as
+// changing credentials, that an admin must not take as the user.
+func ForbidImpersonation(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if claims := ClaimsFromContext(r.Context()); claims != nil && claims.Impersonated() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": auth.ErrImpersonationForbidden.Error(),
+				"code":  "impersonation_forbidden",
+			})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeTokenError rejects a token that failed verification with 401, and
+// with the account status code when the account was stopped. Failures to
+// check the account at all are 500.
+func writeTokenError(w http.ResponseWriter, err error) {
+	code := accountStatusCode(err)
+	switch {
+	case code != "":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": code})
+	case errors.Is(err, service.ErrInvalidToken), errors.Is(err, auth.ErrAccountNotFound):
+		writeUnauthorized(w, err.Error())
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Authentication failed"})
+	}
+}
+
+// accountStatusCode returns the machine-readable code for errors about a
+// stopped account or revoked session, or "".
+func accountStatusCode(err error) string {
+	switch {
+	case errors.Is(err, entity.ErrAccountSuspended):
+		return "account_suspended"
+	case errors.Is(err, entity.ErrAccountLocked):
+		return "account_locked"
+	case errors.Is(err, entity.ErrAccountBanned):
+		return "account_banned"
+	case errors.Is(err, auth.ErrSessionRevoked):
+		return "session_revoked"
+	case errors.Is(err, entity.ErrImpersonationEnded):
+		return "impersonation_ended"
+	}
+	return ""
+}
+
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,114 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/interface/handler"
+	"auth-module/internal/interface/repository/repotest"
+	"auth-module/internal/usecase/auth"
+	"auth-module/pkg/hash"
+)
+
+const testPassword = "Sup3r-Secret-Pass!"
+
+func newRegisterUseCase(t *testing.T, b repotest.Backend) *auth.RegisterUseCase {
+	t.Helper()
+	registration, err := policy.NewRegistrationPolicy(policy.RegistrationPolicyConfig{Mode: policy.RegistrationOpen}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.NewRegisterUseCase(b.Users, hash.NewHasher(hash.NewBcrypt(4)),
+		policy.NewPasswordPolicy(policy.DefaultPasswordPolicyConfig(), nil), registration, auth.RegisterOptions{})
+}
+
+type registerResult struct {
+	status int
+	field  string
+}
+
+// registerInParallel posts every body to /register at once.
+func registerInParallel(ctx context.Context, uc *auth.RegisterUseCase, bodies []string) []registerResult {
+	results := make([]registerResult, len(bodies))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, body := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			handler.RegisterHandlerWithRepo(rec, req, uc)
+
+			var resp struct {
+				Field string `json:"field"`
+			}
+			json.NewDecoder(rec.Body).Decode(&resp)
+			results[i] = registerResult{status: rec.Code, field: resp.Field}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return results
+}
+
+// TestRegisterRace hammers /register with clashing accounts: exactly one
+// registration may win, and every other one must get a 409 naming the
+// clashing field, never a 500.
+func TestRegisterRace(t *testing.T) {
+	const parallel = 20
+
+	repotest.Run(t, func(t *testing.T, b repotest.Backend) {
+		t.Run("same email", func(t *testing.T) {
+			ctx := b.NewTenant(t, "email-race")
+			bodies := make([]string, parallel)
+			for i := range bodies {
+				// Emails differ only in case, which must still clash
+				email := "Racer@Example.com"
+				if i%2 == 1 {
+					email = strings.ToLower(email)
+				}
+				bodies[i] = fmt.Sprintf(`{"username": "racer%d", "email": %q, "password": %q}`, i, email, testPassword)
+			}
+			checkOneWinner(t, registerInParallel(ctx, newRegisterUseCase(t, b), bodies), "email")
+		})
+
+		t.Run("same username", func(t *testing.T) {
+			ctx := b.NewTenant(t, "username-race")
+			bodies := make([]string, parallel)
+			for i := range bodies {
+				username := "Racer"
+				if i%2 == 1 {
+					username = "racer"
+				}
+				bodies[i] = fmt.Sprintf(`{"username": %q, "email": "racer%d@example.com", "password": %q}`, username, i, testPassword)
+			}
+			checkOneWinner(t, registerInParallel(ctx, newRegisterUseCase(t, b), bodies), "username")
+		})
+	})
+}
+
+func checkOneWinner(t *testing.T, results []registerResult, field string) {
+	t.Helper()
+	created := 0
+	for _, result := range results {
+		switch {
+		case result.status == http.StatusCreated:
+			created++
+		case result.status == http.StatusConflict && result.field == field:
+		default:
+			t.Errorf("got status %d with field %q, want 201 or 409 on %q", result.status, result.field, field)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d registrations succeeded, want exactly 1", created)
+	}
+}
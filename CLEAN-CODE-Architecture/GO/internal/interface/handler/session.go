@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/usecase/auth"
+)
+
+// SessionResponse is the API representation of a session. Current marks
+// the session of the access token used for the request; RiskFlags are those
+// of the login that started it.
+type SessionResponse struct {
+	ID         string            `json:"id"`
+	Device     DeviceResponse    `json:"device"`
+	UserAgent  string            `json:"user_agent"`
+	IP         string            `json:"ip"`
+	Location   *LocationResponse `json:"location,omitempty"`
+	CreatedAt  string            `json:"created_at"`
+	LastSeenAt string            `json:"last_seen_at"`
+	ExpiresAt  string            `json:"expires_at"`
+	RiskFlags  []string          `json:"risk_flags,omitempty"`
+	Current    bool              `json:"current"`
+}
+
+// DeviceResponse describes the device of a session.
+type DeviceResponse struct {
+	Type    string `json:"type"`
+	Browser string `json:"browser,omitempty"`
+	OS      string `json:"os,omitempty"`
+}
+
+// LocationResponse is where a session or login came from. It is left out
+// when the location is unknown.
+type LocationResponse struct {
+	CountryCode string `json:"country_code"`
+	Country     string `json:"country,omitempty"`
+	City        string `json:"city,omitempty"`
+}
+
+func convertLocationToResponse(location entity.Location) *LocationResponse {
+	if !location.Known() {
+		return nil
+	}
+	return &LocationResponse{
+		CountryCode: location.CountryCode,
+		Country:     location.Country,
+		City:        location.City,
+	}
+}
+
+func convertDeviceToResponse(device entity.Device) DeviceResponse {
+	return DeviceResponse{
+		Type:    string(device.Type),
+		Browser: device.Browser,
+		OS:      device.OS,
+	}
+}
+
+func convertSessionToResponse(session *entity.Session, current entity.SessionID) SessionResponse {
+	return SessionResponse{
+		ID:         string(session.ID),
+		Device:     convertDeviceToResponse(session.Device),
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Location:   convertLocationToResponse(session.Location),
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+		LastSeenAt: session.LastSeenAt.Format(time.RFC3339),
+		ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+		RiskFlags:  riskFlagNames(session.RiskFlags),
+		Current:    current != "" && session.ID == current,
+	}
+}
+
+// writeTokens renders the tokens of a login or refresh.
+func writeTokens(w http.ResponseWriter, tokens *auth.IssuedTokens, extra map[string]interface{}) {
+	body := map[string]interface{}{
+		"access_token":  tokens.AccessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(time.Until(tokens.Claims.ExpiresAt).Seconds()),
+		"refresh_token": tokens.RefreshToken,
+		"session_id":    string(tokens.Session.ID),
+	}
+	for key, value := range extra {
+		body[key] = value
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(body)
+}
+
+// RefreshTokenRequest is the body of POST /token/refresh.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenHandler handles POST /token/refresh
+// It returns a new access token and a new refresh token for the session;
+// the old refresh token stops working. Presenting it again revokes the
+// session.
+func RefreshTokenHandler(w http.ResponseWriter, r *http.Request, uc *auth.SessionUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		writeBadRequest(w, errors.New("refresh_token is required"))
+		return
+	}
+
+	tokens, err := uc.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		writeRefreshError(w, err)
+		return
+	}
+	writeTokens(w, tokens, nil)
+}
+
+// ListMySessionsHandler handles GET /api/users/me/sessions
+func ListMySessionsHandler(w http.ResponseWriter, r *http.Request, uc *auth.SessionUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ClaimsFromContext(r.Context())
+	writeSessions(w, r, uc, claims.UserID, claims.SessionID)
+}
+
+// RevokeMySessionHandler handles DELETE /api/users/me/sessions/{id}
+// Revoking the current session signs the caller out.
+func RevokeMySessionHandler(w http.ResponseWriter, r *http.Request, uc *auth.SessionUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := entity.SessionID(strings.TrimPrefix(r.URL.Path, "/api/users/me/sessions/"))
+	if err := uc.Revoke(r.Context(), ClaimsFromContext(r.Context()).UserID, id); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListUserSessionsHandler handles GET /api/admin/users/{id}/sessions
+func ListUserSessionsHandler(w http.ResponseWriter, r *http.Request, uc *auth.SessionUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, err := parseAdminUserPath(r.URL.Path, "/sessions")
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	writeSessions(w, r, uc, user, ClaimsFromContext(r.Context()).SessionID)
+}
+
+// RevokeUserSessionHandler handles DELETE /api/admin/users/{id}/sessions/{session_id}
+func RevokeUserSessionHandler(w http.ResponseWriter, r *http.Request, uc *auth.SessionUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/admin/users/")
+	userID, sessionID, ok := strings.Cut(rest, "/sessions/")
+	if !ok {
+		writeBadRequest(w, errors.New("expected /api/admin/users/{id}/sessions/{session_id}"))
+		return
+	}
+	user, err := entity.ParseUserID(userID)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	if err := uc.Revoke(r.Context(), user, entity.SessionID(sessionID)); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeSessions(w http.ResponseWriter, r *http.Request, uc *auth.SessionUseCase, user entity.UserID, current entity.SessionID) {
+	sessions, err := uc.List(r.Context(), user)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = convertSessionToResponse(session, current)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"sessions": responses})
+}
+
+// writeSessionError maps session management errors to 404 and 500.
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrSessionNotFound), errors.Is(err, auth.ErrUserNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Session request failed"})
+	}
+}
+
+// writeRefreshError rejects a refresh with 401 and a code telling clients
+// whether to sign in again.
+func writeRefreshError(w http.ResponseWriter, err error) {
+	code := accountStatusCode(err)
+	switch {
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		code = "refresh_token_reused"
+	case errors.Is(err, auth.ErrInvalidRefreshToken), errors.Is(err, auth.ErrAccountNotFound):
+		code = "invalid_refresh_token"
+	case code == "":
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Token refresh failed"})
+		return
+	}
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": code})
+}
@@ -0,0 +1,257 @@
+package handler
+
+// This file maps requests to tenants and serves the tenant admin API.
+// - The tenant is resolved once per request and stored in the context with
+//   repository.WithTenant; repositories then only see that tenant's data.
+// - A bearer token is bound to its tenant: naming another tenant in the
+//   header or subdomain is rejected instead of silently switching.
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+	tenantUseCase "auth-module/internal/usecase/tenant"
+)
+
+// DefaultTenantHeader is the request header that names a tenant by slug or ID.
+const DefaultTenantHeader = "X-Tenant"
+
+// TenantOptions configure how requests are mapped to tenants. The sources
+// are tried in order: the header, the subdomain, the bearer token's tenant
+// and finally Default.
+type TenantOptions struct {
+	// Header names a tenant by slug or ID; DefaultTenantHeader if empty.
+	Header string
+	// BaseDomain enables subdomain resolution: with "auth.example.com", a
+	// request to acme.auth.example.com is for the tenant with slug "acme".
+	BaseDomain string
+	// Default is the slug or ID used when nothing else names a tenant. When
+	// empty, such requests are rejected.
+	Default string
+}
+
+// RequireTenant resolves the tenant of the request and passes it on to next
+// in the context. Unknown tenants get 404, requests that name no tenant and
+// have no default get 400, and a bearer token for another tenant gets 403.
+// An invalid token is ignored here and left to RequireAuth.
+func RequireTenant(tenants *tenantUseCase.TenantUseCase, tokens service.TokenService, opts TenantOptions, next http.HandlerFunc) http.HandlerFunc {
+	header := opts.Header
+	if header == "" {
+		header = DefaultTenantHeader
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var tokenTenant entity.TenantID
+		if raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && raw != "" {
+			if claims, err := tokens.ParseAccessToken(r.Context(), raw); err == nil {
+				tokenTenant = claims.TenantID
+			}
+		}
+
+		ref := strings.TrimSpace(r.Header.Get(header))
+		if ref == "" {
+			ref = subdomain(r.Host, opts.BaseDomain)
+		}
+		if ref == "" && tokenTenant == "" {
+			ref = opts.Default
+		}
+
+		tenant := tokenTenant
+		if ref != "" {
+			resolved, err := tenants.ResolveTenant(r.Context(), ref)
+			switch {
+			case errors.Is(err, tenantUseCase.ErrTenantNotFound):
+				writeTenantError(w, http.StatusNotFound, "Unknown tenant")
+				return
+			case err != nil:
+				writeTenantError(w, http.StatusInternalServerError, "Failed to resolve tenant")
+				return
+			}
+			if tokenTenant != "" && resolved.ID != tokenTenant {
+				writeTenantError(w, http.StatusForbidden, "Token belongs to another tenant")
+				return
+			}
+			tenant = resolved.ID
+		}
+		if tenant == "" {
+			writeTenantError(w, http.StatusBadRequest, "Tenant required: send the "+header+" header")
+			return
+		}
+
+		next(w, r.WithContext(repository.WithTenant(r.Context(), tenant)))
+	}
+}
+
+// RequireTenantManager is RequireRole for admins of the tenant with
+// managerTenant, who manage all tenants. Admins of other tenants get 403.
+func RequireTenantManager(tokens service.TokenService, managerTenant entity.TenantID, next http.HandlerFunc) http.HandlerFunc {
+	return RequireRole(tokens, entity.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if ClaimsFromContext(r.Context()).TenantID != managerTenant {
+			writeTenantError(w, http.StatusForbidden, "Insufficient permissions")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// subdomain returns the label of host directly below baseDomain, or "".
+func subdomain(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+func writeTenantError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// TenantResponse is the API representation of a tenant.
+type TenantResponse struct {
+	ID        string `json:"id"`
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func convertTenantToResponse(tenant *entity.Tenant) TenantResponse {
+	return TenantResponse{
+		ID:        string(tenant.ID),
+		Slug:      tenant.Slug.String(),
+		Name:      tenant.Name,
+		CreatedAt: tenant.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: tenant.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// TenantRequest is the body of POST and PATCH /api/admin/tenants.
+type TenantRequest struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// CreateTenantHandler handles POST /api/admin/tenants
+func CreateTenantHandler(w http.ResponseWriter, r *http.Request, uc *tenantUseCase.TenantUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req TenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+
+	tenant, err := uc.CreateTenant(r.Context(), req.Slug, req.Name)
+	if err != nil {
+		writeTenantUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(convertTenantToResponse(tenant))
+}
+
+// ListTenantsHandler handles GET /api/admin/tenants
+func ListTenantsHandler(w http.ResponseWriter, r *http.Request, uc *tenantUseCase.TenantUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tenants, err := uc.ListTenants(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to list tenants"})
+		return
+	}
+
+	responses := make([]TenantResponse, len(tenants))
+	for i, tenant := range tenants {
+		responses[i] = convertTenantToResponse(tenant)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"tenants": responses})
+}
+
+// GetTenantHandler handles GET /api/admin/tenants/{id}
+func GetTenantHandler(w http.ResponseWriter, r *http.Request, uc *tenantUseCase.TenantUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := entity.ParseTenantID(strings.TrimPrefix(r.URL.Path, "/api/admin/tenants/"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	tenant, err := uc.GetTenant(r.Context(), id)
+	if err != nil {
+		writeTenantUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertTenantToResponse(tenant))
+}
+
+// RenameTenantHandler handles PATCH /api/admin/tenants/{id}. Only the name
+// can change; slugs are permanent.
+func RenameTenantHandler(w http.ResponseWriter, r *http.Request, uc *tenantUseCase.TenantUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := entity.ParseTenantID(strings.TrimPrefix(r.URL.Path, "/api/admin/tenants/"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+	var req TenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+	if req.Slug != "" {
+		writeBadRequest(w, errors.New("slug cannot be changed"))
+		return
+	}
+
+	tenant, err := uc.RenameTenant(r.Context(), id, req.Name)
+	if err != nil {
+		writeTenantUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertTenantToResponse(tenant))
+}
+
+// writeTenantUseCaseError maps tenant use case errors to 404, 409 and 422.
+func writeTenantUseCaseError(w http.ResponseWriter, err error) {
+	var verr *entity.ValidationError
+	var conflict *repository.ConflictError
+	switch {
+	case errors.Is(err, tenantUseCase.ErrTenantNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Tenant not found"})
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	case errors.As(err, &conflict):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": conflict.Error(),
+			"field": conflict.Field,
+		})
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Tenant request failed"})
+	}
+}
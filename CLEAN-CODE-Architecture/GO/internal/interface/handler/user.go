@@ -8,9 +8,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"auth-module/internal/domain/entity"
 	"auth-module/internal/domain/repository"
@@ -20,23 +24,37 @@ import (
 // UserResponse represents the user data returned to client
 type UserResponse struct {
 	ID        string `json:"id"`
+	TenantID  string `json:"tenant_id"`
 	Username  string `json:"username"`
 	Email     string `json:"email"`
 	FirstName string `json:"first_name,omitempty"`
 	LastName  string `json:"last_name,omitempty"`
 	Phone     string `json:"phone,omitempty"`
 	Address   string `json:"address,omitempty"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	// StatusReason and StatusUntil are only set while the account is stopped
+	StatusReason string `json:"status_reason,omitempty"`
+	StatusUntil  string `json:"status_until,omitempty"`
+	// EmailVerified is derived from the verification timestamp
+	EmailVerified bool   `json:"email_verified"`
+	LastLoginAt   string `json:"last_login_at,omitempty"`
+	Version       int64  `json:"version"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
 }
 
-// PaginatedUsersResponse represents paginated user list response
+// PaginatedUsersResponse represents a keyset-paginated user list response.
+// Pass next_cursor or prev_cursor back as the "cursor" query parameter to
+// move between pages. Total is present for searches and, for listings,
+// when include_total=true.
 type PaginatedUsersResponse struct {
 	Users      []UserResponse `json:"users"`
-	Total      int64          `json:"total"`
 	Limit      int            `json:"limit"`
-	Offset     int            `json:"offset"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
 	HasMore    bool           `json:"has_more"`
+	Total      *int64         `json:"total,omitempty"`
 }
 
 // CountResponse represents user count response
@@ -46,182 +64,152 @@ type CountResponse struct {
 
 // convertUserToResponse converts domain entity to response format
 func convertUserToResponse(user *entity.User) UserResponse {
-	return UserResponse{
-		ID:        string(user.ID),
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Address:   user.Address,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	response := UserResponse{
+		ID:            string(user.ID),
+		TenantID:      string(user.TenantID),
+		Username:      user.Username.String(),
+		Email:         user.Email.String(),
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Phone:         user.Phone.String(),
+		Address:       user.Address,
+		Role:          string(user.Role),
+		Status:        string(user.EffectiveStatus(time.Now())),
+		EmailVerified: user.IsEmailVerified(),
+		Version:       user.Version,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
+	if user.LastLoginAt != nil {
+		response.LastLoginAt = user.LastLoginAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if response.Status != string(entity.StatusActive) {
+		response.StatusReason = user.StatusReason
+		if user.StatusUntil != nil {
+			response.StatusUntil = user.StatusUntil.Format("2006-01-02T15:04:05Z07:00")
+		}
+	}
+	return response
 }
 
 // ListUsersHandler handles GET /api/users
-func ListUsersHandler(w http.ResponseWriter, r *http.Request, userRepo repository.UserRepository) {
+// Query parameters:
+//   - limit, cursor (from a previous next_cursor/prev_cursor)
+//   - sort: created_at (default), username, email or last_login
+//   - order: asc (default) or desc
+//   - created_after, created_before: RFC 3339 timestamp or YYYY-MM-DD date
+//   - email_domain, phone (exact match), verified=true|false, role, status
+//   - include_total=true to also count the matching users
+//
+// A cursor keeps the sort it was issued with; filters must be repeated on
+// every page request. Cursors issued by search are refused with a 400.
+func ListUsersHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase, cursors *CursorCodec) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Parse query parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10 // default
-	offset := 0 // default
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	limit, cursor, ok := parsePageParams(w, r, cursors)
+	if !ok {
+		return
 	}
-
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	// Relevance cursors come from search and cannot continue a listing
+	if cursor != nil && cursor.Sort.Field == repository.SortByRelevance {
+		writeBadRequest(w, ErrInvalidCursor)
+		return
 	}
-
-	// Create use case
-	uc := userUseCase.NewUserUseCase(userRepo)
-
-	// Get users
-	users, err := uc.ListUsers(r.Context(), limit, offset)
+	filter, err := ParseUserFilter(r.URL.Query())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeBadRequest(w, err)
 		return
 	}
-
-	// Get total count
-	total, err := uc.GetUserCount(r.Context())
+	sort, err := parseUserSort(r.URL.Query())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get user count"})
+		writeBadRequest(w, err)
 		return
 	}
 
-	// Convert to response format
-	userResponses := make([]UserResponse, len(users))
-	for i, user := range users {
-		userResponses[i] = convertUserToResponse(user)
-	}
-
-	response := PaginatedUsersResponse{
-		Users:   userResponses,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
-		HasMore: int64(offset+len(users)) < total,
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
-
-// GetAllUsersHandler handles GET /api/users/all
-func GetAllUsersHandler(w http.ResponseWriter, r *http.Request, userRepo repository.UserRepository) {
-	w.Header().Set("Content-Type", "application/json")
-
-	// Parse limit parameter
-	limitStr := r.URL.Query().Get("limit")
-	limit := 50 // default
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
-	}
-
-	// Create use case
-	uc := userUseCase.NewUserUseCase(userRepo)
-
-	// Get all users
-	users, err := uc.GetAllUsers(r.Context(), limit)
+	// Get users
+	page, err := uc.ListUsersPage(r.Context(), repository.UserPageQuery{
+		Limit:  limit,
+		Cursor: cursor,
+		Filter: filter,
+		Sort:   sort,
+	})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
-	// Convert to response format
-	userResponses := make([]UserResponse, len(users))
-	for i, user := range users {
-		userResponses[i] = convertUserToResponse(user)
+	response := newPaginatedUsersResponse(page, cursors)
+
+	// The total is comparatively expensive, so only count when asked to
+	if r.URL.Query().Get("include_total") == "true" {
+		total, err := uc.CountUsers(r.Context(), filter)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get user count"})
+			return
+		}
+		response.Total = &total
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"users": userResponses,
-		"count": len(userResponses),
-		"limit": limit,
-	})
+	json.NewEncoder(w).Encode(response)
 }
 
 // SearchUsersHandler handles GET /api/users/search
-func SearchUsersHandler(w http.ResponseWriter, r *http.Request, userRepo repository.UserRepository) {
+// Query parameters: q (required), limit and cursor. Results are ranked by
+// relevance: username or email prefix matches first, then by trigram
+// similarity. The response always includes the total number of matches.
+func SearchUsersHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase, cursors *CursorCodec) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Parse query parameters
 	query := r.URL.Query().Get("q")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
 	if query == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Search query 'q' parameter is required"})
 		return
 	}
 
-	limit := 10 // default
-	offset := 0 // default
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
-	}
-
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	limit, cursor, ok := parsePageParams(w, r, cursors)
+	if !ok {
+		return
 	}
 
-	// Create use case
-	uc := userUseCase.NewUserUseCase(userRepo)
-
 	// Search users
-	users, err := uc.SearchUsers(r.Context(), query, limit, offset)
+	filter := repository.UserFilter{Search: query}
+	page, err := uc.SearchUsersPage(r.Context(), repository.UserPageQuery{
+		Limit:  limit,
+		Cursor: cursor,
+		Filter: filter,
+	})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
-	// Convert to response format
-	userResponses := make([]UserResponse, len(users))
-	for i, user := range users {
-		userResponses[i] = convertUserToResponse(user)
+	total, err := uc.CountUsers(r.Context(), filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to count matching users"})
+		return
 	}
 
+	response := newPaginatedUsersResponse(page, cursors)
+	response.Total = &total
+
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"users":  userResponses,
-		"count":  len(userResponses),
-		"query":  query,
-		"limit":  limit,
-		"offset": offset,
-	})
+	json.NewEncoder(w).Encode(struct {
+		PaginatedUsersResponse
+		Query string `json:"query"`
+	}{response, query})
 }
 
 // GetUserCountHandler handles GET /api/users/count
-func GetUserCountHandler(w http.ResponseWriter, r *http.Request, userRepo repository.UserRepository) {
+func GetUserCountHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Create use case
-	uc := userUseCase.NewUserUseCase(userRepo)
-
 	// Get user count
 	count, err := uc.GetUserCount(r.Context())
 	if err != nil {
@@ -236,7 +224,9 @@ func GetUserCountHandler(w http.ResponseWriter, r *http.Request, userRepo reposi
 }
 
 // GetUserByIDHandler handles GET /api/users/{id}
-func GetUserByIDHandler(w http.ResponseWriter, r *http.Request, userRepo repository.UserRepository) {
+// The ID must be a UUID; anything else, including a legacy integer ID, is
+// rejected with 400.
+func GetUserByIDHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract user ID from URL path
@@ -247,11 +237,12 @@ func GetUserByIDHandler(w http.ResponseWriter, r *http.Request, userRepo reposit
 		return
 	}
 
-	// Convert string ID to UserID
-	userID := entity.UserID(path)
-
-	// Create use case
-	uc := userUseCase.NewUserUseCase(userRepo)
+	// Validate the ID so malformed input is a client error, not a query failure
+	userID, err := entity.ParseUserID(path)
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
 
 	// Get user
 	user, err := uc.GetUserByID(r.Context(), userID)
@@ -268,6 +259,241 @@ func GetUserByIDHandler(w http.ResponseWriter, r *http.Request, userRepo reposit
 	}
 
 	response := convertUserToResponse(user)
+	w.Header().Set("ETag", userETag(user))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// UpdateProfileRequest is the body of PATCH /api/users/{id}. Omitted
+// fields are left unchanged.
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+	Phone     *string `json:"phone"`
+	Address   *string `json:"address"`
+}
+
+// UpdateUserProfileHandler handles PATCH /api/users/{id}
+// Users may update their own profile and admins anyone's. Send the ETag
+// from GET /api/users/{id} as If-Match to avoid overwriting someone else's
+// change: a stale ETag gets 412 Precondition Failed, and so does a
+// concurrent write that lands between the read and the update. The response
+// carries the new ETag.
+func UpdateUserProfileHandler(w http.ResponseWriter, r *http.Request, uc *userUseCase.UserUseCase) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := entity.ParseUserID(strings.TrimPrefix(r.URL.Path, "/api/users/"))
+	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
+
+	claims := ClaimsFromContext(r.Context())
+	if claims == nil || (claims.UserID != userID && claims.Role != entity.RoleAdmin) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "You may only update your own profile"})
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok {
+		writePreconditionFailed(w)
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, errors.New("Invalid request body"))
+		return
+	}
+
+	user, err := uc.UpdateUserProfile(r.Context(), userID, expectedVersion, userUseCase.ProfileChanges{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
+		Address:   req.Address,
+	})
+
+	var verr *entity.ValidationError
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		writePreconditionFailed(w)
+		return
+	case errors.Is(err, userUseCase.ErrUserNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
+		return
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update profile"})
+		return
+	}
+
+	w.Header().Set("ETag", userETag(user))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(convertUserToResponse(user))
+}
+
+// userETag is the strong entity tag of a user representation: its version.
+func userETag(user *entity.User) string {
+	return `"` + strconv.FormatInt(user.Version, 10) + `"`
+}
+
+// parseIfMatch returns the version demanded by an If-Match header: 0 when
+// the header is absent or "*", and ok=false when it cannot match any
+// version (weak or malformed tags, which If-Match never matches).
+func parseIfMatch(header string) (version int64, ok bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	tag, found := strings.CutPrefix(header, `"`)
+	if !found {
+		return 0, false
+	}
+	tag, found = strings.CutSuffix(tag, `"`)
+	if !found {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+func writePreconditionFailed(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": "The user was modified since it was read; fetch it again and retry",
+	})
+}
+
+// parsePageParams reads the limit and cursor query parameters. On a bad
+// cursor it writes a 400 response and returns ok=false.
+func parsePageParams(w http.ResponseWriter, r *http.Request, cursors *CursorCodec) (limit int, cursor *repository.UserCursor, ok bool) {
+	limit = 10 // default
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	cursor, err := cursors.Decode(r.URL.Query().Get("cursor"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return 0, nil, false
+	}
+	return limit, cursor, true
+}
+
+// ParseUserFilter reads the listing filters from query parameters. It is
+// shared by the listing and export endpoints and the export CLI.
+func ParseUserFilter(values url.Values) (repository.UserFilter, error) {
+	var filter repository.UserFilter
+
+	if v := values.Get("created_after"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_after: %w", err)
+		}
+		filter.CreatedFrom = &t
+	}
+	if v := values.Get("created_before"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_before: %w", err)
+		}
+		filter.CreatedTo = &t
+	}
+	if v := values.Get("email_domain"); v != "" {
+		domain, err := entity.NormalizeEmailDomain(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid email_domain: %w", err)
+		}
+		filter.EmailDomain = domain
+	}
+	if v := values.Get("phone"); v != "" {
+		phone, err := entity.NewPhoneNumber(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid phone: %w", err)
+		}
+		filter.Phone = phone
+	}
+	if v := values.Get("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("verified must be true or false")
+		}
+		filter.Verified = &verified
+	}
+	if v := values.Get("role"); v != "" {
+		role, err := entity.ParseRole(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Role = &role
+	}
+	if v := values.Get("status"); v != "" {
+		status, err := entity.ParseAccountStatus(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Status = &status
+	}
+	return filter, nil
+}
+
+// parseUserSort reads the sort and order query parameters.
+func parseUserSort(values url.Values) (repository.UserSort, error) {
+	sort := repository.DefaultUserSort
+	if v := values.Get("sort"); v != "" {
+		sort.Field = repository.UserSortField(v)
+		// Relevance needs a search text and is only offered by search
+		if !sort.Field.Valid() || sort.Field == repository.SortByRelevance {
+			return sort, fmt.Errorf("unsupported sort field %q", v)
+		}
+	}
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		sort.Desc = true
+	default:
+		return sort, errors.New("order must be asc or desc")
+	}
+	return sort, nil
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a YYYY-MM-DD date (UTC
+// midnight).
+func parseTimeParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+// writeBadRequest writes a 400 response carrying err's message.
+func writeBadRequest(w http.ResponseWriter, err error) {
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// newPaginatedUsersResponse converts a page to the response format. The
+// limit reported is the one applied, which the use case may have capped.
+func newPaginatedUsersResponse(page *repository.UserPage, cursors *CursorCodec) PaginatedUsersResponse {
+	userResponses := make([]UserResponse, len(page.Users))
+	for i, user := range page.Users {
+		userResponses[i] = convertUserToResponse(user)
+	}
+
+	return PaginatedUsersResponse{
+		Users:      userResponses,
+		Limit:      page.Limit,
+		NextCursor: cursors.Encode(page.NextCursor()),
+		PrevCursor: cursors.Encode(page.PrevCursor()),
+		HasMore:    page.HasNext,
+	}
+}
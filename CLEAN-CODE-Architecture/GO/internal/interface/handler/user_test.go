@@ -0,0 +1,39 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"auth-module/internal/interface/handler"
+	"auth-module/internal/interface/repository/repotest"
+	userUseCase "auth-module/internal/usecase/user"
+)
+
+// TestListUsersReportsAppliedLimit asks for more users per page than the
+// use case allows; the response must report the capped limit.
+func TestListUsersReportsAppliedLimit(t *testing.T) {
+	b := repotest.Memory()
+	ctx := b.NewTenant(t, "listing")
+	b.CreateUser(t, ctx, "listed", "listed@example.com")
+	uc := userUseCase.NewUserUseCase(b.Users, b.TxManager)
+	cursors := handler.NewCursorCodec([]byte("test-cursor-secret"))
+
+	for query, want := range map[string]int{"": 10, "?limit=25": 25, "?limit=5000": 100} {
+		req := httptest.NewRequest(http.MethodGet, "/api/users"+query, nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		handler.ListUsersHandler(rec, req, uc, cursors)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /api/users%s: status %d: %s", query, rec.Code, rec.Body)
+		}
+
+		var resp handler.PaginatedUsersResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Limit != want {
+			t.Errorf("GET /api/users%s reported limit %d, want %d", query, resp.Limit, want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// GroupRepo implements repository.GroupRepository on a Store. Memberships
+// belong to the tenant of their group, and deleting a user through UserRepo
+// removes them, like the foreign keys in Postgres.
+type GroupRepo struct {
+	store *Store
+}
+
+// membershipKey identifies a membership in Store.members.
+type membershipKey struct {
+	group entity.GroupID
+	user  entity.UserID
+}
+
+func (r *GroupRepo) Create(ctx context.Context, group *entity.Group) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	for _, other := range r.store.groups {
+		if other.TenantID == tenant && other.Name == group.Name {
+			return &repository.ConflictError{Field: "name"}
+		}
+	}
+	group.TenantID = tenant
+	stored := *group
+	r.store.groups[stored.ID] = &stored
+	return nil
+}
+
+func (r *GroupRepo) GetByID(ctx context.Context, id entity.GroupID) (*entity.Group, error) {
+	parsedID, err := entity.ParseGroupID(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+	return cloneGroup(r.get(tenant, parsedID)), nil
+}
+
+func (r *GroupRepo) AddMember(ctx context.Context, membership *entity.Membership) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[membership.UserID]
+	if r.get(tenant, membership.GroupID) == nil || !ok || user.TenantID != tenant {
+		return repository.ErrNotFound
+	}
+	key := membershipKey{group: membership.GroupID, user: membership.UserID}
+	stored := *membership
+	if current, ok := r.store.members[key]; ok {
+		stored.JoinedAt = current.JoinedAt
+	}
+	r.store.members[key] = &stored
+	return nil
+}
+
+func (r *GroupRepo) GetMembership(ctx context.Context, group entity.GroupID, user entity.UserID) (*entity.Membership, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	if r.get(tenant, group) == nil {
+		return nil, nil
+	}
+	return cloneMembership(r.store.members[membershipKey{group: group, user: user}]), nil
+}
+
+func (r *GroupRepo) RemoveMember(ctx context.Context, group entity.GroupID, user entity.UserID) (bool, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer r.store.lock(ctx)()
+
+	key := membershipKey{group: group, user: user}
+	if _, ok := r.store.members[key]; !ok || r.get(tenant, group) == nil {
+		return false, nil
+	}
+	delete(r.store.members, key)
+	return true, nil
+}
+
+func (r *GroupRepo) ListMembers(ctx context.Context, query repository.MemberPageQuery) (*repository.MemberPage, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	page := &repository.MemberPage{}
+	if r.get(tenant, query.GroupID) == nil {
+		return page, nil
+	}
+	var members []*entity.Membership
+	for key, m := range r.store.members {
+		if key.group == query.GroupID && (query.After == nil || compareMember(m, query.After.JoinedAt, query.After.UserID) > 0) {
+			members = append(members, m)
+		}
+	}
+	slices.SortFunc(members, func(a, b *entity.Membership) int {
+		return compareMember(a, b.JoinedAt, b.UserID)
+	})
+
+	if len(members) > query.Limit {
+		members = members[:query.Limit]
+		last := members[len(members)-1]
+		page.Next = &repository.MemberCursor{JoinedAt: last.JoinedAt, UserID: last.UserID}
+	}
+	page.Members = make([]*entity.Membership, len(members))
+	for i, m := range members {
+		page.Members[i] = cloneMembership(m)
+	}
+	return page, nil
+}
+
+func (r *GroupRepo) ListUserGroups(ctx context.Context, user entity.UserID) ([]repository.UserGroup, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	var groups []repository.UserGroup
+	for key, m := range r.store.members {
+		if group := r.get(tenant, key.group); group != nil && key.user == user {
+			groups = append(groups, repository.UserGroup{Group: cloneGroup(group), Role: m.Role})
+		}
+	}
+	slices.SortFunc(groups, func(a, b repository.UserGroup) int {
+		return strings.Compare(a.Group.Name.String(), b.Group.Name.String())
+	})
+	return groups, nil
+}
+
+// get returns the stored group with id if it belongs to tenant, or nil. The
+// caller holds the lock.
+func (r *GroupRepo) get(tenant entity.TenantID, id entity.GroupID) *entity.Group {
+	if g, ok := r.store.groups[id]; ok && g.TenantID == tenant {
+		return g
+	}
+	return nil
+}
+
+// compareMember orders memberships by (JoinedAt, UserID).
+func compareMember(m *entity.Membership, joinedAt time.Time, user entity.UserID) int {
+	if c := m.JoinedAt.Compare(joinedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(string(m.UserID), string(user))
+}
+
+func cloneGroup(g *entity.Group) *entity.Group {
+	if g == nil {
+		return nil
+	}
+	c := *g
+	return &c
+}
+
+func cloneMembership(m *entity.Membership) *entity.Membership {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	return &c
+}
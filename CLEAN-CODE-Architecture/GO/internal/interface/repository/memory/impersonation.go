@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// ImpersonationRepo implements repository.ImpersonationRepository on a
+// Store.
+type ImpersonationRepo struct {
+	store *Store
+}
+
+func (r *ImpersonationRepo) Create(ctx context.Context, impersonation *entity.Impersonation) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	impersonation.TenantID = tenant
+	stored := cloneImpersonation(impersonation)
+	r.store.impersonations[stored.ID] = stored
+	return nil
+}
+
+func (r *ImpersonationRepo) GetByID(ctx context.Context, id entity.ImpersonationID) (*entity.Impersonation, error) {
+	parsedID, err := entity.ParseImpersonationID(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	if i, ok := r.store.impersonations[parsedID]; ok && i.TenantID == tenant {
+		return cloneImpersonation(i), nil
+	}
+	return nil, nil
+}
+
+func (r *ImpersonationRepo) Update(ctx context.Context, impersonation *entity.Impersonation) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	if current, ok := r.store.impersonations[impersonation.ID]; ok && current.TenantID == tenant {
+		stored := cloneImpersonation(current)
+		stored.EndedAt = cloneTime(impersonation.EndedAt)
+		stored.EndedBy = impersonation.EndedBy
+		r.store.impersonations[stored.ID] = stored
+	}
+	return nil
+}
+
+func (r *ImpersonationRepo) List(ctx context.Context, filter repository.ImpersonationFilter) ([]*entity.Impersonation, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	var impersonations []*entity.Impersonation
+	for _, i := range r.store.impersonations {
+		switch {
+		case i.TenantID != tenant:
+		case filter.ActorID != nil && i.ActorID != *filter.ActorID:
+		case filter.TargetID != nil && i.TargetID != *filter.TargetID:
+		default:
+			impersonations = append(impersonations, cloneImpersonation(i))
+		}
+	}
+	slices.SortFunc(impersonations, func(a, b *entity.Impersonation) int {
+		if c := b.StartedAt.Compare(a.StartedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(b.ID), string(a.ID))
+	})
+	return impersonations, nil
+}
+
+func cloneImpersonation(i *entity.Impersonation) *entity.Impersonation {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	c.EndedAt = cloneTime(i.EndedAt)
+	return &c
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// InvitationRepo implements repository.InvitationRepository on a Store.
+type InvitationRepo struct {
+	store *Store
+}
+
+func (r *InvitationRepo) Create(ctx context.Context, invitation *entity.Invitation) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	if invitation.Status == entity.InvitationPending && r.pending(tenant, invitation.Email) != nil {
+		return &repository.ConflictError{Field: "invitation"}
+	}
+	invitation.TenantID = tenant
+	stored := cloneInvitation(invitation)
+	r.store.invitations[stored.ID] = stored
+	return nil
+}
+
+func (r *InvitationRepo) GetByID(ctx context.Context, id entity.InvitationID) (*entity.Invitation, error) {
+	parsedID, err := entity.ParseInvitationID(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	if i, ok := r.store.invitations[parsedID]; ok && i.TenantID == tenant {
+		return cloneInvitation(i), nil
+	}
+	return nil, nil
+}
+
+func (r *InvitationRepo) GetPending(ctx context.Context, email entity.Email) (*entity.Invitation, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+	return cloneInvitation(r.pending(tenant, email)), nil
+}
+
+func (r *InvitationRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	defer r.store.lock(ctx)()
+
+	for _, i := range r.store.invitations {
+		if i.TokenHash == tokenHash {
+			return cloneInvitation(i), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *InvitationRepo) Update(ctx context.Context, invitation *entity.Invitation) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	if current, ok := r.store.invitations[invitation.ID]; ok && current.TenantID == tenant {
+		stored := cloneInvitation(invitation)
+		stored.TenantID = tenant
+		r.store.invitations[stored.ID] = stored
+	}
+	return nil
+}
+
+func (r *InvitationRepo) List(ctx context.Context) ([]*entity.Invitation, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	var invitations []*entity.Invitation
+	for _, i := range r.store.invitations {
+		if i.TenantID == tenant {
+			invitations = append(invitations, cloneInvitation(i))
+		}
+	}
+	slices.SortFunc(invitations, func(a, b *entity.Invitation) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(b.ID), string(a.ID))
+	})
+	return invitations, nil
+}
+
+// pending returns the stored pending invitation of tenant for email, or
+// nil. The caller holds the lock.
+func (r *InvitationRepo) pending(tenant entity.TenantID, email entity.Email) *entity.Invitation {
+	for _, i := range r.store.invitations {
+		if i.TenantID == tenant && i.Status == entity.InvitationPending && strings.EqualFold(i.Email.String(), email.String()) {
+			return i
+		}
+	}
+	return nil
+}
+
+func cloneInvitation(i *entity.Invitation) *entity.Invitation {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
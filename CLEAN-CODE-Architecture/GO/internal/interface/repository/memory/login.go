@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// LoginAttemptRepo implements repository.LoginAttemptRepository on a Store.
+type LoginAttemptRepo struct {
+	store *Store
+}
+
+func (r *LoginAttemptRepo) Create(ctx context.Context, attempt *entity.LoginAttempt) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	attempt.TenantID = tenant
+	stored := cloneLoginAttempt(attempt)
+	r.store.loginAttempts[stored.ID] = stored
+	return nil
+}
+
+func (r *LoginAttemptRepo) List(ctx context.Context, query repository.LoginAttemptQuery) ([]*entity.LoginAttempt, error) {
+	if query.Before != "" {
+		if _, err := entity.ParseLoginAttemptID(string(query.Before)); err != nil {
+			return nil, err
+		}
+	}
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	var attempts []*entity.LoginAttempt
+	for _, a := range r.store.loginAttempts {
+		switch {
+		case a.TenantID != tenant || a.UserID == nil || *a.UserID != query.UserID:
+		case query.Before != "" && a.ID >= query.Before:
+		default:
+			attempts = append(attempts, a)
+		}
+	}
+	slices.SortFunc(attempts, func(a, b *entity.LoginAttempt) int {
+		return strings.Compare(string(b.ID), string(a.ID))
+	})
+	if query.Limit > 0 && len(attempts) > query.Limit {
+		attempts = attempts[:query.Limit]
+	}
+	for i, a := range attempts {
+		attempts[i] = cloneLoginAttempt(a)
+	}
+	return attempts, nil
+}
+
+func (r *LoginAttemptRepo) Familiarity(ctx context.Context, user entity.UserID, fingerprint, ipRange string) (repository.LoginFamiliarity, error) {
+	var familiarity repository.LoginFamiliarity
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return familiarity, err
+	}
+	defer r.store.lock(ctx)()
+
+	for _, a := range r.store.loginAttempts {
+		if a.TenantID != tenant || a.UserID == nil || *a.UserID != user || !a.Success {
+			continue
+		}
+		familiarity.PreviousLogins = true
+		familiarity.KnownDevice = familiarity.KnownDevice || a.Fingerprint == fingerprint
+		familiarity.KnownIPRange = familiarity.KnownIPRange || a.IPRange == ipRange
+	}
+	return familiarity, nil
+}
+
+func cloneLoginAttempt(a *entity.LoginAttempt) *entity.LoginAttempt {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	c.RiskFlags = slices.Clone(a.RiskFlags)
+	if a.UserID != nil {
+		id := *a.UserID
+		c.UserID = &id
+	}
+	if a.SessionID != nil {
+		id := *a.SessionID
+		c.SessionID = &id
+	}
+	return &c
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// MagicLinkRepo implements repository.MagicLinkRepository on a Store.
+type MagicLinkRepo struct {
+	store *Store
+}
+
+// CreateWithinLimit counts and stores under one hold of the store lock.
+func (r *MagicLinkRepo) CreateWithinLimit(ctx context.Context, link *entity.MagicLink, since time.Time, limit int) (bool, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer r.store.lock(ctx)()
+
+	count := 0
+	for _, existing := range r.store.magicLinks {
+		if existing.TokenHash == link.TokenHash {
+			return false, &repository.ConflictError{Field: "token"}
+		}
+		if existing.TenantID == tenant && existing.UserID == link.UserID && !existing.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	if count >= limit {
+		return false, nil
+	}
+	link.TenantID = tenant
+	r.store.magicLinks[link.ID] = cloneMagicLink(link)
+	return true, nil
+}
+
+func (r *MagicLinkRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MagicLink, error) {
+	defer r.store.lock(ctx)()
+
+	for _, l := range r.store.magicLinks {
+		if l.TokenHash == tokenHash {
+			return cloneMagicLink(l), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MagicLinkRepo) Use(ctx context.Context, id entity.MagicLinkID, now time.Time) (bool, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer r.store.lock(ctx)()
+
+	current, ok := r.store.magicLinks[id]
+	if !ok || current.TenantID != tenant || current.UsedAt != nil {
+		return false, nil
+	}
+	stored := cloneMagicLink(current)
+	stored.UsedAt = &now
+	r.store.magicLinks[id] = stored
+	return true, nil
+}
+
+func cloneMagicLink(l *entity.MagicLink) *entity.MagicLink {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	c.UsedAt = cloneTime(l.UsedAt)
+	return &c
+}
@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// SessionRepo implements repository.SessionRepository on a Store.
+type SessionRepo struct {
+	store *Store
+}
+
+func (r *SessionRepo) Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.refreshTokens[token.TokenHash]; ok {
+		return &repository.ConflictError{Field: "token"}
+	}
+	session.TenantID, token.TenantID = tenant, tenant
+	r.store.sessions[session.ID] = cloneSession(session)
+	r.store.refreshTokens[token.TokenHash] = cloneRefreshToken(token)
+	return nil
+}
+
+func (r *SessionRepo) GetByID(ctx context.Context, id entity.SessionID) (*entity.Session, error) {
+	parsedID, err := entity.ParseSessionID(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	if s, ok := r.store.sessions[parsedID]; ok && s.TenantID == tenant {
+		return cloneSession(s), nil
+	}
+	return nil, nil
+}
+
+func (r *SessionRepo) ListByUser(ctx context.Context, user entity.UserID, now time.Time) ([]*entity.Session, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	var sessions []*entity.Session
+	for _, s := range r.store.sessions {
+		if s.TenantID == tenant && s.UserID == user && s.RevokedAt == nil && now.Before(s.ExpiresAt) {
+			sessions = append(sessions, cloneSession(s))
+		}
+	}
+	slices.SortFunc(sessions, func(a, b *entity.Session) int {
+		if c := b.LastSeenAt.Compare(a.LastSeenAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(b.ID), string(a.ID))
+	})
+	return sessions, nil
+}
+
+func (r *SessionRepo) Update(ctx context.Context, session *entity.Session) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	if current, ok := r.store.sessions[session.ID]; ok && current.TenantID == tenant {
+		stored := cloneSession(current)
+		stored.RevokedAt = cloneTime(session.RevokedAt)
+		r.store.sessions[stored.ID] = stored
+	}
+	return nil
+}
+
+func (r *SessionRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	defer r.store.lock(ctx)()
+	return cloneRefreshToken(r.store.refreshTokens[tokenHash]), nil
+}
+
+func (r *SessionRepo) UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (bool, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer r.store.lock(ctx)()
+
+	current, ok := r.store.refreshTokens[tokenHash]
+	if !ok || current.TenantID != tenant || current.UsedAt != nil {
+		return false, nil
+	}
+	stored := cloneRefreshToken(current)
+	stored.UsedAt = &now
+	r.store.refreshTokens[tokenHash] = stored
+	return true, nil
+}
+
+func (r *SessionRepo) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.refreshTokens[token.TokenHash]; ok {
+		return &repository.ConflictError{Field: "token"}
+	}
+	token.TenantID = tenant
+	r.store.refreshTokens[token.TokenHash] = cloneRefreshToken(token)
+	return nil
+}
+
+func (r *SessionRepo) TouchLastSeen(ctx context.Context, seen map[entity.SessionID]time.Time) error {
+	defer r.store.lock(ctx)()
+
+	for id, at := range seen {
+		if current, ok := r.store.sessions[id]; ok && current.LastSeenAt.Before(at) {
+			stored := cloneSession(current)
+			stored.LastSeenAt = at
+			r.store.sessions[id] = stored
+		}
+	}
+	return nil
+}
+
+func cloneSession(s *entity.Session) *entity.Session {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.RevokedAt = cloneTime(s.RevokedAt)
+	c.RiskFlags = slices.Clone(s.RiskFlags)
+	return &c
+}
+
+func cloneRefreshToken(t *entity.RefreshToken) *entity.RefreshToken {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.UsedAt = cloneTime(t.UsedAt)
+	return &c
+}
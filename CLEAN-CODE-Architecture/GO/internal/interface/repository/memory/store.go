@@ -0,0 +1,174 @@
+// Package memory keeps repository data in process memory.
+//   - It implements the same domain ports as the Postgres adapters, so use
+//     cases can run against it in tests, demos and local tools without a
+//     database.
+//   - Data lives only as long as the Store; nothing is persisted.
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"auth-module/internal/domain/entity"
+)
+
+// txKey is the context key marking that the Store's lock is held by the
+// transaction running with that context.
+type txKey struct{}
+
+// Store holds the tables shared by the in-memory repositories and implements
+// repository.TxManager for them.
+//
+// Transactions are serializable: WithinTx holds the store lock until fn
+// returns, so other callers wait instead of seeing uncommitted changes.
+// Stored records are never modified in place, only replaced, which makes a
+// snapshot of the tables a cheap copy of their maps.
+type Store struct {
+	mu      sync.Mutex
+	users   map[entity.UserID]*entity.User
+	tenants map[entity.TenantID]*entity.Tenant
+	groups  map[entity.GroupID]*entity.Group
+	members map[membershipKey]*entity.Membership
+
+	invitations    map[entity.InvitationID]*entity.Invitation
+	impersonations map[entity.ImpersonationID]*entity.Impersonation
+	sessions       map[entity.SessionID]*entity.Session
+	refreshTokens  map[string]*entity.RefreshToken
+	loginAttempts  map[entity.LoginAttemptID]*entity.LoginAttempt
+	magicLinks     map[entity.MagicLinkID]*entity.MagicLink
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		users:   make(map[entity.UserID]*entity.User),
+		tenants: make(map[entity.TenantID]*entity.Tenant),
+		groups:  make(map[entity.GroupID]*entity.Group),
+		members: make(map[membershipKey]*entity.Membership),
+
+		invitations:    make(map[entity.InvitationID]*entity.Invitation),
+		impersonations: make(map[entity.ImpersonationID]*entity.Impersonation),
+		sessions:       make(map[entity.SessionID]*entity.Session),
+		refreshTokens:  make(map[string]*entity.RefreshToken),
+		loginAttempts:  make(map[entity.LoginAttemptID]*entity.LoginAttempt),
+		magicLinks:     make(map[entity.MagicLinkID]*entity.MagicLink),
+	}
+}
+
+// Users returns the user repository backed by s.
+func (s *Store) Users() *UserRepo {
+	return &UserRepo{store: s}
+}
+
+// Tenants returns the tenant repository backed by s.
+func (s *Store) Tenants() *TenantRepo {
+	return &TenantRepo{store: s}
+}
+
+// Groups returns the group repository backed by s.
+func (s *Store) Groups() *GroupRepo {
+	return &GroupRepo{store: s}
+}
+
+// Invitations returns the invitation repository backed by s.
+func (s *Store) Invitations() *InvitationRepo {
+	return &InvitationRepo{store: s}
+}
+
+// Impersonations returns the impersonation repository backed by s.
+func (s *Store) Impersonations() *ImpersonationRepo {
+	return &ImpersonationRepo{store: s}
+}
+
+// Sessions returns the session repository backed by s.
+func (s *Store) Sessions() *SessionRepo {
+	return &SessionRepo{store: s}
+}
+
+// LoginAttempts returns the login history repository backed by s.
+func (s *Store) LoginAttempts() *LoginAttemptRepo {
+	return &LoginAttemptRepo{store: s}
+}
+
+// MagicLinks returns the magic link repository backed by s.
+func (s *Store) MagicLinks() *MagicLinkRepo {
+	return &MagicLinkRepo{store: s}
+}
+
+// WithinTx runs fn with the store locked and restores the tables to their
+// state before the call if fn returns an error or panics. Nested calls reuse
+// the lock and restore only their own changes.
+func (s *Store) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if ctx.Value(txKey{}) != s {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		ctx = context.WithValue(ctx, txKey{}, s)
+	}
+
+	snapshot := s.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			s.restore(snapshot)
+			panic(p)
+		}
+	}()
+	if err = fn(ctx); err != nil {
+		s.restore(snapshot)
+	}
+	return err
+}
+
+// lock acquires the store lock unless the transaction in ctx already holds
+// it, and returns the matching unlock.
+func (s *Store) lock(ctx context.Context) (unlock func()) {
+	if ctx.Value(txKey{}) == s {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// tables is a point-in-time copy of the store's tables.
+type tables struct {
+	users   map[entity.UserID]*entity.User
+	tenants map[entity.TenantID]*entity.Tenant
+	groups  map[entity.GroupID]*entity.Group
+	members map[membershipKey]*entity.Membership
+
+	invitations    map[entity.InvitationID]*entity.Invitation
+	impersonations map[entity.ImpersonationID]*entity.Impersonation
+	sessions       map[entity.SessionID]*entity.Session
+	refreshTokens  map[string]*entity.RefreshToken
+	loginAttempts  map[entity.LoginAttemptID]*entity.LoginAttempt
+	magicLinks     map[entity.MagicLinkID]*entity.MagicLink
+}
+
+func (s *Store) snapshot() tables {
+	return tables{
+		users:   maps.Clone(s.users),
+		tenants: maps.Clone(s.tenants),
+		groups:  maps.Clone(s.groups),
+		members: maps.Clone(s.members),
+
+		invitations:    maps.Clone(s.invitations),
+		impersonations: maps.Clone(s.impersonations),
+		sessions:       maps.Clone(s.sessions),
+		refreshTokens:  maps.Clone(s.refreshTokens),
+		loginAttempts:  maps.Clone(s.loginAttempts),
+		magicLinks:     maps.Clone(s.magicLinks),
+	}
+}
+
+func (s *Store) restore(t tables) {
+	s.users = t.users
+	s.tenants = t.tenants
+	s.groups = t.groups
+	s.members = t.members
+	s.invitations = t.invitations
+	s.impersonations = t.impersonations
+	s.sessions = t.sessions
+	s.refreshTokens = t.refreshTokens
+	s.loginAttempts = t.loginAttempts
+	s.magicLinks = t.magicLinks
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// TenantRepo implements repository.TenantRepository on a Store.
+type TenantRepo struct {
+	store *Store
+}
+
+func (r *TenantRepo) Create(ctx context.Context, tenant *entity.Tenant) error {
+	defer r.store.lock(ctx)()
+
+	for _, other := range r.store.tenants {
+		if strings.EqualFold(other.Slug.String(), tenant.Slug.String()) {
+			return &repository.ConflictError{Field: "slug"}
+		}
+	}
+	stored := *tenant
+	r.store.tenants[stored.ID] = &stored
+	return nil
+}
+
+func (r *TenantRepo) GetByID(ctx context.Context, id entity.TenantID) (*entity.Tenant, error) {
+	parsedID, err := entity.ParseTenantID(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.store.lock(ctx)()
+	return cloneTenant(r.store.tenants[parsedID]), nil
+}
+
+func (r *TenantRepo) GetBySlug(ctx context.Context, slug entity.TenantSlug) (*entity.Tenant, error) {
+	defer r.store.lock(ctx)()
+
+	for _, tenant := range r.store.tenants {
+		if strings.EqualFold(tenant.Slug.String(), slug.String()) {
+			return cloneTenant(tenant), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *TenantRepo) List(ctx context.Context) ([]*entity.Tenant, error) {
+	defer r.store.lock(ctx)()
+
+	tenants := make([]*entity.Tenant, 0, len(r.store.tenants))
+	for _, tenant := range r.store.tenants {
+		tenants = append(tenants, cloneTenant(tenant))
+	}
+	slices.SortFunc(tenants, func(a, b *entity.Tenant) int {
+		return strings.Compare(a.Slug.String(), b.Slug.String())
+	})
+	return tenants, nil
+}
+
+// Update stores the tenant's name; the slug never changes.
+func (r *TenantRepo) Update(ctx context.Context, tenant *entity.Tenant) error {
+	defer r.store.lock(ctx)()
+
+	current, ok := r.store.tenants[tenant.ID]
+	if !ok {
+		return nil
+	}
+	stored := *current
+	stored.Name = tenant.Name
+	stored.UpdatedAt = tenant.UpdatedAt
+	r.store.tenants[stored.ID] = &stored
+	return nil
+}
+
+func cloneTenant(t *entity.Tenant) *entity.Tenant {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,529 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// UserRepo implements repository.UserRepository on a Store. It mirrors the
+// Postgres adapter: every method is scoped to the tenant in the context,
+// usernames and emails are unique per tenant regardless of case,
+// updates are conditional on the version, and listings use the same keyset
+// order. Search is a plain substring match standing in for trigram
+// similarity.
+type UserRepo struct {
+	store *Store
+}
+
+func (r *UserRepo) Create(ctx context.Context, user *entity.User) (*entity.User, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	stored := cloneUser(user)
+	stored.TenantID = tenant
+	if stored.ID == "" {
+		stored.ID = entity.NewUserID()
+	}
+	if stored.Version == 0 {
+		stored.Version = 1
+	}
+	now := time.Now()
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = now
+	}
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = now
+	}
+	if err := r.checkUnique(stored); err != nil {
+		return nil, err
+	}
+	r.store.users[stored.ID] = stored
+	return cloneUser(stored), nil
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id entity.UserID) (*entity.User, error) {
+	parsedID, err := entity.ParseUserID(string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+	return cloneUser(r.get(tenant, parsedID)), nil
+}
+
+func (r *UserRepo) GetByEmail(ctx context.Context, email entity.Email) (*entity.User, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+	return cloneUser(r.find(tenant, func(u *entity.User) bool {
+		return strings.EqualFold(u.Email.String(), email.String())
+	})), nil
+}
+
+func (r *UserRepo) GetByEmails(ctx context.Context, emails []entity.Email) ([]*entity.User, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+	return cloneUsers(r.filter(tenant, func(u *entity.User) bool {
+		return slices.ContainsFunc(emails, func(email entity.Email) bool {
+			return strings.EqualFold(u.Email.String(), email.String())
+		})
+	})), nil
+}
+
+func (r *UserRepo) GetByUsername(ctx context.Context, username entity.Username) (*entity.User, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+	return cloneUser(r.find(tenant, func(u *entity.User) bool {
+		return strings.EqualFold(u.Username.String(), username.String())
+	})), nil
+}
+
+// Update replaces the stored user if its version still equals user.Version.
+func (r *UserRepo) Update(ctx context.Context, user *entity.User) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+
+	current := r.get(tenant, user.ID)
+	if current == nil || current.Version != user.Version {
+		return &repository.VersionConflictError{ID: user.ID, Version: user.Version}
+	}
+
+	stored := cloneUser(user)
+	stored.TenantID = current.TenantID
+	if err := r.checkUnique(stored); err != nil {
+		return err
+	}
+	stored.CreatedAt = current.CreatedAt
+	stored.Version = current.Version + 1
+	stored.UpdatedAt = time.Now()
+	r.store.users[stored.ID] = stored
+
+	user.Version = stored.Version
+	user.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *UserRepo) Delete(ctx context.Context, id entity.UserID) error {
+	parsedID, err := entity.ParseUserID(string(id))
+	if err != nil {
+		return err
+	}
+
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.store.lock(ctx)()
+	if r.get(tenant, parsedID) != nil {
+		delete(r.store.users, parsedID)
+		maps.DeleteFunc(r.store.members, func(key membershipKey, _ *entity.Membership) bool {
+			return key.user == parsedID
+		})
+		maps.DeleteFunc(r.store.sessions, func(_ entity.SessionID, s *entity.Session) bool {
+			return s.UserID == parsedID
+		})
+		maps.DeleteFunc(r.store.refreshTokens, func(_ string, t *entity.RefreshToken) bool {
+			_, ok := r.store.sessions[t.SessionID]
+			return !ok
+		})
+		maps.DeleteFunc(r.store.loginAttempts, func(_ entity.LoginAttemptID, a *entity.LoginAttempt) bool {
+			return a.UserID != nil && *a.UserID == parsedID
+		})
+		maps.DeleteFunc(r.store.magicLinks, func(_ entity.MagicLinkID, l *entity.MagicLink) bool {
+			return l.UserID == parsedID
+		})
+	}
+	return nil
+}
+
+func (r *UserRepo) Count(ctx context.Context) (int64, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer r.store.lock(ctx)()
+	return int64(len(r.filter(tenant, func(*entity.User) bool { return true }))), nil
+}
+
+func (r *UserRepo) GetByEmailOrUsername(ctx context.Context, emailOrUsername string) (*entity.User, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+	return cloneUser(r.find(tenant, func(u *entity.User) bool {
+		return strings.EqualFold(u.Email.String(), emailOrUsername) ||
+			strings.EqualFold(u.Username.String(), emailOrUsername)
+	})), nil
+}
+
+// GetByLegacyID always finds nothing: accounts in memory never had integer IDs.
+func (r *UserRepo) GetByLegacyID(ctx context.Context, legacyID int64) (*entity.User, error) {
+	if _, err := repository.RequireTenant(ctx); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// ListPage follows PostgresUserRepo.ListPage: rows are ordered by (sort
+// key, id), the cursor excludes itself, and one extra row tells whether
+// another page exists in the reading direction.
+func (r *UserRepo) ListPage(ctx context.Context, query repository.UserPageQuery) (*repository.UserPage, error) {
+	sort := query.Sort
+	if query.Cursor != nil {
+		sort = query.Cursor.Sort
+	}
+	if sort.Field == "" {
+		sort = repository.DefaultUserSort
+	}
+	if !sort.Field.Valid() {
+		return nil, fmt.Errorf("unsupported sort field %q", sort.Field)
+	}
+	if sort.Field == repository.SortByRelevance && query.Filter.Search == "" {
+		return nil, errors.New("relevance sort requires a search")
+	}
+	term := searchTerm(query.Filter.Search)
+
+	descending := sort.Desc
+	backwards := query.Cursor != nil && query.Cursor.Direction == repository.CursorPrev
+	if backwards {
+		descending = !descending
+	}
+
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bound keyed
+	if query.Cursor != nil {
+		id, err := entity.ParseUserID(string(query.Cursor.ID))
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseSortKey(sort.Field, query.Cursor.Key)
+		if err != nil {
+			return nil, err
+		}
+		bound = keyed{key: key, id: id}
+	}
+
+	defer r.store.lock(ctx)()
+
+	var rows []keyed
+	for _, u := range r.store.users {
+		if u.TenantID != tenant || !matchesFilter(u, query.Filter, term) {
+			continue
+		}
+		row := keyed{user: u, key: sortValue(u, sort.Field, term), id: u.ID}
+		if query.Cursor != nil {
+			c := compareKeyed(row, bound)
+			if descending {
+				c = -c
+			}
+			if c <= 0 {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b keyed) int {
+		if descending {
+			return compareKeyed(b, a)
+		}
+		return compareKeyed(a, b)
+	})
+
+	more := len(rows) > query.Limit
+	if more {
+		rows = rows[:query.Limit]
+	}
+	if backwards {
+		slices.Reverse(rows)
+	}
+
+	page := &repository.UserPage{Users: make([]*entity.User, len(rows)), Sort: sort, Limit: query.Limit}
+	if sort.Field == repository.SortByRelevance {
+		page.Keys = make([]string, len(rows))
+	}
+	for i, row := range rows {
+		page.Users[i] = cloneUser(row.user)
+		if page.Keys != nil {
+			page.Keys[i] = strconv.FormatFloat(row.key.(float64), 'g', -1, 64)
+		}
+	}
+	if backwards {
+		page.HasPrev, page.HasNext = more, true
+	} else {
+		page.HasNext, page.HasPrev = more, query.Cursor != nil
+	}
+	return page, nil
+}
+
+func (r *UserRepo) CountMatching(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer r.store.lock(ctx)()
+
+	term := searchTerm(filter.Search)
+	return int64(len(r.filter(tenant, func(u *entity.User) bool { return matchesFilter(u, filter, term) }))), nil
+}
+
+// UpsertBatch inserts each user or, when its email is already registered,
+// updates that account's profile like PostgresUserRepo.UpsertBatch does. The whole
+// batch runs under one lock, so readers never see half of it.
+func (r *UserRepo) UpsertBatch(ctx context.Context, users []*entity.User) ([]error, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.store.lock(ctx)()
+
+	errs := make([]error, len(users))
+	for i, user := range users {
+		existing := r.find(tenant, func(u *entity.User) bool {
+			return strings.EqualFold(u.Email.String(), user.Email.String())
+		})
+		if existing == nil {
+			stored := cloneUser(user)
+			stored.TenantID = tenant
+			if err := r.checkUnique(stored); err != nil {
+				errs[i] = err
+				continue
+			}
+			r.store.users[stored.ID] = stored
+			continue
+		}
+
+		stored := cloneUser(existing)
+		stored.Username = user.Username
+		stored.FirstName = user.FirstName
+		stored.LastName = user.LastName
+		stored.Phone = user.Phone
+		stored.Address = user.Address
+		stored.UpdatedAt = user.UpdatedAt
+		stored.Version++
+		if err := r.checkUnique(stored); err != nil {
+			errs[i] = err
+			continue
+		}
+		r.store.users[stored.ID] = stored
+		user.ID = stored.ID
+		user.TenantID = tenant
+		user.Version = stored.Version
+	}
+	return errs, nil
+}
+
+// checkUnique reports a *repository.ConflictError if another user of u's
+// tenant already has its email or username. Like the citext indexes, case
+// is ignored.
+func (r *UserRepo) checkUnique(u *entity.User) error {
+	for id, other := range r.store.users {
+		if id == u.ID || other.TenantID != u.TenantID {
+			continue
+		}
+		if strings.EqualFold(other.Email.String(), u.Email.String()) {
+			return &repository.ConflictError{Field: "email"}
+		}
+		if strings.EqualFold(other.Username.String(), u.Username.String()) {
+			return &repository.ConflictError{Field: "username"}
+		}
+	}
+	return nil
+}
+
+// get returns the stored user with id if it belongs to tenant, or nil.
+func (r *UserRepo) get(tenant entity.TenantID, id entity.UserID) *entity.User {
+	if u, ok := r.store.users[id]; ok && u.TenantID == tenant {
+		return u
+	}
+	return nil
+}
+
+// find returns the stored user of tenant matching match, or nil. The caller holds the lock.
+func (r *UserRepo) find(tenant entity.TenantID, match func(*entity.User) bool) *entity.User {
+	for _, u := range r.store.users {
+		if u.TenantID == tenant && match(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+// filter returns the stored users of tenant matching match. The caller holds the lock.
+func (r *UserRepo) filter(tenant entity.TenantID, match func(*entity.User) bool) []*entity.User {
+	var users []*entity.User
+	for _, u := range r.store.users {
+		if u.TenantID == tenant && match(u) {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// matchesFilter applies the set fields of filter; term is the normalized
+// filter.Search.
+func matchesFilter(u *entity.User, filter repository.UserFilter, term string) bool {
+	switch {
+	case filter.CreatedFrom != nil && u.CreatedAt.Before(*filter.CreatedFrom):
+		return false
+	case filter.CreatedTo != nil && !u.CreatedAt.Before(*filter.CreatedTo):
+		return false
+	case filter.EmailDomain != "" && !strings.EqualFold(u.Email.Domain(), filter.EmailDomain):
+		return false
+	case !filter.Phone.IsZero() && u.Phone != filter.Phone:
+		return false
+	case filter.Verified != nil && u.IsEmailVerified() != *filter.Verified:
+		return false
+	case filter.Role != nil && u.Role != *filter.Role:
+		return false
+	case filter.Status != nil && u.Status != *filter.Status:
+		return false
+	case term != "" && !matchesSearch(u, term):
+		return false
+	}
+	return true
+}
+
+func searchTerm(text string) string {
+	return strings.ToLower(strings.TrimSpace(text))
+}
+
+// hasSearchPrefix reports whether the username or email starts with term.
+func hasSearchPrefix(u *entity.User, term string) bool {
+	return strings.HasPrefix(strings.ToLower(u.Username.String()), term) ||
+		strings.HasPrefix(strings.ToLower(u.Email.String()), term)
+}
+
+func searchDocument(u *entity.User) string {
+	return strings.ToLower(strings.Join([]string{u.Username.String(), u.Email.String(), u.FirstName, u.LastName}, " "))
+}
+
+func matchesSearch(u *entity.User, term string) bool {
+	return hasSearchPrefix(u, term) || strings.Contains(searchDocument(u), term)
+}
+
+// searchRank scores like the Postgres rank: 1 for a prefix match on username
+// or email, plus a similarity in [0, 1], here 1 for a substring match.
+func searchRank(u *entity.User, term string) float64 {
+	var rank float64
+	if hasSearchPrefix(u, term) {
+		rank++
+	}
+	if strings.Contains(searchDocument(u), term) {
+		rank++
+	}
+	return rank
+}
+
+// keyed is a user with its sort value; key is a time.Time, string or float64
+// depending on the sort field.
+type keyed struct {
+	user *entity.User
+	key  any
+	id   entity.UserID
+}
+
+// compareKeyed orders by (key, id) ascending.
+func compareKeyed(a, b keyed) int {
+	var c int
+	switch key := a.key.(type) {
+	case time.Time:
+		c = key.Compare(b.key.(time.Time))
+	case float64:
+		c = cmp.Compare(key, b.key.(float64))
+	case string:
+		c = cmp.Compare(key, b.key.(string))
+	}
+	return cmp.Or(c, cmp.Compare(a.id, b.id))
+}
+
+// sortValue returns u's value for field. Usernames and emails compare
+// without case, as citext columns do.
+func sortValue(u *entity.User, field repository.UserSortField, term string) any {
+	switch field {
+	case repository.SortByUsername, repository.SortByEmail:
+		return strings.ToLower(repository.SortKey(u, field))
+	case repository.SortByRelevance:
+		return searchRank(u, term)
+	case repository.SortByLastLogin:
+		if u.LastLoginAt == nil {
+			return time.Unix(0, 0)
+		}
+		return *u.LastLoginAt
+	}
+	return u.CreatedAt
+}
+
+// parseSortKey converts a cursor key to the type sortValue returns for field.
+func parseSortKey(field repository.UserSortField, key string) (any, error) {
+	switch field {
+	case repository.SortByUsername, repository.SortByEmail:
+		return strings.ToLower(key), nil
+	case repository.SortByRelevance:
+		return strconv.ParseFloat(key, 64)
+	}
+	return time.Parse(time.RFC3339Nano, key)
+}
+
+// cloneUser copies u so callers can never modify stored records. It returns
+// nil for a nil user.
+func cloneUser(u *entity.User) *entity.User {
+	if u == nil {
+		return nil
+	}
+	c := *u
+	c.EmailVerifiedAt = cloneTime(u.EmailVerifiedAt)
+	c.LastLoginAt = cloneTime(u.LastLoginAt)
+	c.StatusUntil = cloneTime(u.StatusUntil)
+	c.StatusChangedAt = cloneTime(u.StatusChangedAt)
+	c.SessionsRevokedAt = cloneTime(u.SessionsRevokedAt)
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func cloneUsers(users []*entity.User) []*entity.User {
+	clones := make([]*entity.User, len(users))
+	for i, u := range users {
+		clones[i] = cloneUser(u)
+	}
+	return clones
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/infrastructure/database/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// PostgresGroupRepo implements repository.GroupRepository with GORM. Groups
+// and memberships are tenant-owned and scoped like users.
+type PostgresGroupRepo struct {
+	db *gorm.DB
+}
+
+func NewPostgresGroupRepo(db *gorm.DB) *PostgresGroupRepo {
+	return &PostgresGroupRepo{db: db}
+}
+
+// scoped is conn restricted to the tenant in ctx on table.
+func (r *PostgresGroupRepo) scoped(ctx context.Context, table string) (*gorm.DB, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn(ctx, r.db).Where(table+".tenant_id = ?", string(tenant)), nil
+}
+
+func (r *PostgresGroupRepo) Create(ctx context.Context, group *entity.Group) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	group.TenantID = tenant
+
+	model := models.GroupFromEntity(group)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return translateError(err)
+	}
+	group.CreatedAt, group.UpdatedAt = model.CreatedAt, model.UpdatedAt
+	return nil
+}
+
+func (r *PostgresGroupRepo) GetByID(ctx context.Context, id entity.GroupID) (*entity.Group, error) {
+	// Validate the UUID before it reaches the query
+	parsedID, err := entity.ParseGroupID(string(id))
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.scoped(ctx, "groups")
+	if err != nil {
+		return nil, err
+	}
+
+	var model models.GroupModel
+	if err := db.First(&model, "id = ?", string(parsedID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity()
+}
+
+// AddMember inserts the membership or, for an existing member, updates the
+// role and keeps the original join time. The insert only happens when both
+// the group and the user belong to the context's tenant.
+func (r *PostgresGroupRepo) AddMember(ctx context.Context, membership *entity.Membership) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := conn(ctx, r.db).Exec(`INSERT INTO group_members (group_id, user_id, tenant_id, role, joined_at)
+		SELECT groups.id, users.id, groups.tenant_id, ?, ? FROM groups
+		JOIN users ON users.tenant_id = groups.tenant_id AND users.id = ?
+		WHERE groups.id = ? AND groups.tenant_id = ?
+		ON CONFLICT (group_id, user_id) DO UPDATE SET role = EXCLUDED.role`,
+		string(membership.Role), membership.JoinedAt, string(membership.UserID),
+		string(membership.GroupID), string(tenant))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresGroupRepo) GetMembership(ctx context.Context, group entity.GroupID, user entity.UserID) (*entity.Membership, error) {
+	db, err := r.scoped(ctx, "group_members")
+	if err != nil {
+		return nil, err
+	}
+
+	var model models.MembershipModel
+	err = db.Where("group_id = ? AND user_id = ?", string(group), string(user)).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity()
+}
+
+func (r *PostgresGroupRepo) RemoveMember(ctx context.Context, group entity.GroupID, user entity.UserID) (bool, error) {
+	db, err := r.scoped(ctx, "group_members")
+	if err != nil {
+		return false, err
+	}
+
+	result := db.Where("group_id = ? AND user_id = ?", string(group), string(user)).Delete(&models.MembershipModel{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListMembers reads limit+1 rows after the cursor to learn whether another
+// page follows.
+func (r *PostgresGroupRepo) ListMembers(ctx context.Context, query repository.MemberPageQuery) (*repository.MemberPage, error) {
+	db, err := r.scoped(ctx, "group_members")
+	if err != nil {
+		return nil, err
+	}
+
+	db = db.Where("group_id = ?", string(query.GroupID))
+	if query.After != nil {
+		db = db.Where("(joined_at, user_id) > (?, ?)", query.After.JoinedAt, string(query.After.UserID))
+	}
+	var rows []models.MembershipModel
+	if err := db.Order("joined_at, user_id").Limit(query.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	page := &repository.MemberPage{}
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		last := rows[len(rows)-1]
+		page.Next = &repository.MemberCursor{JoinedAt: last.JoinedAt, UserID: entity.UserID(last.UserID)}
+	}
+	page.Members = make([]*entity.Membership, len(rows))
+	for i := range rows {
+		membership, err := rows[i].ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		page.Members[i] = membership
+	}
+	return page, nil
+}
+
+func (r *PostgresGroupRepo) ListUserGroups(ctx context.Context, user entity.UserID) ([]repository.UserGroup, error) {
+	db, err := r.scoped(ctx, "groups")
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		models.GroupModel
+		Role string
+	}
+	err = db.Model(&models.GroupModel{}).
+		Select("groups.*, group_members.role").
+		Joins("JOIN group_members ON group_members.group_id = groups.id").
+		Where("group_members.user_id = ?", string(user)).
+		Order("groups.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]repository.UserGroup, len(rows))
+	for i := range rows {
+		group, err := rows[i].GroupModel.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		role, err := entity.ParseGroupRole(rows[i].Role)
+		if err != nil {
+			return nil, err
+		}
+		groups[i] = repository.UserGroup{Group: group, Role: role}
+	}
+	return groups, nil
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/infrastructure/database/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// PostgresImpersonationRepo implements repository.ImpersonationRepository
+// with GORM.
+type PostgresImpersonationRepo struct {
+	db *gorm.DB
+}
+
+func NewPostgresImpersonationRepo(db *gorm.DB) *PostgresImpersonationRepo {
+	return &PostgresImpersonationRepo{db: db}
+}
+
+// scoped is conn restricted to the tenant in ctx.
+func (r *PostgresImpersonationRepo) scoped(ctx context.Context) (*gorm.DB, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn(ctx, r.db).Where("impersonations.tenant_id = ?", string(tenant)), nil
+}
+
+func (r *PostgresImpersonationRepo) Create(ctx context.Context, impersonation *entity.Impersonation) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	impersonation.TenantID = tenant
+	return translateError(conn(ctx, r.db).Create(models.ImpersonationFromEntity(impersonation)).Error)
+}
+
+func (r *PostgresImpersonationRepo) GetByID(ctx context.Context, id entity.ImpersonationID) (*entity.Impersonation, error) {
+	// Validate the UUID before it reaches the query
+	parsedID, err := entity.ParseImpersonationID(string(id))
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model models.ImpersonationModel
+	if err := db.Where("id = ?", string(parsedID)).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity(), nil
+}
+
+func (r *PostgresImpersonationRepo) Update(ctx context.Context, impersonation *entity.Impersonation) error {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return err
+	}
+	model := models.ImpersonationFromEntity(impersonation)
+	return translateError(db.Model(model).Select("ended_at", "ended_by").Updates(model).Error)
+}
+
+func (r *PostgresImpersonationRepo) List(ctx context.Context, filter repository.ImpersonationFilter) ([]*entity.Impersonation, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filter.ActorID != nil {
+		db = db.Where("actor_id = ?", string(*filter.ActorID))
+	}
+	if filter.TargetID != nil {
+		db = db.Where("target_id = ?", string(*filter.TargetID))
+	}
+
+	var rows []models.ImpersonationModel
+	if err := db.Order("started_at DESC, id DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	impersonations := make([]*entity.Impersonation, len(rows))
+	for i := range rows {
+		impersonations[i] = rows[i].ToEntity()
+	}
+	return impersonations, nil
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/infrastructure/database/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// PostgresInvitationRepo implements repository.InvitationRepository with GORM.
+type PostgresInvitationRepo struct {
+	db *gorm.DB
+}
+
+func NewPostgresInvitationRepo(db *gorm.DB) *PostgresInvitationRepo {
+	return &PostgresInvitationRepo{db: db}
+}
+
+// scoped is conn restricted to the tenant in ctx.
+func (r *PostgresInvitationRepo) scoped(ctx context.Context) (*gorm.DB, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn(ctx, r.db).Where("invitations.tenant_id = ?", string(tenant)), nil
+}
+
+func (r *PostgresInvitationRepo) Create(ctx context.Context, invitation *entity.Invitation) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	invitation.TenantID = tenant
+
+	model := models.InvitationFromEntity(invitation)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return translateError(err)
+	}
+	invitation.CreatedAt, invitation.UpdatedAt = model.CreatedAt, model.UpdatedAt
+	return nil
+}
+
+func (r *PostgresInvitationRepo) GetByID(ctx context.Context, id entity.InvitationID) (*entity.Invitation, error) {
+	// Validate the UUID before it reaches the query
+	parsedID, err := entity.ParseInvitationID(string(id))
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return firstInvitation(db.Where("id = ?", string(parsedID)))
+}
+
+func (r *PostgresInvitationRepo) GetPending(ctx context.Context, email entity.Email) (*entity.Invitation, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return firstInvitation(db.Where("email = ? AND status = ?", email.String(), string(entity.InvitationPending)))
+}
+
+// GetByTokenHash is deliberately not tenant-scoped; see the port.
+func (r *PostgresInvitationRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	return firstInvitation(conn(ctx, r.db).Where("token_hash = ?", tokenHash))
+}
+
+func (r *PostgresInvitationRepo) Update(ctx context.Context, invitation *entity.Invitation) error {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return err
+	}
+	model := models.InvitationFromEntity(invitation)
+	err = db.Model(model).
+		Select("token_hash", "status", "expires_at", "sent_at", "accepted_at", "accepted_by", "updated_at").
+		Updates(model).Error
+	return translateError(err)
+}
+
+func (r *PostgresInvitationRepo) List(ctx context.Context) ([]*entity.Invitation, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.InvitationModel
+	if err := db.Order("created_at DESC, id DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	invitations := make([]*entity.Invitation, len(rows))
+	for i := range rows {
+		invitation, err := rows[i].ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		invitations[i] = invitation
+	}
+	return invitations, nil
+}
+
+// firstInvitation loads the invitation matched by db, or nil.
+func firstInvitation(db *gorm.DB) (*entity.Invitation, error) {
+	var model models.InvitationModel
+	if err := db.First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity()
+}
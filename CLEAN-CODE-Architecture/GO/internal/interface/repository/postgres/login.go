@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/infrastructure/database/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// PostgresLoginAttemptRepo implements repository.LoginAttemptRepository
+// with GORM.
+type PostgresLoginAttemptRepo struct {
+	db *gorm.DB
+}
+
+func NewPostgresLoginAttemptRepo(db *gorm.DB) *PostgresLoginAttemptRepo {
+	return &PostgresLoginAttemptRepo{db: db}
+}
+
+// scoped is conn restricted to the tenant in ctx.
+func (r *PostgresLoginAttemptRepo) scoped(ctx context.Context) (*gorm.DB, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn(ctx, r.db).Where("login_attempts.tenant_id = ?", string(tenant)), nil
+}
+
+func (r *PostgresLoginAttemptRepo) Create(ctx context.Context, attempt *entity.LoginAttempt) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	attempt.TenantID = tenant
+	return translateError(conn(ctx, r.db).Create(models.LoginAttemptFromEntity(attempt)).Error)
+}
+
+func (r *PostgresLoginAttemptRepo) List(ctx context.Context, query repository.LoginAttemptQuery) ([]*entity.LoginAttempt, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db = db.Where("user_id = ?", string(query.UserID))
+	if query.Before != "" {
+		before, err := entity.ParseLoginAttemptID(string(query.Before))
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("id < ?", string(before))
+	}
+	var rows []models.LoginAttemptModel
+	if err := db.Order("id DESC").Limit(query.Limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	attempts := make([]*entity.LoginAttempt, len(rows))
+	for i := range rows {
+		attempts[i] = rows[i].ToEntity()
+	}
+	return attempts, nil
+}
+
+// Familiarity answers all three questions in one round trip.
+func (r *PostgresLoginAttemptRepo) Familiarity(ctx context.Context, user entity.UserID, fingerprint, ipRange string) (repository.LoginFamiliarity, error) {
+	var familiarity repository.LoginFamiliarity
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return familiarity, err
+	}
+
+	const previous = "SELECT 1 FROM login_attempts WHERE tenant_id = @tenant AND user_id = @user AND success"
+	err = conn(ctx, r.db).Raw(
+		"SELECT EXISTS ("+previous+") AS previous_logins, "+
+			"EXISTS ("+previous+" AND fingerprint = @fingerprint) AS known_device, "+
+			"EXISTS ("+previous+" AND ip_range = @range) AS known_ip_range",
+		map[string]interface{}{
+			"tenant":      string(tenant),
+			"user":        string(user),
+			"fingerprint": fingerprint,
+			"range":       ipRange,
+		},
+	).Scan(&familiarity).Error
+	return familiarity, err
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/infrastructure/database/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresMagicLinkRepo implements repository.MagicLinkRepository with GORM.
+type PostgresMagicLinkRepo struct {
+	db *gorm.DB
+}
+
+func NewPostgresMagicLinkRepo(db *gorm.DB) *PostgresMagicLinkRepo {
+	return &PostgresMagicLinkRepo{db: db}
+}
+
+// scoped is conn restricted to the tenant in ctx.
+func (r *PostgresMagicLinkRepo) scoped(ctx context.Context) (*gorm.DB, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn(ctx, r.db).Where("magic_links.tenant_id = ?", string(tenant)), nil
+}
+
+// CreateWithinLimit locks the user's row for the transaction, so concurrent
+// requests for one account count and insert one after the other. Links of
+// a user that no longer exists are not stored.
+func (r *PostgresMagicLinkRepo) CreateWithinLimit(ctx context.Context, link *entity.MagicLink, since time.Time, limit int) (bool, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+	created := false
+	err = conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var owner models.UserModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("tenant_id = ? AND id = ?", string(tenant), string(link.UserID)).
+			First(&owner).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&models.MagicLinkModel{}).
+			Where("tenant_id = ? AND user_id = ? AND created_at >= ?", string(tenant), string(link.UserID), since).
+			Count(&count).Error
+		if err != nil || count >= int64(limit) {
+			return err
+		}
+
+		link.TenantID = tenant
+		if err := tx.Create(models.MagicLinkFromEntity(link)).Error; err != nil {
+			return translateError(err)
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// GetByTokenHash is deliberately not tenant-scoped; see the port.
+func (r *PostgresMagicLinkRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.MagicLink, error) {
+	var model models.MagicLinkModel
+	if err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity(), nil
+}
+
+func (r *PostgresMagicLinkRepo) Use(ctx context.Context, id entity.MagicLinkID, now time.Time) (bool, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return false, err
+	}
+	// The used_at condition makes this a compare-and-set
+	result := db.Model(&models.MagicLinkModel{}).
+		Where("id = ? AND used_at IS NULL", string(id)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/infrastructure/database/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresSessionRepo implements repository.SessionRepository with GORM.
+type PostgresSessionRepo struct {
+	db *gorm.DB
+}
+
+func NewPostgresSessionRepo(db *gorm.DB) *PostgresSessionRepo {
+	return &PostgresSessionRepo{db: db}
+}
+
+// scoped is conn restricted to the tenant in ctx.
+func (r *PostgresSessionRepo) scoped(ctx context.Context) (*gorm.DB, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn(ctx, r.db).Where("sessions.tenant_id = ?", string(tenant)), nil
+}
+
+func (r *PostgresSessionRepo) Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	session.TenantID, token.TenantID = tenant, tenant
+
+	// The token references the session, so both go in or neither does
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(models.SessionFromEntity(session)).Error; err != nil {
+			return translateError(err)
+		}
+		return translateError(tx.Create(models.RefreshTokenFromEntity(token)).Error)
+	})
+}
+
+func (r *PostgresSessionRepo) GetByID(ctx context.Context, id entity.SessionID) (*entity.Session, error) {
+	// Validate the UUID before it reaches the query
+	parsedID, err := entity.ParseSessionID(string(id))
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model models.SessionModel
+	if err := db.Where("id = ?", string(parsedID)).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity(), nil
+}
+
+func (r *PostgresSessionRepo) ListByUser(ctx context.Context, user entity.UserID, now time.Time) ([]*entity.Session, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.SessionModel
+	err = db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", string(user), now).
+		Order("last_seen_at DESC, id DESC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*entity.Session, len(rows))
+	for i := range rows {
+		sessions[i] = rows[i].ToEntity()
+	}
+	return sessions, nil
+}
+
+func (r *PostgresSessionRepo) Update(ctx context.Context, session *entity.Session) error {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return err
+	}
+	model := models.SessionFromEntity(session)
+	return translateError(db.Model(model).Select("revoked_at").Updates(model).Error)
+}
+
+// GetRefreshToken is deliberately not tenant-scoped; see the port.
+func (r *PostgresSessionRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var model models.RefreshTokenModel
+	if err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity(), nil
+}
+
+func (r *PostgresSessionRepo) UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (bool, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+	// The used_at condition makes this a compare-and-set
+	result := conn(ctx, r.db).Model(&models.RefreshTokenModel{}).
+		Where("token_hash = ? AND tenant_id = ? AND used_at IS NULL", tokenHash, string(tenant)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *PostgresSessionRepo) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return err
+	}
+	token.TenantID = tenant
+	return translateError(conn(ctx, r.db).Create(models.RefreshTokenFromEntity(token)).Error)
+}
+
+// TouchLastSeen is deliberately not tenant-scoped; see the port. Each
+// session is one small UPDATE, all in one transaction.
+func (r *PostgresSessionRepo) TouchLastSeen(ctx context.Context, seen map[entity.SessionID]time.Time) error {
+	if len(seen) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for id, at := range seen {
+			err := tx.Model(&models.SessionModel{}).
+				Where("id = ? AND last_seen_at < ?", string(id), at).
+				Update("last_seen_at", at).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
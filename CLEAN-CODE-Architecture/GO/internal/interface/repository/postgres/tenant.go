@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/infrastructure/database/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// PostgresTenantRepo implements repository.TenantRepository with GORM.
+type PostgresTenantRepo struct {
+	db *gorm.DB
+}
+
+func NewPostgresTenantRepo(db *gorm.DB) *PostgresTenantRepo {
+	return &PostgresTenantRepo{db: db}
+}
+
+func (r *PostgresTenantRepo) Create(ctx context.Context, tenant *entity.Tenant) error {
+	model := models.TenantFromEntity(tenant)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return translateError(err)
+	}
+	tenant.CreatedAt, tenant.UpdatedAt = model.CreatedAt, model.UpdatedAt
+	return nil
+}
+
+func (r *PostgresTenantRepo) GetByID(ctx context.Context, id entity.TenantID) (*entity.Tenant, error) {
+	// Validate the UUID before it reaches the query
+	parsedID, err := entity.ParseTenantID(string(id))
+	if err != nil {
+		return nil, err
+	}
+	return r.first(ctx, "id = ?", string(parsedID))
+}
+
+func (r *PostgresTenantRepo) GetBySlug(ctx context.Context, slug entity.TenantSlug) (*entity.Tenant, error) {
+	return r.first(ctx, "slug = ?", slug.String())
+}
+
+func (r *PostgresTenantRepo) List(ctx context.Context) ([]*entity.Tenant, error) {
+	var rows []models.TenantModel
+	if err := conn(ctx, r.db).Order("slug").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	tenants := make([]*entity.Tenant, len(rows))
+	for i := range rows {
+		tenant, err := rows[i].ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		tenants[i] = tenant
+	}
+	return tenants, nil
+}
+
+// Update stores the tenant's name; the slug never changes.
+func (r *PostgresTenantRepo) Update(ctx context.Context, tenant *entity.Tenant) error {
+	model := models.TenantFromEntity(tenant)
+	return conn(ctx, r.db).Model(model).Select("name", "updated_at").Updates(model).Error
+}
+
+func (r *PostgresTenantRepo) first(ctx context.Context, query string, arg interface{}) (*entity.Tenant, error) {
+	var model models.TenantModel
+	if err := conn(ctx, r.db).Where(query, arg).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToEntity()
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key under which TxManager stores the open *gorm.DB
+// transaction.
+type txKey struct{}
+
+// TxManager implements repository.TxManager with GORM transactions. Nested
+// calls run inside a savepoint of the outer transaction.
+type TxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager creates a TxManager for db. Repositories must share the same
+// *gorm.DB for their queries to join its transactions.
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx runs fn in a transaction carried by the ctx it receives.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction open in ctx, or db when there is none, bound
+// to ctx. Every repository query starts from it.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -8,151 +8,675 @@ package postgres
 
 import (
 	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
 	"auth-module/internal/infrastructure/database/models"
+	"auth-module/internal/infrastructure/encryption"
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// pgUniqueViolation is the Postgres SQLSTATE for unique_violation.
+const pgUniqueViolation = "23505"
+
+// Phone and address are stored encrypted by cipher; the domain only ever
+// sees plaintext. Field names double as the associated data and blind index
+// domain, so they must not change once data is written.
 type PostgresUserRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	cipher *encryption.FieldCipher
+}
+
+const (
+	phoneField   = "users.phone"
+	addressField = "users.address"
+)
+
+func NewPostgresUserRepo(db *gorm.DB, cipher *encryption.FieldCipher) *PostgresUserRepo {
+	return &PostgresUserRepo{db: db, cipher: cipher}
+}
+
+// conn returns the connection queries should use: the transaction started by
+// TxManager.WithinTx if ctx carries one, the pool otherwise.
+func (r *PostgresUserRepo) conn(ctx context.Context) *gorm.DB {
+	return conn(ctx, r.db)
 }
 
-func NewPostgresUserRepo(db *gorm.DB) *PostgresUserRepo {
-	return &PostgresUserRepo{db: db}
+// scoped is conn restricted to the tenant in ctx. Every query on users goes
+// through it, so no method can read or change another tenant's rows.
+func (r *PostgresUserRepo) scoped(ctx context.Context) (*gorm.DB, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.conn(ctx).Where("users.tenant_id = ?", string(tenant)), nil
 }
 
 func (r *PostgresUserRepo) Create(ctx context.Context, user *entity.User) (*entity.User, error) {
-	// Convert domain entity to GORM model
-	model := models.FromEntity(user)
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create record in database
-	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+	// Convert domain entity to GORM model
+	model, err := r.toModel(user)
+	if err != nil {
 		return nil, err
 	}
+	model.TenantID = string(tenant)
+
+	// Create record in database. The unique indexes decide whether the
+	// email or username is taken within the tenant, so concurrent
+	// registrations cannot race.
+	if err := r.conn(ctx).Create(model).Error; err != nil {
+		return nil, translateError(err)
+	}
 
 	// Convert back to domain entity with generated ID
-	return model.ToEntity(), nil
+	return r.toEntity(model)
 }
 
 func (r *PostgresUserRepo) GetByID(ctx context.Context, id entity.UserID) (*entity.User, error) {
 	var model models.UserModel
 	
-	// Parse UserID to uint for GORM query
-	parsedID, err := entity.ParseUserIDToUint(id)
+	// Validate the UUID before it reaches the query
+	parsedID, err := entity.ParseUserID(string(id))
 	if err != nil {
 		return nil, err
 	}
 
-	if err := r.db.WithContext(ctx).First(&model, parsedID).Error; err != nil {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.First(&model, "id = ?", string(parsedID)).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return model.ToEntity(), nil
+	return r.toEntity(&model)
 }
 
-func (r *PostgresUserRepo) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+func (r *PostgresUserRepo) GetByEmail(ctx context.Context, email entity.Email) (*entity.User, error) {
 	var model models.UserModel
 
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&model).Error; err != nil {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Where("email = ?", email.String()).First(&model).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return model.ToEntity(), nil
+	return r.toEntity(&model)
+}
+
+func (r *PostgresUserRepo) GetByEmails(ctx context.Context, emails []entity.Email) ([]*entity.User, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	values := make([]string, len(emails))
+	for i, email := range emails {
+		values[i] = email.String()
+	}
+
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rows []models.UserModel
+	if err := db.Where("email IN ?", values).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return r.toEntities(rows)
 }
 
-func (r *PostgresUserRepo) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+func (r *PostgresUserRepo) GetByUsername(ctx context.Context, username entity.Username) (*entity.User, error) {
 	var model models.UserModel
 
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&model).Error; err != nil {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Where("username = ?", username.String()).First(&model).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return model.ToEntity(), nil
+	return r.toEntity(&model)
 }
 
+// Update writes every column in a single conditional UPDATE: the row is only
+// changed if its version is still the one the entity was read at. Zero rows
+// affected means another writer got there first (or the user was deleted).
 func (r *PostgresUserRepo) Update(ctx context.Context, user *entity.User) error {
 	// Convert domain entity to GORM model
-	model := models.FromEntity(user)
+	model, err := r.toModel(user)
+	if err != nil {
+		return err
+	}
+	model.Version = user.Version + 1
 
-	// Update record in database
-	return r.db.WithContext(ctx).Save(model).Error
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The tenant, legacy ID and creation time are not changed by updates
+	result := db.Model(model).
+		Where("version = ?", user.Version).
+		Select("*").Omit("id", "tenant_id", "legacy_id", "created_at").
+		Updates(model)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &repository.VersionConflictError{ID: user.ID, Version: user.Version}
+	}
+
+	user.Version = model.Version
+	user.UpdatedAt = model.UpdatedAt
+	return nil
 }
 
 func (r *PostgresUserRepo) Delete(ctx context.Context, id entity.UserID) error {
-	// Parse UserID to uint for GORM query
-	parsedID, err := entity.ParseUserIDToUint(id)
+	// Validate the UUID before it reaches the query
+	parsedID, err := entity.ParseUserID(string(id))
 	if err != nil {
 		return err
 	}
 
-	return r.db.WithContext(ctx).Delete(&models.UserModel{}, parsedID).Error
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Delete(&models.UserModel{}, "id = ?", string(parsedID)).Error
 }
 
-func (r *PostgresUserRepo) List(ctx context.Context, limit, offset int) ([]*entity.User, error) {
-	var models []models.UserModel
+// GetByLegacyID finds an account by the integer ID it had before IDs became
+// UUIDs.
+func (r *PostgresUserRepo) GetByLegacyID(ctx context.Context, legacyID int64) (*entity.User, error) {
+	var model models.UserModel
 
-	if err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+	db, err := r.scoped(ctx)
+	if err != nil {
 		return nil, err
 	}
-
-	// Convert models to domain entities
-	users := make([]*entity.User, len(models))
-	for i, model := range models {
-		users[i] = model.ToEntity()
+	if err := db.Where("legacy_id = ?", legacyID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	return users, nil
+	return r.toEntity(&model)
 }
 
 func (r *PostgresUserRepo) Count(ctx context.Context) (int64, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return 0, err
+	}
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&models.UserModel{}).Count(&count).Error; err != nil {
+	if err := db.Model(&models.UserModel{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (r *PostgresUserRepo) Search(ctx context.Context, query string, limit, offset int) ([]*entity.User, error) {
-	var models []models.UserModel
+func (r *PostgresUserRepo) GetByEmailOrUsername(ctx context.Context, emailOrUsername string) (*entity.User, error) {
+	var model models.UserModel
+
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Where("email = ? OR username = ?", emailOrUsername, emailOrUsername).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.toEntity(&model)
+}
+
+// userSortColumns maps the allow-listed sort fields to SQL expressions.
+// Only these expressions are ever interpolated into ORDER BY; a missing
+// last login sorts as the epoch so the keyset comparison never sees NULL.
+var userSortColumns = map[repository.UserSortField]string{
+	repository.SortByCreatedAt: "created_at",
+	repository.SortByUsername:  "username",
+	repository.SortByEmail:     "email",
+	repository.SortByLastLogin: "COALESCE(last_login_at, 'epoch'::timestamptz)",
+}
+
+// pageRow is a users row plus its search rank, which is only selected when
+// sorting by relevance.
+type pageRow struct {
+	models.UserModel `gorm:"embedded"`
+	SearchRank       float64
+}
 
-	// Search in username, email, first_name, or last_name
-	searchPattern := "%" + query + "%"
-	if err := r.db.WithContext(ctx).Where(
-		"username ILIKE ? OR email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?",
-		searchPattern, searchPattern, searchPattern, searchPattern,
-	).Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+// ListPage reads one keyset page ordered by (sort column, id). One extra row
+// is fetched to learn whether another page exists in the reading direction;
+// the opposite direction has more rows whenever a cursor was given.
+func (r *PostgresUserRepo) ListPage(ctx context.Context, query repository.UserPageQuery) (*repository.UserPage, error) {
+	sort := query.Sort
+	if query.Cursor != nil {
+		sort = query.Cursor.Sort
+	}
+	if sort.Field == "" {
+		sort = repository.DefaultUserSort
+	}
+	tx, err := r.scoped(ctx)
+	if err != nil {
 		return nil, err
 	}
+	tx = tx.Model(&models.UserModel{}).Scopes(r.filterScope(query.Filter))
+
+	// column is an allow-listed expression; columnArgs are its parameters
+	column, ok := userSortColumns[sort.Field]
+	var columnArgs []interface{}
+	switch {
+	case sort.Field == repository.SortByRelevance:
+		if query.Filter.Search == "" {
+			return nil, errors.New("relevance sort requires a search")
+		}
+		column, columnArgs = searchRank(query.Filter.Search)
+		tx = tx.Select("users.*, "+column+" AS search_rank", columnArgs...)
+	case !ok:
+		return nil, fmt.Errorf("unsupported sort field %q", sort.Field)
+	default:
+		tx = tx.Select("users.*")
+	}
 
-	// Convert models to domain entities
-	users := make([]*entity.User, len(models))
-	for i, model := range models {
-		users[i] = model.ToEntity()
+	// Reading backwards flips both the comparison and the order; the rows
+	// are reversed again below.
+	descending := sort.Desc
+	backwards := query.Cursor != nil && query.Cursor.Direction == repository.CursorPrev
+	if backwards {
+		descending = !descending
 	}
 
-	return users, nil
+	if query.Cursor != nil {
+		id, err := entity.ParseUserID(string(query.Cursor.ID))
+		if err != nil {
+			return nil, err
+		}
+		key, err := sortKeyValue(sort.Field, query.Cursor.Key)
+		if err != nil {
+			return nil, err
+		}
+		op := ">"
+		if descending {
+			op = "<"
+		}
+		tx = tx.Where("("+column+", id) "+op+" (?, ?)", append(columnArgs, key, string(id))...)
+	}
+	if descending {
+		tx = tx.Order(orderBy(column+" DESC, id DESC", columnArgs))
+	} else {
+		tx = tx.Order(orderBy(column+", id", columnArgs))
+	}
+
+	var rows []pageRow
+	if err := tx.Limit(query.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	more := len(rows) > query.Limit
+	if more {
+		rows = rows[:query.Limit]
+	}
+	if backwards {
+		slices.Reverse(rows)
+	}
+
+	page := &repository.UserPage{Users: make([]*entity.User, len(rows)), Sort: sort, Limit: query.Limit}
+	if sort.Field == repository.SortByRelevance {
+		page.Keys = make([]string, len(rows))
+	}
+	for i := range rows {
+		user, err := r.toEntity(&rows[i].UserModel)
+		if err != nil {
+			return nil, err
+		}
+		page.Users[i] = user
+		if page.Keys != nil {
+			page.Keys[i] = strconv.FormatFloat(rows[i].SearchRank, 'g', -1, 64)
+		}
+	}
+	if backwards {
+		page.HasPrev, page.HasNext = more, true
+	} else {
+		page.HasNext, page.HasPrev = more, query.Cursor != nil
+	}
+	return page, nil
 }
 
-func (r *PostgresUserRepo) GetByEmailOrUsername(ctx context.Context, emailOrUsername string) (*entity.User, error) {
-	var model models.UserModel
+func (r *PostgresUserRepo) CountMatching(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	db, err := r.scoped(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := db.Model(&models.UserModel{}).Scopes(r.filterScope(filter)).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
 
-	if err := r.db.WithContext(ctx).Where("email = ? OR username = ?", emailOrUsername, emailOrUsername).First(&model).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
+// upsertByEmail turns an insert into an update of the profile of the account
+// that already owns the email in the same tenant. Password, role, status,
+// verification and login timestamps are left untouched, and the version is
+// bumped like any other update.
+var upsertByEmail = clause.OnConflict{
+	Columns: []clause.Column{{Name: "tenant_id"}, {Name: "email"}},
+	DoUpdates: append(clause.AssignmentColumns([]string{
+		"username", "first_name", "last_name", "phone", "phone_bidx", "address", "updated_at",
+	}), clause.Assignment{Column: clause.Column{Name: "version"}, Value: gorm.Expr("users.version + 1")}),
+}
+
+var returningID = clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "version"}}}
+
+// UpsertBatch runs the batch in one transaction with a savepoint per user,
+// so a user that violates a constraint is rolled back alone. Inside
+// TxManager.WithinTx the batch becomes a savepoint of that transaction.
+func (r *PostgresUserRepo) UpsertBatch(ctx context.Context, users []*entity.User) ([]error, error) {
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(users))
+	err = r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, user := range users {
+			if err := tx.SavePoint("upsert_user").Error; err != nil {
+				return err
+			}
+			model, err := r.toModel(user)
+			if err != nil {
+				return err
+			}
+			model.TenantID = string(tenant)
+			// An update keeps the existing account's ID and bumps its
+			// version, so read both back
+			if err := tx.Clauses(upsertByEmail, returningID).Create(model).Error; err != nil {
+				if rbErr := tx.RollbackTo("upsert_user").Error; rbErr != nil {
+					return rbErr
+				}
+				errs[i] = translateError(err)
+				continue
+			}
+			if err := tx.Exec("RELEASE SAVEPOINT upsert_user").Error; err != nil {
+				return err
+			}
+			user.ID = entity.UserID(model.ID)
+			user.TenantID = tenant
+			user.Version = model.Version
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// ReencryptReport summarizes a Reencrypt run.
+type ReencryptReport struct {
+	Scanned     int `json:"scanned"`
+	Reencrypted int `json:"reencrypted"`
+	// Skipped counts rows changed by another writer during the run; that
+	// write already used the primary key.
+	Skipped int `json:"skipped"`
+}
+
+// Reencrypt seals every phone and address that is still plaintext or under a
+// retired key with the primary key, and recomputes phone blind indexes that
+// no longer match (after the blind index key changed). Rows are read in id
+// order, batchSize at a time, and each is updated only if its ciphertext is
+// still the one that was read, so concurrent profile updates are never
+// overwritten. Versions and updated_at are left alone: the data is unchanged.
+// As a maintenance task it covers every tenant.
+func (r *PostgresUserRepo) Reencrypt(ctx context.Context, batchSize int) (ReencryptReport, error) {
+	var report ReencryptReport
+	after := ""
+	for {
+		var rows []models.UserModel
+		tx := r.conn(ctx).Select("id", "phone", "phone_bidx", "address").Order("id").Limit(batchSize)
+		if after != "" {
+			tx = tx.Where("id > ?", after)
+		}
+		if err := tx.Find(&rows).Error; err != nil {
+			return report, err
+		}
+		if len(rows) == 0 {
+			return report, nil
+		}
+		after = rows[len(rows)-1].ID
+
+		for i := range rows {
+			row := &rows[i]
+			report.Scanned++
+
+			plain := models.UserModel{}
+			var err error
+			if plain.Phone, err = r.cipher.Decrypt(phoneField, row.Phone); err != nil {
+				return report, fmt.Errorf("user %s phone: %w", row.ID, err)
+			}
+			if plain.Address, err = r.cipher.Decrypt(addressField, row.Address); err != nil {
+				return report, fmt.Errorf("user %s address: %w", row.ID, err)
+			}
+			if !r.cipher.NeedsReencrypt(row.Phone) && !r.cipher.NeedsReencrypt(row.Address) &&
+				equalIndex(r.phoneIndex(plain.Phone), row.PhoneIndex) {
+				continue
+			}
+
+			if err := r.seal(&plain); err != nil {
+				return report, err
+			}
+			result := r.conn(ctx).Model(&models.UserModel{}).
+				Where("id = ? AND phone IS NOT DISTINCT FROM ? AND address IS NOT DISTINCT FROM ?", row.ID, row.Phone, row.Address).
+				UpdateColumns(map[string]interface{}{
+					"phone":      plain.Phone,
+					"phone_bidx": plain.PhoneIndex,
+					"address":    plain.Address,
+				})
+			if result.Error != nil {
+				return report, result.Error
+			}
+			if result.RowsAffected == 0 {
+				report.Skipped++
+			} else {
+				report.Reencrypted++
+			}
+		}
+	}
+}
+
+func equalIndex(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sortKeyValue converts a cursor key back to the type of its sort column.
+func sortKeyValue(field repository.UserSortField, key string) (interface{}, error) {
+	switch field {
+	case repository.SortByCreatedAt, repository.SortByLastLogin:
+		return time.Parse(time.RFC3339Nano, key)
+	case repository.SortByRelevance:
+		return strconv.ParseFloat(key, 64)
+	}
+	return key, nil
+}
+
+// filterScope applies the set fields of filter. Every value is bound as a
+// parameter; no filter input reaches the SQL text. Phones are encrypted, so
+// they are matched through their blind index.
+func (r *PostgresUserRepo) filterScope(filter repository.UserFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.CreatedFrom != nil {
+			db = db.Where("created_at >= ?", *filter.CreatedFrom)
+		}
+		if filter.CreatedTo != nil {
+			db = db.Where("created_at < ?", *filter.CreatedTo)
+		}
+		if filter.EmailDomain != "" {
+			db = db.Where(`email LIKE ? ESCAPE '\'`, "%@"+escapeLike(filter.EmailDomain))
+		}
+		if !filter.Phone.IsZero() {
+			db = db.Where("phone_bidx = ?", r.cipher.BlindIndex(phoneField, filter.Phone.String()))
 		}
+		if filter.Verified != nil {
+			if *filter.Verified {
+				db = db.Where("email_verified_at IS NOT NULL")
+			} else {
+				db = db.Where("email_verified_at IS NULL")
+			}
+		}
+		if filter.Role != nil {
+			db = db.Where("role = ?", string(*filter.Role))
+		}
+		if filter.Status != nil {
+			db = db.Where("status = ?", string(*filter.Status))
+		}
+		if filter.Search != "" {
+			db = db.Scopes(searchScope(filter.Search))
+		}
+		return db
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchScope restricts a query to users whose username or email starts
+// with text, or whose username, email or name is similar to it. Similarity
+// uses pg_trgm word similarity (operator <%), which the trigram index on
+// models.UserSearchDocument serves; prefix matches use the pattern-ops
+// indexes. Wildcards in text are escaped, so they match literally.
+func searchScope(text string) func(*gorm.DB) *gorm.DB {
+	term, prefix := searchTerms(text)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			`(lower(username::text) LIKE ? ESCAPE '\' OR lower(email::text) LIKE ? ESCAPE '\' OR ? <% `+models.UserSearchDocument+`)`,
+			prefix, prefix, term,
+		)
+	}
+}
+
+// searchRank returns the relevance expression for text and its parameters.
+// A prefix match on username or email outranks any similarity, which is
+// between 0 and 1. The result is float8 so it survives a cursor round trip.
+func searchRank(text string) (string, []interface{}) {
+	term, prefix := searchTerms(text)
+	sql := `((CASE WHEN lower(username::text) LIKE ? ESCAPE '\' OR lower(email::text) LIKE ? ESCAPE '\' THEN 1 ELSE 0 END) + ` +
+		`word_similarity(?, ` + models.UserSearchDocument + `))::float8`
+	return sql, []interface{}{prefix, prefix, term}
+}
+
+// orderBy builds an ORDER BY clause from an allow-listed expression that
+// may carry bound parameters.
+func orderBy(sql string, vars []interface{}) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: vars}}
+}
+
+// searchTerms normalizes the search text and derives its prefix pattern.
+func searchTerms(text string) (term, prefix string) {
+	term = strings.ToLower(strings.TrimSpace(text))
+	return term, escapeLike(term) + "%"
+}
+
+// toModel converts user to a GORM model with its personal fields encrypted.
+func (r *PostgresUserRepo) toModel(user *entity.User) (*models.UserModel, error) {
+	model := models.FromEntity(user)
+	if err := r.seal(model); err != nil {
 		return nil, err
 	}
+	return model, nil
+}
+
+// toEntity decrypts the personal fields of model in place and converts it
+// to a domain entity.
+func (r *PostgresUserRepo) toEntity(model *models.UserModel) (*entity.User, error) {
+	var err error
+	if model.Phone, err = r.cipher.Decrypt(phoneField, model.Phone); err != nil {
+		return nil, fmt.Errorf("user %s phone: %w", model.ID, err)
+	}
+	if model.Address, err = r.cipher.Decrypt(addressField, model.Address); err != nil {
+		return nil, fmt.Errorf("user %s address: %w", model.ID, err)
+	}
+	return model.ToEntity()
+}
 
-	return model.ToEntity(), nil
+// seal replaces the plaintext personal fields of model with ciphertext under
+// the primary key and sets the phone blind index.
+func (r *PostgresUserRepo) seal(model *models.UserModel) error {
+	model.PhoneIndex = r.phoneIndex(model.Phone)
+	var err error
+	if model.Phone, err = r.cipher.Encrypt(phoneField, model.Phone); err != nil {
+		return err
+	}
+	if model.Address, err = r.cipher.Encrypt(addressField, model.Address); err != nil {
+		return err
+	}
+	return nil
+}
+
+// phoneIndex returns the blind index of an E.164 phone number, or nil when
+// there is none.
+func (r *PostgresUserRepo) phoneIndex(phone string) *string {
+	index := r.cipher.BlindIndex(phoneField, phone)
+	if index == "" {
+		return nil
+	}
+	return &index
+}
+
+// toEntities converts a slice of GORM models to domain entities.
+func (r *PostgresUserRepo) toEntities(rows []models.UserModel) ([]*entity.User, error) {
+	users := make([]*entity.User, len(rows))
+	for i := range rows {
+		user, err := r.toEntity(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		users[i] = user
+	}
+	return users, nil
+}
+
+// translateError maps Postgres constraint violations to domain errors.
+// Unique violations on the users indexes become a field-level ConflictError;
+// anything else is returned unchanged.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		if field := models.UniqueIndexField(pgErr.ConstraintName); field != "" {
+			return &repository.ConflictError{Field: field}
+		}
+	}
+	return err
 }
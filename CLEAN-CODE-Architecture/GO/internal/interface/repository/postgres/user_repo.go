@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
 	"context"
 
 	"gorm.io/gorm"
@@ -26,12 +27,12 @@ func (repo *UserRepo) GetByID(ctx context.Context, id entity.UserID) (*entity.Us
 	return nil, nil
 }
 
-func (repo *UserRepo) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+func (repo *UserRepo) GetByEmail(ctx context.Context, email entity.Email) (*entity.User, error) {
 	// Implement logic to retrieve a user by email from the database
 	return nil, nil
 }
 
-func (repo *UserRepo) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+func (repo *UserRepo) GetByUsername(ctx context.Context, username entity.Username) (*entity.User, error) {
 	// Implement logic to retrieve a user by username from the database
 	return nil, nil
 }
@@ -76,3 +77,23 @@ func (repo *UserRepo) Create(ctx context.Context, user *entity.User) (*entity.Us
 	// Implement logic to create a user in the database
 	return nil, nil
 }
+
+func (repo *UserRepo) ListPage(ctx context.Context, query repository.UserPageQuery) (*repository.UserPage, error) {
+	// Implement logic to list users with keyset pagination
+	return &repository.UserPage{}, nil
+}
+
+func (repo *UserRepo) CountMatching(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	// Implement logic to count users matching a filter
+	return 0, nil
+}
+
+func (repo *UserRepo) UpsertBatch(ctx context.Context, users []*entity.User) ([]error, error) {
+	// Implement logic to insert or update users in one transaction
+	return make([]error, len(users)), nil
+}
+
+func (repo *UserRepo) GetByLegacyID(ctx context.Context, legacyID int64) (*entity.User, error) {
+	// Implement logic to get a user by legacy integer ID
+	return nil, nil
+}
@@ -0,0 +1,57 @@
+package repotest_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/interface/repository/repotest"
+)
+
+// TestMagicLinkLimitIsAtomic requests many links for one account at once:
+// no more than the limit may be stored, however the calls interleave.
+func TestMagicLinkLimitIsAtomic(t *testing.T) {
+	const (
+		parallel = 20
+		limit    = 3
+	)
+
+	repotest.Run(t, func(t *testing.T, b repotest.Backend) {
+		ctx := b.NewTenant(t, "magic-links")
+		user := b.CreateUser(t, ctx, "linked", "linked@example.com")
+		since := time.Now().Add(-time.Hour)
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			created int
+		)
+		start := make(chan struct{})
+		for i := 0; i < parallel; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				link := entity.NewMagicLink(user, fmt.Sprintf("token-hash-%d", i), "203.0.113.7", time.Minute)
+				ok, err := b.MagicLinks.CreateWithinLimit(ctx, link, since, limit)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if ok {
+					mu.Lock()
+					created++
+					mu.Unlock()
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if created != limit {
+			t.Errorf("%d links stored, want the limit of %d", created, limit)
+		}
+	})
+}
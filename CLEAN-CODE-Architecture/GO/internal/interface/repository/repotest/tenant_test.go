@@ -0,0 +1,122 @@
+package repotest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/interface/repository/repotest"
+)
+
+// TestUsersDoNotLeakAcrossTenants stores a user in tenant A and checks that
+// no read, listing or write made for tenant B can see or change it.
+func TestUsersDoNotLeakAcrossTenants(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, b repotest.Backend) {
+		ctxA := b.NewTenant(t, "tenant-a")
+		ctxB := b.NewTenant(t, "tenant-b")
+		alice := b.CreateUser(t, ctxA, "alice", "alice@example.com")
+		b.CreateUser(t, ctxB, "bob", "bob@example.com")
+
+		t.Run("lookups", func(t *testing.T) {
+			lookups := map[string]func() (*entity.User, error){
+				"GetByID":    func() (*entity.User, error) { return b.Users.GetByID(ctxB, alice.ID) },
+				"GetByEmail": func() (*entity.User, error) { return b.Users.GetByEmail(ctxB, alice.Email) },
+				"GetByUsername": func() (*entity.User, error) {
+					return b.Users.GetByUsername(ctxB, alice.Username)
+				},
+				"GetByEmailOrUsername": func() (*entity.User, error) {
+					return b.Users.GetByEmailOrUsername(ctxB, "alice")
+				},
+			}
+			for name, lookup := range lookups {
+				user, err := lookup()
+				if err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+				if user != nil {
+					t.Errorf("%s from tenant B found tenant A's user", name)
+				}
+			}
+		})
+
+		t.Run("listings", func(t *testing.T) {
+			page, err := b.Users.ListPage(ctxB, repository.UserPageQuery{Limit: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkOnlyBob(t, "ListPage", page.Users)
+
+			page, err = b.Users.ListPage(ctxB, repository.UserPageQuery{
+				Limit:  100,
+				Filter: repository.UserFilter{Search: "alice"},
+				Sort:   repository.RelevanceSort,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Users) != 0 {
+				t.Errorf("search from tenant B found %d of tenant A's users", len(page.Users))
+			}
+
+			count, err := b.Users.Count(ctxB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			matching, err := b.Users.CountMatching(ctxB, repository.UserFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 1 || matching != 1 {
+				t.Errorf("tenant B counts %d and %d users, want 1", count, matching)
+			}
+		})
+
+		t.Run("writes", func(t *testing.T) {
+			hijacked := *alice
+			hijacked.FirstName = "Mallory"
+			var conflict *repository.VersionConflictError
+			if err := b.Users.Update(ctxB, &hijacked); !errors.As(err, &conflict) {
+				t.Errorf("Update from tenant B returned %v, want a version conflict", err)
+			}
+			if err := b.Users.Delete(ctxB, alice.ID); err != nil {
+				t.Errorf("Delete from tenant B: %v", err)
+			}
+
+			stored, err := b.Users.GetByID(ctxA, alice.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored == nil {
+				t.Fatal("Delete from tenant B removed tenant A's user")
+			}
+			if stored.FirstName == "Mallory" {
+				t.Error("Update from tenant B changed tenant A's user")
+			}
+		})
+
+		t.Run("same email and username in another tenant", func(t *testing.T) {
+			twin := b.CreateUser(t, ctxB, "alice", "alice@example.com")
+			if twin.ID == alice.ID {
+				t.Error("tenant B's alice reused tenant A's ID")
+			}
+		})
+
+		t.Run("no tenant", func(t *testing.T) {
+			if _, err := b.Users.GetByID(context.Background(), alice.ID); !errors.Is(err, repository.ErrNoTenant) {
+				t.Errorf("GetByID without a tenant returned %v, want ErrNoTenant", err)
+			}
+			if _, err := b.Users.ListPage(context.Background(), repository.UserPageQuery{Limit: 100}); !errors.Is(err, repository.ErrNoTenant) {
+				t.Errorf("ListPage without a tenant returned %v, want ErrNoTenant", err)
+			}
+		})
+	})
+}
+
+func checkOnlyBob(t *testing.T, method string, users []*entity.User) {
+	t.Helper()
+	if len(users) != 1 || users[0].Username.String() != "bob" {
+		t.Errorf("%s from tenant B returned %d users, want only bob", method, len(users))
+	}
+}
@@ -0,0 +1,172 @@
+package repotest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/interface/repository/repotest"
+)
+
+var errAbort = errors.New("abort")
+
+// exists reports whether a user with email is stored in the tenant of ctx.
+func exists(t *testing.T, b repotest.Backend, ctx context.Context, email string) bool {
+	t.Helper()
+	parsed, err := entity.NewEmail(email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := b.Users.GetByEmail(ctx, parsed)
+	if err != nil {
+		t.Fatalf("get %s: %v", email, err)
+	}
+	return user != nil
+}
+
+func TestWithinTx(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, b repotest.Backend) {
+		ctx := b.NewTenant(t, "tx")
+
+		t.Run("commit", func(t *testing.T) {
+			err := b.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+				b.CreateUser(t, ctx, "committed", "committed@example.com")
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !exists(t, b, ctx, "committed@example.com") {
+				t.Error("committed user is missing")
+			}
+		})
+
+		t.Run("rollback on error", func(t *testing.T) {
+			err := b.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+				b.CreateUser(t, ctx, "failed", "failed@example.com")
+				return errAbort
+			})
+			if !errors.Is(err, errAbort) {
+				t.Fatalf("got %v, want the error of fn", err)
+			}
+			if exists(t, b, ctx, "failed@example.com") {
+				t.Error("user of a failed transaction was kept")
+			}
+		})
+
+		t.Run("rollback on panic", func(t *testing.T) {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Error("panic was not propagated")
+					}
+				}()
+				b.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+					b.CreateUser(t, ctx, "panicked", "panicked@example.com")
+					panic("boom")
+				})
+			}()
+			if exists(t, b, ctx, "panicked@example.com") {
+				t.Error("user of a panicking transaction was kept")
+			}
+		})
+
+		t.Run("failed nested call rolls back only itself", func(t *testing.T) {
+			err := b.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+				b.CreateUser(t, ctx, "outer", "outer@example.com")
+				inner := b.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+					b.CreateUser(t, ctx, "inner", "inner@example.com")
+					return errAbort
+				})
+				if !errors.Is(inner, errAbort) {
+					t.Errorf("nested call returned %v, want the error of its fn", inner)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !exists(t, b, ctx, "outer@example.com") {
+				t.Error("outer user is missing after the nested call failed")
+			}
+			if exists(t, b, ctx, "inner@example.com") {
+				t.Error("user of the failed nested call was kept")
+			}
+		})
+
+		t.Run("failed outer call rolls back nested calls", func(t *testing.T) {
+			err := b.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+				b.CreateUser(t, ctx, "outer2", "outer2@example.com")
+				if err := b.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+					b.CreateUser(t, ctx, "inner2", "inner2@example.com")
+					return nil
+				}); err != nil {
+					t.Fatal(err)
+				}
+				return errAbort
+			})
+			if !errors.Is(err, errAbort) {
+				t.Fatalf("got %v, want the error of fn", err)
+			}
+			if exists(t, b, ctx, "outer2@example.com") || exists(t, b, ctx, "inner2@example.com") {
+				t.Error("users of a failed transaction were kept")
+			}
+		})
+	})
+}
+
+func TestUpsertBatch(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, b repotest.Backend) {
+		ctx := b.NewTenant(t, "upsert")
+		existing := b.CreateUser(t, ctx, "existing", "existing@example.com")
+		b.CreateUser(t, ctx, "taken", "taken@example.com")
+
+		update, err := entity.NewUser("renamed", "Existing@Example.com", "An0ther-Secret-Pass!")
+		if err != nil {
+			t.Fatal(err)
+		}
+		update.UpdateProfile("Ada", "Lovelace", entity.PhoneNumber{}, "")
+		update.Role = entity.RoleAdmin
+		update.Status = entity.StatusBanned
+		fresh, err := entity.NewUser("fresh", "fresh@example.com", "Sup3r-Secret-Pass!")
+		if err != nil {
+			t.Fatal(err)
+		}
+		clash, err := entity.NewUser("taken", "clash@example.com", "Sup3r-Secret-Pass!")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errs, err := b.Users.UpsertBatch(ctx, []*entity.User{update, fresh, clash})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if errs[0] != nil || errs[1] != nil {
+			t.Fatalf("got errors %v, want the update and the insert to succeed", errs)
+		}
+		if errs[2] == nil {
+			t.Error("username clash was not reported")
+		}
+
+		stored, err := b.Users.GetByID(ctx, existing.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if update.ID != existing.ID {
+			t.Errorf("update got ID %s, want the existing account's %s", update.ID, existing.ID)
+		}
+		if stored.Username.String() != "renamed" || stored.FirstName != "Ada" {
+			t.Errorf("profile was not updated: %+v", stored)
+		}
+		if stored.Password != existing.Password || stored.Role != entity.RoleUser || stored.Status != entity.StatusActive {
+			t.Error("upsert changed the password, role or status of an existing account")
+		}
+		if !exists(t, b, ctx, "fresh@example.com") {
+			t.Error("new user was not inserted")
+		}
+		if exists(t, b, ctx, "clash@example.com") {
+			t.Error("clashing user was inserted")
+		}
+	})
+}
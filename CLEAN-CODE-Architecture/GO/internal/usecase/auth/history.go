@@ -0,0 +1,133 @@
+package auth
+
+// LoginHistory records every login attempt and flags unusual ones.
+// - Failed attempts are recorded with the reason, also for unknown
+//   accounts, so attacks on an account show up in its history.
+// - A successful login is compared with the user's earlier successful
+//   logins: an unseen device fingerprint or IP range flags it. The first
+//   login of an account is never flagged, as there is nothing to compare.
+// - Flagged logins are reported through the service.LoginNotifier port.
+//   Notifying happens in the background and never fails the login.
+// - Writing the history never fails a login either; errors are logged.
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+)
+
+// DefaultLoginHistoryLimit and MaxLoginHistoryLimit bound a history page.
+const (
+	DefaultLoginHistoryLimit = 20
+	MaxLoginHistoryLimit     = 100
+)
+
+// LoginHistory records login attempts and notifies users of risky ones.
+type LoginHistory struct {
+	attempts repository.LoginAttemptRepository
+	devices  service.DeviceDetector
+	locator  service.GeoLocator
+	notifier service.LoginNotifier
+}
+
+// NewLoginHistory creates a LoginHistory. locator may be nil to record no
+// locations, and notifier nil to only flag risky logins.
+func NewLoginHistory(attempts repository.LoginAttemptRepository, devices service.DeviceDetector, locator service.GeoLocator, notifier service.LoginNotifier) *LoginHistory {
+	return &LoginHistory{attempts: attempts, devices: devices, locator: locator, notifier: notifier}
+}
+
+// LoginHistoryPage is a page of login attempts, newest first. Next is the
+// Before of the following page, or "" on the last page.
+type LoginHistoryPage struct {
+	Attempts []*entity.LoginAttempt
+	Next     entity.LoginAttemptID
+}
+
+// List returns a page of user's login attempts before the attempt before,
+// or the newest when before is "".
+func (h *LoginHistory) List(ctx context.Context, user entity.UserID, before entity.LoginAttemptID, limit int) (*LoginHistoryPage, error) {
+	if limit <= 0 {
+		limit = DefaultLoginHistoryLimit
+	}
+	limit = min(limit, MaxLoginHistoryLimit)
+
+	// One extra row tells whether another page exists
+	attempts, err := h.attempts.List(ctx, repository.LoginAttemptQuery{UserID: user, Before: before, Limit: limit + 1})
+	if err != nil {
+		return nil, err
+	}
+	page := &LoginHistoryPage{Attempts: attempts}
+	if len(attempts) > limit {
+		page.Attempts = attempts[:limit]
+		page.Next = page.Attempts[limit-1].ID
+	}
+	return page, nil
+}
+
+// recordFailure records a failed attempt to sign in as identifier; user is
+// nil when no account matched.
+func (h *LoginHistory) recordFailure(ctx context.Context, identifier string, user *entity.User, client ClientInfo, reason string) {
+	attempt := h.newAttempt(user, identifier, client)
+	attempt.Fail(reason)
+	if err := h.attempts.Create(ctx, attempt); err != nil {
+		log.Printf("recording failed login attempt failed: %v", err)
+	}
+}
+
+// assess starts the record of user's successful login from client and
+// flags what is unfamiliar about it.
+func (h *LoginHistory) assess(ctx context.Context, user *entity.User, client ClientInfo) *entity.LoginAttempt {
+	attempt := h.newAttempt(user, user.Email.String(), client)
+	familiarity, err := h.attempts.Familiarity(ctx, user.ID, attempt.Fingerprint, attempt.IPRange)
+	if err != nil {
+		log.Printf("checking login history of user %s failed: %v", user.ID, err)
+		return attempt
+	}
+	if !familiarity.PreviousLogins {
+		return attempt
+	}
+	if !familiarity.KnownDevice {
+		attempt.RiskFlags = append(attempt.RiskFlags, entity.RiskNewDevice)
+	}
+	if !familiarity.KnownIPRange && attempt.IPRange != "" {
+		attempt.RiskFlags = append(attempt.RiskFlags, entity.RiskNewIPRange)
+	}
+	return attempt
+}
+
+// recordSuccess stores attempt, which started session, and notifies the
+// user when it was flagged.
+func (h *LoginHistory) recordSuccess(ctx context.Context, user *entity.User, attempt *entity.LoginAttempt, session entity.SessionID) {
+	attempt.Succeed(session)
+	if err := h.attempts.Create(ctx, attempt); err != nil {
+		log.Printf("recording login of user %s failed: %v", user.ID, err)
+	}
+	if !attempt.Risky() || h.notifier == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	go func() {
+		defer cancel()
+		if err := h.notifier.NotifyLogin(ctx, user, attempt); err != nil {
+			log.Printf("notifying user %s of login %s failed: %v", user.ID, attempt.ID, err)
+		}
+	}()
+}
+
+func (h *LoginHistory) newAttempt(user *entity.User, identifier string, client ClientInfo) *entity.LoginAttempt {
+	device := h.devices.Detect(client.UserAgent)
+	return entity.NewLoginAttempt(user, identifier, device, locate(h.locator, client.IP), client.UserAgent, client.IP, client.AcceptLanguage)
+}
+
+// locate looks ip up when a locator is configured.
+func locate(locator service.GeoLocator, ip string) entity.Location {
+	if locator == nil {
+		return entity.Location{}
+	}
+	return locator.Locate(ip)
+}
@@ -0,0 +1,135 @@
+package auth
+
+// ImpersonationUseCase lets admins act as a user to reproduce what they see.
+// - Every impersonation is recorded with its reason, start and end; the
+//   records are the audit trail and are never deleted.
+// - The token names the admin in its act claim and expires with the
+//   impersonation. Ending the impersonation revokes it at once, because
+//   ActiveAccountTokens checks the record on every request.
+// - Admins cannot be impersonated, so impersonation never grants more than
+//   the admin already has.
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+)
+
+// DefaultImpersonationTTL is how long an impersonation lasts unless ended.
+const DefaultImpersonationTTL = 15 * time.Minute
+
+var (
+	// ErrImpersonationNotFound is returned for unknown impersonation IDs.
+	ErrImpersonationNotFound = errors.New("impersonation not found")
+	// ErrCannotImpersonate is returned when the target is the admin
+	// themselves or another admin.
+	ErrCannotImpersonate = errors.New("this account cannot be impersonated")
+	// ErrImpersonationForbidden is returned for actions an impersonating
+	// admin may not take on the user's behalf.
+	ErrImpersonationForbidden = errors.New("not allowed while impersonating")
+)
+
+// ImpersonationUseCase starts, ends and lists impersonations.
+type ImpersonationUseCase struct {
+	users          repository.UserRepository
+	impersonations repository.ImpersonationRepository
+	tokens         service.TokenService
+	ttl            time.Duration
+}
+
+// NewImpersonationUseCase creates an ImpersonationUseCase. A ttl of zero
+// means DefaultImpersonationTTL.
+func NewImpersonationUseCase(users repository.UserRepository, impersonations repository.ImpersonationRepository, tokens service.TokenService, ttl time.Duration) *ImpersonationUseCase {
+	if ttl <= 0 {
+		ttl = DefaultImpersonationTTL
+	}
+	return &ImpersonationUseCase{
+		users:          users,
+		impersonations: impersonations,
+		tokens:         tokens,
+		ttl:            ttl,
+	}
+}
+
+// StartedImpersonation is the record of a new impersonation and the token
+// to act as the user with.
+type StartedImpersonation struct {
+	Impersonation *entity.Impersonation
+	Token         string
+	Claims        service.AccessClaims
+}
+
+// Start records that actor impersonates target for reason and issues the
+// impersonation token.
+//
+// Errors:
+//   - ErrUserNotFound when target does not exist
+//   - ErrCannotImpersonate for the actor themselves and for admins
+//   - the errors of entity.User.CheckActive when target cannot sign in
+//   - *entity.ValidationError when the reason is missing
+func (uc *ImpersonationUseCase) Start(ctx context.Context, actor, target entity.UserID, reason string) (*StartedImpersonation, error) {
+	if actor == target {
+		return nil, ErrCannotImpersonate
+	}
+	user, err := uc.users.GetByID(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Role == entity.RoleAdmin {
+		return nil, ErrCannotImpersonate
+	}
+	if err := user.CheckActive(time.Now()); err != nil {
+		return nil, err
+	}
+
+	impersonation, err := entity.NewImpersonation(actor, target, reason, uc.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.impersonations.Create(ctx, impersonation); err != nil {
+		return nil, err
+	}
+	token, claims, err := uc.tokens.IssueImpersonationToken(user, impersonation)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("impersonation %s started: admin %s as user %s", impersonation.ID, actor, target)
+	return &StartedImpersonation{Impersonation: impersonation, Token: token, Claims: claims}, nil
+}
+
+// End stops impersonation id on behalf of by, the impersonating admin or
+// another admin. Its token stops working.
+//
+// Errors: ErrImpersonationNotFound, and entity.ErrImpersonationEnded when it
+// already ended or expired.
+func (uc *ImpersonationUseCase) End(ctx context.Context, id entity.ImpersonationID, by entity.UserID) (*entity.Impersonation, error) {
+	impersonation, err := uc.impersonations.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if impersonation == nil {
+		return nil, ErrImpersonationNotFound
+	}
+	if err := impersonation.End(by, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := uc.impersonations.Update(ctx, impersonation); err != nil {
+		return nil, err
+	}
+	log.Printf("impersonation %s ended by %s", impersonation.ID, by)
+	return impersonation, nil
+}
+
+// List returns the tenant's impersonations matching filter, most recent
+// first.
+func (uc *ImpersonationUseCase) List(ctx context.Context, filter repository.ImpersonationFilter) ([]*entity.Impersonation, error) {
+	return uc.impersonations.List(ctx, filter)
+}
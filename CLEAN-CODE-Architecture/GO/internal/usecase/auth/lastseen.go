@@ -0,0 +1,100 @@
+package auth
+
+// LastSeenTracker keeps session last-seen times current without a database
+// write per request.
+// - Requests only record the time in memory; Run writes the latest time of
+//   each session in one batch per interval.
+// - A crash loses at most one interval of activity, which only makes
+//   last-seen times slightly older than they should be.
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// DefaultLastSeenInterval is how often pending last-seen times are written.
+const DefaultLastSeenInterval = time.Minute
+
+// LastSeenTracker buffers session activity and writes it periodically.
+type LastSeenTracker struct {
+	sessions repository.SessionRepository
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[entity.SessionID]time.Time
+}
+
+// NewLastSeenTracker creates a LastSeenTracker. An interval of zero means
+// DefaultLastSeenInterval.
+func NewLastSeenTracker(sessions repository.SessionRepository, interval time.Duration) *LastSeenTracker {
+	if interval <= 0 {
+		interval = DefaultLastSeenInterval
+	}
+	return &LastSeenTracker{
+		sessions: sessions,
+		interval: interval,
+		pending:  make(map[entity.SessionID]time.Time),
+	}
+}
+
+// Seen records activity on session at.
+func (t *LastSeenTracker) Seen(session entity.SessionID, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if current, ok := t.pending[session]; !ok || current.Before(at) {
+		t.pending[session] = at
+	}
+}
+
+// LastSeen returns the activity on session that has not been written yet.
+func (t *LastSeenTracker) LastSeen(session entity.SessionID) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	at, ok := t.pending[session]
+	return at, ok
+}
+
+// Flush writes the pending last-seen times. When the write fails they are
+// kept for the next flush, unless newer activity replaced them.
+func (t *LastSeenTracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[entity.SessionID]time.Time, len(batch))
+	t.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := t.sessions.TouchLastSeen(ctx, batch)
+	if err != nil {
+		for session, at := range batch {
+			t.Seen(session, at)
+		}
+	}
+	return err
+}
+
+// Run flushes every interval until ctx is done, then flushes once more so
+// a graceful shutdown loses nothing.
+func (t *LastSeenTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil {
+				log.Printf("writing session last-seen times failed: %v", err)
+			}
+		case <-ctx.Done():
+			if err := t.Flush(context.Background()); err != nil {
+				log.Printf("writing session last-seen times failed: %v", err)
+			}
+			return
+		}
+	}
+}
@@ -3,24 +3,144 @@ package auth
 // This use case follows Clean Code Architecture principles:
 // - Business logic (authentication) is separated from infrastructure (database, hashing).
 // - The repository is injected (Dependency Injection), so this code is easy to test and not tied to a specific DB.
-// - The Repository Pattern is used: repo.FindByEmail abstracts data access.
-// - The Hash utility is injected, so password logic is also decoupled.
+// - The Repository Pattern is used: repo.GetByEmail abstracts data access.
+// - The PasswordHasher is injected, so password logic is also decoupled.
 // This makes the code modular, testable, and easy to maintain.
+//
+// Login must not reveal which emails are registered: unknown accounts and
+// wrong passwords return the same error after the same amount of hashing.
 
 import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
 	"auth-module/internal/domain/repository"
-	"auth-module/pkg/hash"
+	"auth-module/internal/domain/service"
 	"context"
 	"errors"
+	"log"
+	"time"
 )
 
-func Login(ctx context.Context, repo repository.UserRepository, email, password string) (bool, error) {
-	user, err := repo.GetByEmail(ctx, email)
-	if err != nil || user == nil {
-		return false, errors.New("user not found")
+// ErrInvalidCredentials is the only error returned for a failed login,
+// whether the account is unknown or the password is wrong.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// LoginUseCase authenticates users by email and password.
+type LoginUseCase struct {
+	repo      repository.UserRepository
+	hasher    service.PasswordHasher
+	history   *LoginHistory
+	countries *policy.CountryPolicy
+}
+
+// NewLoginUseCase creates a LoginUseCase. history, when non-nil, records
+// failed attempts; successful ones are recorded when their session starts.
+// countries, when non-nil, restricts where logins may come from.
+func NewLoginUseCase(repo repository.UserRepository, hasher service.PasswordHasher, history *LoginHistory, countries *policy.CountryPolicy) *LoginUseCase {
+	return &LoginUseCase{
+		repo:      repo,
+		hasher:    hasher,
+		history:   history,
+		countries: countries,
+	}
+}
+
+// Login verifies the credentials and returns the authenticated user.
+// Unknown emails are checked against a dummy hash so they cost as much as a
+// wrong password, and both fail with ErrInvalidCredentials. Hasher errors
+// such as service.ErrHasherOverloaded are returned unchanged.
+//
+// Accounts that are not active fail with entity.ErrAccountSuspended,
+// entity.ErrAccountLocked or entity.ErrAccountBanned. The status is only
+// revealed once the password has been verified.
+//
+// A successful login records the last-login time. When the stored hash uses
+// an outdated algorithm or cost, it is upgraded in the same write while the
+// plaintext is available. Neither failing fails the login.
+//
+// Failed attempts from client are recorded in the login history. Clients
+// in a country the country policy refuses fail with
+// policy.ErrCountryNotAllowed before the password is checked, whether the
+// account exists or not.
+func (uc *LoginUseCase) Login(ctx context.Context, email, password string, client ClientInfo) (*entity.User, error) {
+	user, err := uc.lookup(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkCountry(client); err != nil {
+		uc.recordFailure(ctx, email, user, client, entity.LoginFailureCountryNotAllowed)
+		return nil, err
+	}
+	if user == nil {
+		if err := uc.hasher.CompareDummy(ctx, dummyIdentifier(email), password); err != nil {
+			return nil, err
+		}
+		uc.recordFailure(ctx, email, nil, client, entity.LoginFailureInvalidCredentials)
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := uc.hasher.Compare(ctx, password, user.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		uc.recordFailure(ctx, email, user, client, entity.LoginFailureInvalidCredentials)
+		return nil, ErrInvalidCredentials
+	}
+	if err := user.CheckActive(time.Now()); err != nil {
+		uc.recordFailure(ctx, email, user, client, "account_"+string(user.EffectiveStatus(time.Now())))
+		return nil, err
+	}
+
+	user.RecordLogin(time.Now())
+	if uc.hasher.NeedsRehash(user.Password) {
+		uc.rehash(ctx, user, password)
+	}
+	if err := uc.repo.Update(ctx, user); err != nil {
+		log.Printf("recording login for user %s failed: %v", user.ID, err)
 	}
-	if !hash.CheckPasswordHash(password, user.Password) {
-		return false, errors.New("invalid password")
+	return user, nil
+}
+
+func (uc *LoginUseCase) checkCountry(client ClientInfo) error {
+	if uc.countries == nil {
+		return nil
+	}
+	return uc.countries.Check(client.IP)
+}
+
+func (uc *LoginUseCase) recordFailure(ctx context.Context, email string, user *entity.User, client ClientInfo, reason string) {
+	if uc.history != nil {
+		uc.history.recordFailure(ctx, email, user, client, reason)
+	}
+}
+
+// lookup finds the account for email. A malformed email is treated like an
+// unknown one so it follows the same dummy-hash path.
+func (uc *LoginUseCase) lookup(ctx context.Context, email string) (*entity.User, error) {
+	parsedEmail, err := entity.NewEmail(email)
+	if err != nil {
+		return nil, nil
+	}
+	return uc.repo.GetByEmail(ctx, parsedEmail)
+}
+
+// dummyIdentifier normalizes email the way lookup does, so spellings of one
+// address share a dummy hash just as they would share an account.
+func dummyIdentifier(email string) string {
+	if parsed, err := entity.NewEmail(email); err == nil {
+		return parsed.String()
+	}
+	return email
+}
+
+// rehash replaces the user's hash with one from the preferred algorithm. The
+// caller persists the user.
+func (uc *LoginUseCase) rehash(ctx context.Context, user *entity.User, password string) {
+	hashed, err := uc.hasher.Hash(ctx, password)
+	if err != nil {
+		log.Printf("rehash for user %s failed: %v", user.ID, err)
+		return
 	}
-	return true, nil
+	user.SetPasswordHash(hashed)
 }
@@ -0,0 +1,136 @@
+package auth_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/infrastructure/useragent"
+	"auth-module/internal/interface/repository/repotest"
+	"auth-module/internal/usecase/auth"
+	"auth-module/pkg/hash"
+)
+
+const testPassword = "Sup3r-Secret-Pass!"
+
+// TestLoginTimingDoesNotRevealAccounts measures logins for unknown emails
+// and for known emails with a wrong password, interleaved so all see the
+// same machine load. One known account has a current hash and one a much
+// slower legacy hash. Every unknown email must take as long as one of the
+// two accounts, within maxMedianSkew of its median, and the unknown emails
+// must cover both costs: skipping the dummy hash makes them faster than
+// either, and always using the preferred cost makes them all as fast as the
+// current account, which singles out unmigrated ones.
+func TestLoginTimingDoesNotRevealAccounts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test skipped in short mode")
+	}
+	if raceEnabled {
+		t.Skip("timing test skipped under the race detector; CI runs it separately")
+	}
+	const (
+		samples       = 15
+		unknownEmails = 16
+		maxMedianSkew = 0.25
+	)
+
+	b := repotest.Memory()
+	ctx := b.NewTenant(t, "timing")
+	// Costs high enough that hashing dominates the repository calls, with
+	// the legacy hash, made before the cost was raised, four times slower
+	hasher := hash.NewHasher(hash.NewBcrypt(6))
+	legacy := hash.NewBcrypt(8)
+
+	registration, err := policy.NewRegistrationPolicy(policy.RegistrationPolicyConfig{Mode: policy.RegistrationOpen}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	register := auth.NewRegisterUseCase(b.Users, hasher,
+		policy.NewPasswordPolicy(policy.DefaultPasswordPolicyConfig(), nil), registration, auth.RegisterOptions{})
+	registered, err := register.Register(ctx, auth.RegisterInput{Username: "current", Email: "current@example.com", Password: testPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmigrated, err := entity.NewUser("legacy", "legacy@example.com", testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyHash, err := legacy.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmigrated.SetPasswordHash(legacyHash)
+	if _, err := b.Users.Create(ctx, unmigrated); err != nil {
+		t.Fatal(err)
+	}
+
+	// Half the stored hashes are legacy ones
+	for i := 0; i < 100; i++ {
+		hasher.Observe(registered.Password)
+		hasher.Observe(legacyHash)
+	}
+
+	history := auth.NewLoginHistory(b.LoginAttempts, useragent.NewDetector(), nil, nil)
+	login := auth.NewLoginUseCase(b.Users, hasher, history, nil)
+	client := auth.ClientInfo{IP: "203.0.113.7", UserAgent: "timing-test"}
+
+	measure := func(email string) time.Duration {
+		start := time.Now()
+		_, err := login.Login(ctx, email, "Wr0ng-Secret-Pass!", client)
+		elapsed := time.Since(start)
+		if !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Fatalf("login as %s: got %v, want ErrInvalidCredentials", email, err)
+		}
+		return elapsed
+	}
+
+	unknown := make([][]time.Duration, unknownEmails)
+	var currentSamples, legacySamples []time.Duration
+	for i := 0; i < samples; i++ {
+		for n := range unknown {
+			unknown[n] = append(unknown[n], measure(fmt.Sprintf("unknown-%d@example.com", n)))
+		}
+		currentSamples = append(currentSamples, measure("current@example.com"))
+		legacySamples = append(legacySamples, measure("legacy@example.com"))
+	}
+
+	c, l := median(currentSamples), median(legacySamples)
+	t.Logf("median wrong password: current hash %v, legacy hash %v", c, l)
+	var atCurrent, atLegacy int
+	for n, durations := range unknown {
+		u := median(durations)
+		switch {
+		case skew(u, c) <= maxMedianSkew:
+			atCurrent++
+		case skew(u, l) <= maxMedianSkew:
+			atLegacy++
+		default:
+			t.Errorf("median login time for unknown-%d@example.com (%v) differs by more than %.0f%% from both the current (%v) and the legacy account (%v)",
+				n, u, maxMedianSkew*100, c, l)
+		}
+	}
+	t.Logf("unknown emails: %d as slow as the current hash, %d as the legacy one", atCurrent, atLegacy)
+	if atCurrent == 0 || atLegacy == 0 {
+		t.Errorf("%d unknown emails took the current cost and %d the legacy cost, want both", atCurrent, atLegacy)
+	}
+}
+
+// skew is the relative difference between a and b.
+func skew(a, b time.Duration) float64 {
+	s := float64(a-b) / float64(max(a, b))
+	if s < 0 {
+		return -s
+	}
+	return s
+}
+
+func median(samples []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
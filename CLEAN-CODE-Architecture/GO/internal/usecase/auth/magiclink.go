@@ -0,0 +1,367 @@
+package auth
+
+// MagicLinkUseCase signs users in with a link sent by email instead of a
+// password.
+// - The link token is random and only its SHA-256 is stored. A link
+//   expires after a few minutes and works once; the first use wins, and
+//   later ones are refused and recorded in the login history.
+// - Requesting a link never reveals whether the email is registered: the
+//   result is the same for unknown emails, stopped accounts and throttled
+//   requests, and everything past checking the email's syntax (the account
+//   lookup, the throttle, storing the link and sending it) happens in the
+//   background, so the response takes as long either way.
+// - Each account gets a limited number of links per window, so the
+//   endpoint cannot be used to flood an inbox. The limit is enforced by
+//   the repository in the same step that stores a link.
+// - Requests are throttled per client IP and per email before any
+//   background work, and each tenant has its own bound on pending work, so
+//   a flood of requests for unknown emails cannot crowd out real users or
+//   other tenants.
+// - Signing in with a link starts a session like a password login, after
+//   the same account status and country checks.
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+)
+
+// Magic link defaults: a link lasts DefaultMagicLinkTTL, an account gets at
+// most DefaultMagicLinkLimit links per DefaultMagicLinkWindow, and a client
+// IP may make DefaultMagicLinkIPLimit requests per window.
+const (
+	DefaultMagicLinkTTL     = 15 * time.Minute
+	DefaultMagicLinkLimit   = 5
+	DefaultMagicLinkWindow  = time.Hour
+	DefaultMagicLinkIPLimit = 20
+)
+
+// maxPendingMagicLinks bounds the link requests of one tenant being
+// processed in the background; requests beyond it are dropped rather than
+// queued.
+const maxPendingMagicLinks = 16
+
+// ErrInvalidMagicLink is returned for link tokens that match no link.
+var ErrInvalidMagicLink = errors.New("invalid magic link")
+
+// MagicLinkOptions configure magic links.
+type MagicLinkOptions struct {
+	// TTL is how long links stay valid; DefaultMagicLinkTTL if zero.
+	TTL time.Duration
+	// Limit is how many links an account gets per Window;
+	// DefaultMagicLinkLimit and DefaultMagicLinkWindow if zero. Requests
+	// for one email beyond Limit are dropped before the account is looked up.
+	Limit  int
+	Window time.Duration
+	// IPLimit is how many requests a client IP may make per Window;
+	// DefaultMagicLinkIPLimit if zero.
+	IPLimit int
+	// VerifyURL is the link sent to users, with "{token}" replaced by the
+	// link token. It usually points at a frontend page that calls
+	// /login/magic-link/verify.
+	VerifyURL string
+}
+
+// MagicLinkUseCase issues magic links and signs users in with them.
+type MagicLinkUseCase struct {
+	links     repository.MagicLinkRepository
+	users     repository.UserRepository
+	sessions  *SessionUseCase
+	mailer    service.Mailer
+	history   *LoginHistory
+	countries *policy.CountryPolicy
+	options   MagicLinkOptions
+
+	byIP    *requestThrottle
+	byEmail *requestThrottle
+	// pending counts each tenant's requests running in the background.
+	mu      sync.Mutex
+	pending map[entity.TenantID]int
+}
+
+// NewMagicLinkUseCase creates a MagicLinkUseCase. history, when non-nil,
+// records failed sign-ins; countries, when non-nil, restricts where links
+// may be used from.
+func NewMagicLinkUseCase(links repository.MagicLinkRepository, users repository.UserRepository, sessions *SessionUseCase, mailer service.Mailer, history *LoginHistory, countries *policy.CountryPolicy, options MagicLinkOptions) *MagicLinkUseCase {
+	if options.TTL <= 0 {
+		options.TTL = DefaultMagicLinkTTL
+	}
+	if options.Limit <= 0 {
+		options.Limit = DefaultMagicLinkLimit
+	}
+	if options.Window <= 0 {
+		options.Window = DefaultMagicLinkWindow
+	}
+	if options.IPLimit <= 0 {
+		options.IPLimit = DefaultMagicLinkIPLimit
+	}
+	return &MagicLinkUseCase{
+		links:     links,
+		users:     users,
+		sessions:  sessions,
+		mailer:    mailer,
+		history:   history,
+		countries: countries,
+		options:   options,
+		byIP:      newRequestThrottle(options.IPLimit, options.Window),
+		byEmail:   newRequestThrottle(options.Limit, options.Window),
+		pending:   make(map[entity.TenantID]int),
+	}
+}
+
+// Request emails a sign-in link to the account registered with email in
+// the context's tenant. Nothing is sent for unknown or malformed emails,
+// accounts that cannot sign in, and accounts that reached the limit of
+// links, nor for requests over the client IP or email throttle. Only the
+// email's syntax and the throttles are checked before Request returns; the
+// rest runs in the background and its failures are logged, so callers
+// cannot tell these cases apart, not even by timing.
+func (uc *MagicLinkUseCase) Request(ctx context.Context, email string, client ClientInfo) {
+	parsedEmail, err := entity.NewEmail(email)
+	if err != nil {
+		return
+	}
+	tenant, err := repository.RequireTenant(ctx)
+	if err != nil {
+		log.Printf("magic link request failed: %v", err)
+		return
+	}
+
+	now := time.Now()
+	if !uc.byIP.allow(clientKey(client.IP), now) {
+		log.Printf("magic link requests from %s throttled", client.IP)
+		return
+	}
+	emailHash := sha256.Sum256([]byte(parsedEmail.String()))
+	if !uc.byEmail.allow(string(tenant)+"/"+hex.EncodeToString(emailHash[:]), now) {
+		return
+	}
+	if !uc.acquire(tenant) {
+		log.Printf("magic link request dropped: %d requests pending for tenant %s", maxPendingMagicLinks, tenant)
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer uc.release(tenant)
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		if err := uc.issue(ctx, parsedEmail, client); err != nil {
+			log.Printf("magic link request failed: %v", err)
+		}
+	}()
+}
+
+// acquire takes one of tenant's slots for background work, if one is free.
+func (uc *MagicLinkUseCase) acquire(tenant entity.TenantID) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.pending[tenant] >= maxPendingMagicLinks {
+		return false
+	}
+	uc.pending[tenant]++
+	return true
+}
+
+func (uc *MagicLinkUseCase) release(tenant entity.TenantID) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.pending[tenant]--; uc.pending[tenant] == 0 {
+		delete(uc.pending, tenant)
+	}
+}
+
+// issue stores and sends a link for the account with email, unless there
+// is none, it cannot sign in or it reached the limit of links.
+func (uc *MagicLinkUseCase) issue(ctx context.Context, email entity.Email, client ClientInfo) error {
+	user, err := uc.users.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if user == nil || user.CheckActive(now) != nil {
+		return nil
+	}
+
+	token, tokenHash, err := newMagicLinkToken()
+	if err != nil {
+		return err
+	}
+	link := entity.NewMagicLink(user, tokenHash, client.IP, uc.options.TTL)
+	created, err := uc.links.CreateWithinLimit(ctx, link, now.Add(-uc.options.Window), uc.options.Limit)
+	if err != nil {
+		return err
+	}
+	if !created {
+		log.Printf("magic link for user %s throttled", user.ID)
+		return nil
+	}
+	return uc.send(ctx, user, link, token)
+}
+
+// Verify signs in with the link for token and starts a session for client.
+// The token names the tenant, so ctx needs none.
+//
+// Errors:
+//   - ErrInvalidMagicLink for unknown tokens
+//   - entity.ErrMagicLinkExpired, entity.ErrMagicLinkUsed
+//   - policy.ErrCountryNotAllowed when the country policy refuses client
+//   - the errors of entity.User.CheckActive when the account cannot sign in
+func (uc *MagicLinkUseCase) Verify(ctx context.Context, token string, client ClientInfo) (*IssuedTokens, error) {
+	link, err := uc.links.GetByTokenHash(ctx, hashMagicLinkToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrInvalidMagicLink
+	}
+
+	ctx = repository.WithTenant(ctx, link.TenantID)
+	user, err := uc.users.GetByID(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidMagicLink
+	}
+
+	now := time.Now()
+	if err := link.Check(now); err != nil {
+		reason := entity.LoginFailureMagicLinkExpired
+		if errors.Is(err, entity.ErrMagicLinkUsed) {
+			reason = entity.LoginFailureMagicLinkUsed
+		}
+		uc.recordFailure(ctx, user, client, reason)
+		return nil, err
+	}
+	if uc.countries != nil {
+		if err := uc.countries.Check(client.IP); err != nil {
+			uc.recordFailure(ctx, user, client, entity.LoginFailureCountryNotAllowed)
+			return nil, err
+		}
+	}
+	if err := user.CheckActive(now); err != nil {
+		uc.recordFailure(ctx, user, client, "account_"+string(user.EffectiveStatus(now)))
+		return nil, err
+	}
+
+	used, err := uc.links.Use(ctx, link.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		// Another request with the same link got there first
+		uc.recordFailure(ctx, user, client, entity.LoginFailureMagicLinkUsed)
+		return nil, entity.ErrMagicLinkUsed
+	}
+
+	// Receiving the link proves the user owns the email
+	user.RecordLogin(now)
+	if !user.IsEmailVerified() {
+		user.MarkEmailVerified(now)
+	}
+	if err := uc.users.Update(ctx, user); err != nil {
+		log.Printf("recording login for user %s failed: %v", user.ID, err)
+	}
+	return uc.sessions.Start(ctx, user, client)
+}
+
+func (uc *MagicLinkUseCase) recordFailure(ctx context.Context, user *entity.User, client ClientInfo, reason string) {
+	if uc.history != nil {
+		uc.history.recordFailure(ctx, user.Email.String(), user, client, reason)
+	}
+}
+
+// send emails the link for token. The user can ask for another link if
+// delivery fails.
+func (uc *MagicLinkUseCase) send(ctx context.Context, user *entity.User, link *entity.MagicLink, token string) error {
+	msg := service.Message{
+		To:      user.Email.String(),
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Use this link to sign in:\n%s\n\n"+
+			"The link works once and expires on %s.\n"+
+			"If you did not ask to sign in, you can ignore this email.\n",
+			strings.ReplaceAll(uc.options.VerifyURL, "{token}", token),
+			link.ExpiresAt.UTC().Format("2 January 2006 15:04 MST")),
+	}
+	if err := uc.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send magic link %s: %w", link.ID, err)
+	}
+	return nil
+}
+
+// newMagicLinkToken returns a random link token and its stored hash.
+func newMagicLinkToken() (token, tokenHash string, err error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", "", fmt.Errorf("generate magic link token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b[:])
+	return token, hashMagicLinkToken(token), nil
+}
+
+func hashMagicLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestThrottle counts requests per key in fixed windows and refuses a
+// key's requests past limit until the window ends. All counts are dropped
+// together when a window ends, so memory is bounded by the keys seen in one
+// window.
+type requestThrottle struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	started time.Time
+	counts  map[string]int
+}
+
+func newRequestThrottle(limit int, window time.Duration) *requestThrottle {
+	return &requestThrottle{limit: limit, window: window, counts: make(map[string]int)}
+}
+
+// allow counts a request for key at now and reports whether it is within
+// the limit.
+func (t *requestThrottle) allow(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.started) >= t.window {
+		t.started = now
+		t.counts = make(map[string]int)
+	}
+	if t.counts[key] >= t.limit {
+		return false
+	}
+	t.counts[key]++
+	return true
+}
+
+// clientKey is the throttle key for ip. IPv6 clients usually hold a whole
+// /64, so they are counted by that prefix.
+func clientKey(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	if addr = addr.Unmap(); addr.Is4() {
+		return addr.String()
+	}
+	prefix, _ := addr.Prefix(64)
+	return prefix.String()
+}
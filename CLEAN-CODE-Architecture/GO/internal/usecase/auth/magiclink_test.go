@@ -0,0 +1,110 @@
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"auth-module/internal/domain/service"
+	"auth-module/internal/interface/repository/repotest"
+	"auth-module/internal/usecase/auth"
+)
+
+// recordingMailer records the recipients of sent messages. Messages to
+// addresses ending in hold wait until release is closed.
+type recordingMailer struct {
+	hold    string
+	release chan struct{}
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg service.Message) error {
+	if m.hold != "" && strings.HasSuffix(msg.To, m.hold) {
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg.To)
+	return nil
+}
+
+// waitForSent waits until n messages were sent and returns their recipients.
+func (m *recordingMailer) waitForSent(t *testing.T, n int) []string {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		m.mu.Lock()
+		sent := append([]string(nil), m.sent...)
+		m.mu.Unlock()
+		if len(sent) >= n {
+			return sent
+		}
+	}
+	t.Fatalf("timed out waiting for %d messages", n)
+	return nil
+}
+
+// TestMagicLinkRequestsAreThrottledPerIP spends a client's requests on
+// unknown emails; its next request for a real account must be dropped while
+// another client's goes through. IPv6 clients are counted per /64.
+func TestMagicLinkRequestsAreThrottledPerIP(t *testing.T) {
+	b := repotest.Memory()
+	ctx := b.NewTenant(t, "magic-links")
+	b.CreateUser(t, ctx, "known", "known@example.com")
+	mailer := &recordingMailer{}
+	uc := auth.NewMagicLinkUseCase(b.MagicLinks, b.Users, nil, mailer, nil, nil, auth.MagicLinkOptions{IPLimit: 3})
+
+	for i := 1; i <= 3; i++ {
+		uc.Request(ctx, fmt.Sprintf("unknown-%d@example.com", i), auth.ClientInfo{IP: fmt.Sprintf("2001:db8::%d", i)})
+	}
+	uc.Request(ctx, "known@example.com", auth.ClientInfo{IP: "2001:db8::4"})
+	uc.Request(ctx, "known@example.com", auth.ClientInfo{IP: "198.51.100.1"})
+
+	mailer.waitForSent(t, 1)
+	// Give a wrongly accepted request time to send as well
+	time.Sleep(50 * time.Millisecond)
+	if sent := mailer.waitForSent(t, 1); len(sent) != 1 {
+		t.Errorf("sent %d links, want only the one for the client under its limit", len(sent))
+	}
+}
+
+// TestMagicLinkPendingRequestsArePerTenant fills one tenant's background
+// work with requests whose mail never leaves; another tenant's request
+// must still be sent.
+func TestMagicLinkPendingRequestsArePerTenant(t *testing.T) {
+	const flood = 40
+
+	b := repotest.Memory()
+	ctxA := b.NewTenant(t, "tenant-a")
+	ctxB := b.NewTenant(t, "tenant-b")
+	for i := 0; i < flood; i++ {
+		b.CreateUser(t, ctxA, fmt.Sprintf("user-%d", i), fmt.Sprintf("user-%d@a.example", i))
+	}
+	b.CreateUser(t, ctxB, "bob", "bob@b.example")
+
+	mailer := &recordingMailer{hold: "@a.example", release: make(chan struct{})}
+	uc := auth.NewMagicLinkUseCase(b.MagicLinks, b.Users, nil, mailer, nil, nil, auth.MagicLinkOptions{})
+	for i := 0; i < flood; i++ {
+		uc.Request(ctxA, fmt.Sprintf("user-%d@a.example", i), auth.ClientInfo{IP: fmt.Sprintf("203.0.113.%d", i)})
+	}
+	uc.Request(ctxB, "bob@b.example", auth.ClientInfo{IP: "198.51.100.1"})
+
+	if sent := mailer.waitForSent(t, 1); sent[0] != "bob@b.example" {
+		t.Errorf("first link went to %s, want tenant B's bob", sent[0])
+	}
+	close(mailer.release)
+
+	mailer.waitForSent(t, 2)
+	time.Sleep(50 * time.Millisecond)
+	if sent := mailer.waitForSent(t, 2); len(sent) > flood {
+		t.Errorf("sent all %d links of the flood, want requests past tenant A's bound dropped", flood)
+	}
+}
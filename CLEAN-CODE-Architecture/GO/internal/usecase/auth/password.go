@@ -0,0 +1,97 @@
+package auth
+
+// This use case owns every way a password can be replaced.
+// - Password change and password reset run the same PasswordPolicy as
+//   registration, so the rules cannot drift between entry points.
+// - Hashing goes through the injected PasswordHasher.
+
+import (
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUserNotFound is returned when the target account does not exist.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidCurrentPassword is returned when a password change is not
+	// authorized by the account's current password.
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+)
+
+// PasswordUseCase changes and resets account passwords.
+type PasswordUseCase struct {
+	repo           repository.UserRepository
+	hasher         service.PasswordHasher
+	passwordPolicy *policy.PasswordPolicy
+}
+
+// NewPasswordUseCase creates a PasswordUseCase
+func NewPasswordUseCase(repo repository.UserRepository, hasher service.PasswordHasher, passwordPolicy *policy.PasswordPolicy) *PasswordUseCase {
+	return &PasswordUseCase{
+		repo:           repo,
+		hasher:         hasher,
+		passwordPolicy: passwordPolicy,
+	}
+}
+
+// ChangePassword replaces the password after verifying the current one.
+func (uc *PasswordUseCase) ChangePassword(ctx context.Context, id entity.UserID, currentPassword, newPassword string) error {
+	user, err := uc.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	ok, err := uc.hasher.Compare(ctx, currentPassword, user.Password)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCurrentPassword
+	}
+	return uc.setPassword(ctx, user, newPassword)
+}
+
+// ResetPassword replaces the password without the current one. Callers are
+// responsible for having authorized the reset (e.g. a verified reset token).
+func (uc *PasswordUseCase) ResetPassword(ctx context.Context, id entity.UserID, newPassword string) error {
+	user, err := uc.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	return uc.setPassword(ctx, user, newPassword)
+}
+
+func (uc *PasswordUseCase) load(ctx context.Context, id entity.UserID) (*entity.User, error) {
+	user, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (uc *PasswordUseCase) setPassword(ctx context.Context, user *entity.User, newPassword string) error {
+	if _, err := entity.NewPassword(newPassword); err != nil {
+		return entity.NewFieldError("password", err)
+	}
+	if err := uc.passwordPolicy.Validate(ctx, policy.PasswordCandidate{
+		Password: newPassword,
+		Username: user.Username.String(),
+		Email:    user.Email.String(),
+	}); err != nil {
+		return err
+	}
+
+	hashed, err := uc.hasher.Hash(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+	user.SetPasswordHash(hashed)
+
+	return uc.repo.Update(ctx, user)
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package auth_test
+
+// raceEnabled reports whether the tests run under the race detector, which
+// slows code unevenly enough to drown the differences timing tests look for.
+const raceEnabled = true
@@ -3,34 +3,108 @@ package auth
 // This use case follows Clean Code Architecture principles:
 // - Business logic (user registration) is separated from infrastructure (database, hashing).
 // - The repository is injected (Dependency Injection), so this code is easy to test and not tied to a specific DB.
-// - The Repository Pattern is used: repo.FindByEmail and repo.Create abstract data access.
-// - The Hash utility is injected, so password logic is also decoupled.
+// - The Repository Pattern is used: repo.Create abstracts data access.
+// - Uniqueness is enforced by the repository (unique indexes), not by a
+//   check-then-insert, so concurrent registrations cannot both succeed.
+// - The PasswordHasher is injected, so password logic is also decoupled.
+// - Who may register is decided by the RegistrationPolicy here, not in the
+//   handler, so every entry point that registers users respects it.
 // This makes the code modular, testable, and easy to maintain.
 
 import (
 	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
 	"auth-module/internal/domain/repository"
-	"auth-module/pkg/hash"
+	"auth-module/internal/domain/service"
 	"context"
 	"errors"
-	"time"
 )
 
-func Register(ctx context.Context, repo repository.UserRepository, user *entity.User) error {
-	// Check if user already exists
-	existing, _ := repo.GetByEmail(ctx, user.Email)
-	if existing != nil {
-		return errors.New("user already exists")
+// RegisterInput carries the raw registration data supplied by a client.
+type RegisterInput struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// RegisterOptions tune how registration reports its outcome.
+type RegisterOptions struct {
+	// ConcealExistingEmail makes a duplicate email indistinguishable from a
+	// successful registration, so /register cannot be used to probe which
+	// addresses have accounts. Username conflicts are still reported because
+	// usernames are public.
+	ConcealExistingEmail bool
+}
+
+// RegisterUseCase is the single entry point for creating user accounts.
+type RegisterUseCase struct {
+	repo           repository.UserRepository
+	hasher         service.PasswordHasher
+	passwordPolicy *policy.PasswordPolicy
+	registration   *policy.RegistrationPolicy
+	options        RegisterOptions
+}
+
+// NewRegisterUseCase creates a RegisterUseCase
+func NewRegisterUseCase(repo repository.UserRepository, hasher service.PasswordHasher, passwordPolicy *policy.PasswordPolicy, registration *policy.RegistrationPolicy, options RegisterOptions) *RegisterUseCase {
+	return &RegisterUseCase{
+		repo:           repo,
+		hasher:         hasher,
+		passwordPolicy: passwordPolicy,
+		registration:   registration,
+		options:        options,
 	}
-	// Hash the password
-	hashed, err := hash.HashPassword(user.Password)
+}
+
+// ConcealsExistingEmail reports whether responses must not distinguish a new
+// account from an already registered email.
+func (uc *RegisterUseCase) ConcealsExistingEmail() bool {
+	return uc.options.ConcealExistingEmail
+}
+
+// Register checks the registration policy, validates the input through
+// entity.NewUser and the password policy, hashes the password and stores
+// the user.
+//
+// Errors:
+//   - policy.ErrRegistrationClosed, policy.ErrInvitationRequired,
+//     policy.ErrEmailDomainNotAllowed, policy.ErrDisposableEmail when the
+//     registration policy refuses the account
+//   - *entity.ValidationError when any field breaks a domain rule
+//   - *policy.PasswordPolicyError when the password breaks the password policy
+//   - *repository.ConflictError when the email or username is already registered
+//
+// With ConcealExistingEmail, a taken email yields (nil, nil) instead of a
+// conflict. The password is hashed before the insert either way, so the two
+// outcomes also take the same time.
+func (uc *RegisterUseCase) Register(ctx context.Context, input RegisterInput) (*entity.User, error) {
+	if err := uc.registration.Check(policy.ChannelSelfService, input.Email); err != nil {
+		return nil, err
+	}
+
+	user, err := entity.NewUser(input.Username, input.Email, input.Password)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := uc.passwordPolicy.Validate(ctx, policy.PasswordCandidate{
+		Password: input.Password,
+		Username: user.Username.String(),
+		Email:    user.Email.String(),
+	}); err != nil {
+		return nil, err
+	}
+
+	hashed, err := uc.hasher.Hash(ctx, user.Password)
+	if err != nil {
+		return nil, err
 	}
 	user.Password = hashed
-	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
-	
-	_, err = repo.Create(ctx, user)
-	return err
+
+	created, err := uc.repo.Create(ctx, user)
+	var conflict *repository.ConflictError
+	if uc.options.ConcealExistingEmail && errors.As(err, &conflict) && conflict.Field == "email" {
+		return nil, nil
+	}
+	return created, err
 }
@@ -0,0 +1,309 @@
+package auth
+
+// SessionUseCase tracks where a user is signed in.
+// - Every login starts a session that records the device, parsed from the
+//   User-Agent, and the client IP. The session is the family of its refresh
+//   tokens; access tokens name it in their sid claim.
+// - Refresh tokens are single-use and rotate on every refresh. Presenting
+//   a used token again means it leaked, so the whole session is revoked.
+// - Users list and revoke their own sessions, admins those of any user in
+//   their tenant. Revoking a session ends its refresh tokens and, through
+//   ActiveAccountTokens, its access tokens.
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+)
+
+// DefaultSessionTTL is how long a session lasts, however often it is
+// refreshed.
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown refresh tokens and
+	// tokens of expired sessions.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token is presented a
+	// second time; its session has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token was already used; the session has been revoked")
+	// ErrSessionNotFound is returned for sessions that do not exist, are no
+	// longer active or belong to another user.
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// GroupLister provides the group names carried in access tokens.
+type GroupLister interface {
+	GroupNames(ctx context.Context, user entity.UserID) ([]string, error)
+}
+
+// ClientInfo describes the client a login comes from. AcceptLanguage only
+// feeds the device fingerprint.
+type ClientInfo struct {
+	UserAgent      string
+	IP             string
+	AcceptLanguage string
+}
+
+// IssuedTokens are the tokens handed to a client after a login or refresh.
+type IssuedTokens struct {
+	AccessToken  string
+	Claims       service.AccessClaims
+	RefreshToken string
+	Session      *entity.Session
+}
+
+// SessionUseCase starts, refreshes, lists and revokes sessions.
+type SessionUseCase struct {
+	sessions  repository.SessionRepository
+	users     repository.UserRepository
+	tokens    service.TokenService
+	devices   service.DeviceDetector
+	txManager repository.TxManager
+	lastSeen  *LastSeenTracker
+	groups    GroupLister
+	history   *LoginHistory
+	locator   service.GeoLocator
+	ttl       time.Duration
+}
+
+// SessionOptions configures a SessionUseCase.
+type SessionOptions struct {
+	// TTL is the session lifetime; zero means DefaultSessionTTL.
+	TTL time.Duration
+	// Groups, when set, fills the groups claim of access tokens.
+	Groups GroupLister
+	// History, when set, records each login and flags risky ones on their
+	// session.
+	History *LoginHistory
+	// Locator, when set, records where sessions were started from.
+	Locator service.GeoLocator
+}
+
+// NewSessionUseCase creates a SessionUseCase.
+func NewSessionUseCase(sessions repository.SessionRepository, users repository.UserRepository, tokens service.TokenService, devices service.DeviceDetector, txManager repository.TxManager, lastSeen *LastSeenTracker, options SessionOptions) *SessionUseCase {
+	if options.TTL <= 0 {
+		options.TTL = DefaultSessionTTL
+	}
+	return &SessionUseCase{
+		sessions:  sessions,
+		users:     users,
+		tokens:    tokens,
+		devices:   devices,
+		txManager: txManager,
+		lastSeen:  lastSeen,
+		groups:    options.Groups,
+		history:   options.History,
+		locator:   options.Locator,
+		ttl:       options.TTL,
+	}
+}
+
+// Start opens a session for user, who has just authenticated, and issues
+// its first tokens. The login is recorded in the history, and its risk
+// flags are kept on the session.
+func (uc *SessionUseCase) Start(ctx context.Context, user *entity.User, client ClientInfo) (*IssuedTokens, error) {
+	session := entity.NewSession(user, uc.devices.Detect(client.UserAgent), client.UserAgent, client.IP, uc.ttl)
+	session.Location = locate(uc.locator, client.IP)
+	var attempt *entity.LoginAttempt
+	if uc.history != nil {
+		attempt = uc.history.assess(ctx, user, client)
+		session.RiskFlags = attempt.RiskFlags
+	}
+
+	refreshToken, tokenHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.sessions.Create(ctx, session, entity.NewRefreshToken(session, tokenHash)); err != nil {
+		return nil, err
+	}
+	if attempt != nil {
+		uc.history.recordSuccess(ctx, user, attempt, session.ID)
+	}
+	return uc.issue(ctx, user, session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for new tokens of the same session. The
+// token names the tenant, so ctx needs none.
+//
+// Errors:
+//   - ErrInvalidRefreshToken for unknown tokens and expired sessions
+//   - ErrRefreshTokenReused for used tokens, after revoking the session
+//   - ErrSessionRevoked for revoked sessions
+//   - ErrAccountNotFound and the errors of entity.User.CheckActive when the
+//     account can no longer sign in
+func (uc *SessionUseCase) Refresh(ctx context.Context, refreshToken string) (*IssuedTokens, error) {
+	tokenHash := hashRefreshToken(refreshToken)
+	stored, err := uc.sessions.GetRefreshToken(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	ctx = repository.WithTenant(ctx, stored.TenantID)
+	session, err := uc.sessions.GetByID(ctx, stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return nil, uc.revokeReused(ctx, session)
+	}
+
+	user, err := uc.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrAccountNotFound
+	}
+	now := time.Now()
+	if err := user.CheckActive(now); err != nil {
+		return nil, err
+	}
+	if !session.Active(user, now) {
+		if !now.Before(session.ExpiresAt) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, ErrSessionRevoked
+	}
+
+	next, nextHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	reused := false
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		used, err := uc.sessions.UseRefreshToken(ctx, tokenHash, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			// Another refresh with the same token got there first
+			reused = true
+			return nil
+		}
+		return uc.sessions.CreateRefreshToken(ctx, entity.NewRefreshToken(session, nextHash))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, uc.revokeReused(ctx, session)
+	}
+
+	uc.lastSeen.Seen(session.ID, now)
+	return uc.issue(ctx, user, session, next)
+}
+
+// revokeReused revokes the session of a reused refresh token and returns
+// ErrRefreshTokenReused.
+func (uc *SessionUseCase) revokeReused(ctx context.Context, session *entity.Session) error {
+	if session.Revoke(time.Now()) == nil {
+		if err := uc.sessions.Update(ctx, session); err != nil {
+			return err
+		}
+		log.Printf("refresh token reuse detected; revoked session %s of user %s", session.ID, session.UserID)
+	}
+	return ErrRefreshTokenReused
+}
+
+// List returns the active sessions of user, most recently seen first.
+// It returns ErrUserNotFound when the user does not exist.
+func (uc *SessionUseCase) List(ctx context.Context, userID entity.UserID) ([]*entity.Session, error) {
+	user, err := uc.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now()
+	stored, err := uc.sessions.ListByUser(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	sessions := stored[:0]
+	for _, session := range stored {
+		if !session.Active(user, now) {
+			continue
+		}
+		// Activity not written yet is still the latest
+		if at, ok := uc.lastSeen.LastSeen(session.ID); ok && at.After(session.LastSeenAt) {
+			session.LastSeenAt = at
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// Revoke ends a session of user. It returns ErrSessionNotFound unless the
+// session is one of the user's active sessions.
+func (uc *SessionUseCase) Revoke(ctx context.Context, userID entity.UserID, id entity.SessionID) error {
+	if _, err := entity.ParseSessionID(string(id)); err != nil {
+		return ErrSessionNotFound
+	}
+	session, err := uc.sessions.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	if err := session.Revoke(time.Now()); err != nil {
+		return ErrSessionNotFound
+	}
+	return uc.sessions.Update(ctx, session)
+}
+
+// issue signs an access token for user in session.
+func (uc *SessionUseCase) issue(ctx context.Context, user *entity.User, session *entity.Session, refreshToken string) (*IssuedTokens, error) {
+	var groups []string
+	if uc.groups != nil {
+		var err error
+		if groups, err = uc.groups.GroupNames(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	accessToken, claims, err := uc.tokens.IssueAccessToken(user, session.ID, groups)
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedTokens{
+		AccessToken:  accessToken,
+		Claims:       claims,
+		RefreshToken: refreshToken,
+		Session:      session,
+	}, nil
+}
+
+// newRefreshToken returns a random refresh token and its stored hash.
+func newRefreshToken() (token, tokenHash string, err error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b[:])
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,139 @@
+package auth
+
+// ActiveAccountTokens makes stopping an account take effect immediately
+// rather than when its access tokens expire.
+// - Every verified token is checked against the stored account, which costs
+//   one lookup by primary key per authenticated request.
+// - The check is a TokenService decorator, so every route behind
+//   RequireAuth gets it without knowing about account status.
+// - Impersonation tokens also need their impersonation to be running and
+//   the impersonating admin to still be an active admin.
+// - Tokens of a session need the session to be active, so revoking it logs
+//   its device out at once. The request counts as activity on the session;
+//   a LastSeenTracker batches those writes.
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+)
+
+var (
+	// ErrSessionRevoked is returned for tokens issued before the account's
+	// sessions were revoked.
+	ErrSessionRevoked = errors.New("session has been revoked")
+	// ErrAccountNotFound is returned for tokens of deleted accounts.
+	ErrAccountNotFound = errors.New("account no longer exists")
+)
+
+// ActiveAccountTokens is a service.TokenService that only accepts tokens of
+// existing, active accounts whose sessions were not revoked after the token
+// was issued.
+type ActiveAccountTokens struct {
+	service.TokenService
+	users          repository.UserRepository
+	impersonations repository.ImpersonationRepository
+	sessions       repository.SessionRepository
+	lastSeen       *LastSeenTracker
+}
+
+// NewActiveAccountTokens wraps tokens with the account checks.
+func NewActiveAccountTokens(tokens service.TokenService, users repository.UserRepository, impersonations repository.ImpersonationRepository, sessions repository.SessionRepository, lastSeen *LastSeenTracker) *ActiveAccountTokens {
+	return &ActiveAccountTokens{
+		TokenService:   tokens,
+		users:          users,
+		impersonations: impersonations,
+		sessions:       sessions,
+		lastSeen:       lastSeen,
+	}
+}
+
+// ParseAccessToken verifies token with the wrapped service and then checks
+// the account. Besides service.ErrInvalidToken it returns
+// ErrAccountNotFound, ErrSessionRevoked and the errors of
+// entity.User.CheckActive, and entity.ErrImpersonationEnded for
+// impersonation tokens that are no longer valid. Tokens of a revoked or
+// expired session fail with ErrSessionRevoked.
+func (t *ActiveAccountTokens) ParseAccessToken(ctx context.Context, token string) (*service.AccessClaims, error) {
+	claims, err := t.TokenService.ParseAccessToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckSession(ctx, t.users, claims.TenantID, claims.UserID, claims.IssuedAt); err != nil {
+		return nil, err
+	}
+	if claims.Impersonated() {
+		if err := t.checkImpersonation(repository.WithTenant(ctx, claims.TenantID), claims); err != nil {
+			return nil, err
+		}
+	}
+	if claims.SessionID != "" {
+		if err := t.checkSession(repository.WithTenant(ctx, claims.TenantID), claims); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// checkSession verifies that the session behind claims was not revoked and
+// has not expired, and records the request as activity on it. The account
+// itself was checked by CheckSession.
+func (t *ActiveAccountTokens) checkSession(ctx context.Context, claims *service.AccessClaims) error {
+	session, err := t.sessions.GetByID(ctx, claims.SessionID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if session == nil || session.UserID != claims.UserID || session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+	t.lastSeen.Seen(session.ID, now)
+	return nil
+}
+
+// checkImpersonation verifies that the impersonation behind claims is still
+// running and that its admin could still start it.
+func (t *ActiveAccountTokens) checkImpersonation(ctx context.Context, claims *service.AccessClaims) error {
+	impersonation, err := t.impersonations.GetByID(ctx, claims.ImpersonationID)
+	if err != nil {
+		return err
+	}
+	if impersonation == nil || impersonation.ActorID != claims.ActorID || impersonation.TargetID != claims.UserID ||
+		!impersonation.Active(time.Now()) {
+		return entity.ErrImpersonationEnded
+	}
+
+	actor, err := t.users.GetByID(ctx, claims.ActorID)
+	if err != nil {
+		return err
+	}
+	if actor == nil || actor.Role != entity.RoleAdmin || actor.CheckActive(time.Now()) != nil ||
+		actor.SessionRevoked(impersonation.StartedAt) {
+		return entity.ErrImpersonationEnded
+	}
+	return nil
+}
+
+// CheckSession returns nil when a session of user in tenant issued at
+// issuedAt may still be used: the account exists, is active and its
+// sessions were not revoked since.
+func CheckSession(ctx context.Context, users repository.UserRepository, tenant entity.TenantID, id entity.UserID, issuedAt time.Time) error {
+	user, err := users.GetByID(repository.WithTenant(ctx, tenant), id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrAccountNotFound
+	}
+	if err := user.CheckActive(time.Now()); err != nil {
+		return err
+	}
+	if user.SessionRevoked(issuedAt) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package group
+
+// GroupUseCase manages groups and their memberships within a tenant.
+// - Tenant admins manage every group; a group's owners manage its members.
+// - Members can see who else is in their group, and every user can see
+//   their own groups. Everything else is ErrForbidden.
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+var (
+	// ErrGroupNotFound is returned when the group does not exist in the tenant.
+	ErrGroupNotFound = errors.New("group not found")
+	// ErrUserNotFound is returned when the user to add does not exist in the tenant.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrNotMember is returned when removing a user who is not a member.
+	ErrNotMember = errors.New("user is not a member of the group")
+	// ErrForbidden is returned when the actor may not perform the action.
+	ErrForbidden = errors.New("insufficient permissions")
+)
+
+// Actor is the authenticated user performing a request.
+type Actor struct {
+	UserID entity.UserID
+	Role   entity.Role
+}
+
+func (a Actor) isAdmin() bool {
+	return a.Role == entity.RoleAdmin
+}
+
+// GroupUseCase handles group-related business logic
+type GroupUseCase struct {
+	groups repository.GroupRepository
+	users  repository.UserRepository
+}
+
+// NewGroupUseCase creates a GroupUseCase
+func NewGroupUseCase(groups repository.GroupRepository, users repository.UserRepository) *GroupUseCase {
+	return &GroupUseCase{groups: groups, users: users}
+}
+
+// CreateGroup validates and stores a new group. Only admins may create
+// groups.
+//
+// Errors:
+//   - *entity.ValidationError when the name or description is invalid
+//   - *repository.ConflictError when the name is taken in the tenant
+func (uc *GroupUseCase) CreateGroup(ctx context.Context, actor Actor, name, description string) (*entity.Group, error) {
+	if !actor.isAdmin() {
+		return nil, ErrForbidden
+	}
+	group, err := entity.NewGroup(name, description)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.groups.Create(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// AddMember adds user to the group with role, or changes the role of an
+// existing member. Admins and the group's owners may add members.
+func (uc *GroupUseCase) AddMember(ctx context.Context, actor Actor, groupID entity.GroupID, userID entity.UserID, role entity.GroupRole) (*entity.Membership, error) {
+	if _, err := uc.authorize(ctx, actor, groupID, entity.GroupRoleOwner); err != nil {
+		return nil, err
+	}
+	user, err := uc.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	membership := &entity.Membership{
+		GroupID:  groupID,
+		UserID:   user.ID,
+		Role:     role,
+		JoinedAt: time.Now(),
+	}
+	if err := uc.groups.AddMember(ctx, membership); err != nil {
+		// The user or group was deleted since they were read
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	// The join time of an existing member is kept; report the stored state
+	return uc.groups.GetMembership(ctx, groupID, user.ID)
+}
+
+// RemoveMember removes user from the group. Admins and the group's owners
+// may remove anyone, and members may leave.
+func (uc *GroupUseCase) RemoveMember(ctx context.Context, actor Actor, groupID entity.GroupID, userID entity.UserID) error {
+	required := entity.GroupRoleOwner
+	if actor.UserID == userID {
+		required = entity.GroupRoleMember
+	}
+	if _, err := uc.authorize(ctx, actor, groupID, required); err != nil {
+		return err
+	}
+
+	removed, err := uc.groups.RemoveMember(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// ListMembers returns one page of the group's members. Admins and members
+// of the group may list them.
+func (uc *GroupUseCase) ListMembers(ctx context.Context, actor Actor, query repository.MemberPageQuery) (*repository.MemberPage, error) {
+	if _, err := uc.authorize(ctx, actor, query.GroupID, entity.GroupRoleMember); err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+	return uc.groups.ListMembers(ctx, query)
+}
+
+// ListUserGroups returns the groups user belongs to. Users may list their
+// own groups and admins anyone's.
+func (uc *GroupUseCase) ListUserGroups(ctx context.Context, actor Actor, userID entity.UserID) ([]repository.UserGroup, error) {
+	if actor.UserID != userID && !actor.isAdmin() {
+		return nil, ErrForbidden
+	}
+	return uc.groups.ListUserGroups(ctx, userID)
+}
+
+// GroupNames returns the names of the groups user belongs to, for the
+// groups claim of access tokens.
+func (uc *GroupUseCase) GroupNames(ctx context.Context, userID entity.UserID) ([]string, error) {
+	groups, err := uc.groups.ListUserGroups(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.Group.Name.String()
+	}
+	return names, nil
+}
+
+// authorize loads the group and checks that actor is an admin or has at
+// least the required role in it. Owners have every member right.
+func (uc *GroupUseCase) authorize(ctx context.Context, actor Actor, groupID entity.GroupID, required entity.GroupRole) (*entity.Group, error) {
+	group, err := uc.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+	if actor.isAdmin() {
+		return group, nil
+	}
+
+	membership, err := uc.groups.GetMembership(ctx, groupID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil || (required == entity.GroupRoleOwner && membership.Role != entity.GroupRoleOwner) {
+		return nil, ErrForbidden
+	}
+	return group, nil
+}
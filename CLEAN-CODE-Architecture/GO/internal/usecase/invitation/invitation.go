@@ -0,0 +1,376 @@
+package invitation
+
+// InvitationUseCase lets admins invite colleagues into their tenant.
+// - The link token is random and only its SHA-256 is stored; resending
+//   issues a new token, so older links stop working.
+// - Accepting creates the account directly, not through RegisterUseCase, so
+//   invitations keep working when open registration is invite-only or
+//   restricted. Only a closed registration policy stops them.
+// - The account, its group membership and the accepted invitation are
+//   written in one transaction.
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+)
+
+// DefaultTTL is how long an invitation link stays valid.
+const DefaultTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrInvitationNotFound is returned for unknown invitation IDs and for
+	// link tokens that match no invitation.
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrAlreadyInvited is returned when the email has a pending invitation
+	// that has not expired; resend that one instead.
+	ErrAlreadyInvited = errors.New("email already has a pending invitation")
+	// ErrAlreadyRegistered is returned when inviting an email that has an
+	// account in the tenant.
+	ErrAlreadyRegistered = errors.New("email is already registered")
+	// ErrGroupNotFound is returned when inviting into an unknown group.
+	ErrGroupNotFound = errors.New("group not found")
+	// ErrDeliveryFailed is returned when the invitation was stored but the
+	// email could not be sent; it can be resent.
+	ErrDeliveryFailed = errors.New("invitation email could not be sent")
+)
+
+// Options configure invitations.
+type Options struct {
+	// TTL is how long links stay valid; DefaultTTL if zero.
+	TTL time.Duration
+	// AcceptURL is the link sent to invitees, with "{token}" replaced by the
+	// invitation token. It usually points at a frontend page that posts to
+	// /invitations/{token}/accept.
+	AcceptURL string
+}
+
+// InvitationUseCase handles invitation-related business logic
+type InvitationUseCase struct {
+	invitations    repository.InvitationRepository
+	users          repository.UserRepository
+	groups         repository.GroupRepository
+	txManager      repository.TxManager
+	hasher         service.PasswordHasher
+	passwordPolicy *policy.PasswordPolicy
+	registration   *policy.RegistrationPolicy
+	mailer         service.Mailer
+	options        Options
+}
+
+// NewInvitationUseCase creates an InvitationUseCase
+func NewInvitationUseCase(
+	invitations repository.InvitationRepository,
+	users repository.UserRepository,
+	groups repository.GroupRepository,
+	txManager repository.TxManager,
+	hasher service.PasswordHasher,
+	passwordPolicy *policy.PasswordPolicy,
+	registration *policy.RegistrationPolicy,
+	mailer service.Mailer,
+	options Options,
+) *InvitationUseCase {
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+	return &InvitationUseCase{
+		invitations:    invitations,
+		users:          users,
+		groups:         groups,
+		txManager:      txManager,
+		hasher:         hasher,
+		passwordPolicy: passwordPolicy,
+		registration:   registration,
+		mailer:         mailer,
+		options:        options,
+	}
+}
+
+// InviteInput describes a new invitation.
+type InviteInput struct {
+	Email     string
+	InvitedBy entity.UserID
+	// Role is given to the new account; RoleUser if empty.
+	Role entity.Role
+	// GroupID optionally names a group the new account joins as a member.
+	GroupID *entity.GroupID
+}
+
+// Invite stores an invitation and emails the link. An expired pending
+// invitation for the same email is revoked and replaced.
+//
+// Errors:
+//   - policy.ErrRegistrationClosed when no accounts may be created
+//   - *entity.ValidationError when the email is invalid
+//   - ErrAlreadyRegistered, ErrAlreadyInvited, ErrGroupNotFound
+//   - ErrDeliveryFailed when the invitation was stored but not sent
+func (uc *InvitationUseCase) Invite(ctx context.Context, input InviteInput) (*entity.Invitation, error) {
+	if err := uc.registration.Check(policy.ChannelInvitation, input.Email); err != nil {
+		return nil, err
+	}
+	if input.Role == "" {
+		input.Role = entity.RoleUser
+	}
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := entity.NewInvitation(input.Email, input.InvitedBy, input.Role, input.GroupID, tokenHash, uc.options.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if existing, err := uc.users.GetByEmail(ctx, invitation.Email); err != nil {
+			return err
+		} else if existing != nil {
+			return ErrAlreadyRegistered
+		}
+		if input.GroupID != nil {
+			if group, err := uc.groups.GetByID(ctx, *input.GroupID); err != nil {
+				return err
+			} else if group == nil {
+				return ErrGroupNotFound
+			}
+		}
+
+		pending, err := uc.invitations.GetPending(ctx, invitation.Email)
+		if err != nil {
+			return err
+		}
+		if pending != nil {
+			if pending.State(time.Now()) != entity.InvitationExpired {
+				return ErrAlreadyInvited
+			}
+			if err := pending.Revoke(time.Now()); err != nil {
+				return err
+			}
+			if err := uc.invitations.Update(ctx, pending); err != nil {
+				return err
+			}
+		}
+
+		err = uc.invitations.Create(ctx, invitation)
+		var conflict *repository.ConflictError
+		if errors.As(err, &conflict) && conflict.Field == "invitation" {
+			return ErrAlreadyInvited
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, uc.send(ctx, invitation, token)
+}
+
+// ListInvitations returns the tenant's invitations, newest first, optionally
+// only those whose current state is status.
+func (uc *InvitationUseCase) ListInvitations(ctx context.Context, status *entity.InvitationStatus) ([]*entity.Invitation, error) {
+	invitations, err := uc.invitations.List(ctx)
+	if err != nil || status == nil {
+		return invitations, err
+	}
+
+	now := time.Now()
+	filtered := invitations[:0]
+	for _, invitation := range invitations {
+		if invitation.State(now) == *status {
+			filtered = append(filtered, invitation)
+		}
+	}
+	return filtered, nil
+}
+
+// Resend issues a new link for a pending or expired invitation, restarts its
+// expiry and emails it again. The previous link stops working.
+func (uc *InvitationUseCase) Resend(ctx context.Context, id entity.InvitationID) (*entity.Invitation, error) {
+	invitation, err := uc.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := invitation.Renew(tokenHash, uc.options.TTL, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := uc.invitations.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+	return invitation, uc.send(ctx, invitation, token)
+}
+
+// Revoke cancels a pending or expired invitation.
+func (uc *InvitationUseCase) Revoke(ctx context.Context, id entity.InvitationID) (*entity.Invitation, error) {
+	invitation, err := uc.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := invitation.Revoke(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := uc.invitations.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// AcceptInput is what the invitee supplies to create their account. The
+// email comes from the invitation.
+type AcceptInput struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// Accept creates the invited account in the invitation's tenant with the
+// invitation's role, adds it to the invitation's group and marks the
+// invitation accepted. The email counts as verified, since the invitee
+// received the link there.
+//
+// Errors:
+//   - ErrInvitationNotFound for unknown tokens
+//   - policy.ErrRegistrationClosed when no accounts may be created
+//   - entity.ErrInvitationExpired, entity.ErrInvitationNotPending
+//   - *entity.ValidationError, *policy.PasswordPolicyError
+//   - *repository.ConflictError when the username or email is taken
+func (uc *InvitationUseCase) Accept(ctx context.Context, input AcceptInput) (*entity.User, error) {
+	invitation, err := uc.invitations.GetByTokenHash(ctx, hashToken(input.Token))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+	if state := invitation.State(time.Now()); state == entity.InvitationExpired {
+		return nil, entity.ErrInvitationExpired
+	} else if state != entity.InvitationPending {
+		return nil, entity.ErrInvitationNotPending
+	}
+	// Checked again because registration may have closed since the invite
+	if err := uc.registration.Check(policy.ChannelInvitation, invitation.Email.String()); err != nil {
+		return nil, err
+	}
+	ctx = repository.WithTenant(ctx, invitation.TenantID)
+
+	user, err := entity.NewUser(input.Username, invitation.Email.String(), input.Password)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.passwordPolicy.Validate(ctx, policy.PasswordCandidate{
+		Password: input.Password,
+		Username: user.Username.String(),
+		Email:    user.Email.String(),
+	}); err != nil {
+		return nil, err
+	}
+	hashed, err := uc.hasher.Hash(ctx, user.Password)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = hashed
+	user.Role = invitation.Role
+	user.MarkEmailVerified(time.Now())
+
+	var created *entity.User
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Re-read inside the transaction so a revoke or a concurrent accept
+		// that committed meanwhile wins
+		current, err := uc.invitations.GetByID(ctx, invitation.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil || current.TokenHash != invitation.TokenHash {
+			return ErrInvitationNotFound
+		}
+
+		created, err = uc.users.Create(ctx, user)
+		if err != nil {
+			return err
+		}
+		if current.GroupID != nil {
+			err := uc.groups.AddMember(ctx, &entity.Membership{
+				GroupID:  *current.GroupID,
+				UserID:   created.ID,
+				Role:     entity.GroupRoleMember,
+				JoinedAt: time.Now(),
+			})
+			// A group deleted since the invitation was sent is skipped
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+		}
+		if err := current.Accept(created.ID, time.Now()); err != nil {
+			return err
+		}
+		return uc.invitations.Update(ctx, current)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (uc *InvitationUseCase) get(ctx context.Context, id entity.InvitationID) (*entity.Invitation, error) {
+	invitation, err := uc.invitations.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// send emails the link for token and records the attempt. A delivery failure
+// is logged and reported as ErrDeliveryFailed; the invitation stays pending.
+func (uc *InvitationUseCase) send(ctx context.Context, invitation *entity.Invitation, token string) error {
+	link := strings.ReplaceAll(uc.options.AcceptURL, "{token}", token)
+	err := uc.mailer.Send(ctx, service.Message{
+		To:      invitation.Email.String(),
+		Subject: "You have been invited",
+		Body: fmt.Sprintf("You have been invited to create an account.\n\n"+
+			"Accept the invitation here:\n%s\n\n"+
+			"The link expires on %s.\n",
+			link, invitation.ExpiresAt.UTC().Format("2 January 2006 15:04 MST")),
+	})
+	if err != nil {
+		log.Printf("sending invitation %s failed: %v", invitation.ID, err)
+		return ErrDeliveryFailed
+	}
+
+	invitation.MarkSent(time.Now())
+	if err := uc.invitations.Update(ctx, invitation); err != nil {
+		log.Printf("recording delivery of invitation %s failed: %v", invitation.ID, err)
+	}
+	return nil
+}
+
+// newToken returns a random link token and its stored hash.
+func newToken() (token, tokenHash string, err error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", "", fmt.Errorf("generate invitation token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b[:])
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
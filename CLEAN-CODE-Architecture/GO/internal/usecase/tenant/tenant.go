@@ -0,0 +1,95 @@
+package tenant
+
+// TenantUseCase manages organizations and maps the references clients send
+// (IDs or slugs) to tenants.
+// - Tenants are not scoped to a tenant themselves; the HTTP layer only lets
+//   admins of the default tenant manage them.
+
+import (
+	"context"
+	"errors"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/repository"
+)
+
+// ErrTenantNotFound is returned when no tenant matches an ID or slug.
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// TenantUseCase handles tenant-related business logic
+type TenantUseCase struct {
+	repo repository.TenantRepository
+}
+
+// NewTenantUseCase creates a TenantUseCase
+func NewTenantUseCase(repo repository.TenantRepository) *TenantUseCase {
+	return &TenantUseCase{repo: repo}
+}
+
+// CreateTenant validates and stores a new tenant.
+//
+// Errors:
+//   - *entity.ValidationError when the slug or name is invalid
+//   - *repository.ConflictError when the slug is taken
+func (uc *TenantUseCase) CreateTenant(ctx context.Context, slug, name string) (*entity.Tenant, error) {
+	tenant, err := entity.NewTenant(slug, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// ListTenants returns every tenant ordered by slug.
+func (uc *TenantUseCase) ListTenants(ctx context.Context) ([]*entity.Tenant, error) {
+	return uc.repo.List(ctx)
+}
+
+// GetTenant returns the tenant with id or ErrTenantNotFound.
+func (uc *TenantUseCase) GetTenant(ctx context.Context, id entity.TenantID) (*entity.Tenant, error) {
+	tenant, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, ErrTenantNotFound
+	}
+	return tenant, nil
+}
+
+// RenameTenant changes the display name of the tenant with id.
+func (uc *TenantUseCase) RenameTenant(ctx context.Context, id entity.TenantID, name string) (*entity.Tenant, error) {
+	tenant, err := uc.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tenant.Rename(name); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// ResolveTenant finds a tenant by ID or slug. A reference that is neither
+// is reported as ErrTenantNotFound too.
+func (uc *TenantUseCase) ResolveTenant(ctx context.Context, ref string) (*entity.Tenant, error) {
+	if id, err := entity.ParseTenantID(ref); err == nil {
+		return uc.GetTenant(ctx, id)
+	}
+	slug, err := entity.NewTenantSlug(ref)
+	if err != nil {
+		return nil, ErrTenantNotFound
+	}
+	tenant, err := uc.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, ErrTenantNotFound
+	}
+	return tenant, nil
+}
@@ -0,0 +1,401 @@
+package usecase
+
+// Bulk import and export of user accounts.
+// - Rows are validated through the same domain rules as registration, and
+//   rows creating an account also through the password policy, then stored
+//   in batches through repository.UpsertBatch.
+// - Row problems never abort an import; they are collected in ImportReport.
+// - Export pages through the repository so memory use does not grow with
+//   the number of users.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/domain/repository"
+	"auth-module/internal/domain/service"
+)
+
+// ImportRow is one user record from an import file. Role and Status
+// default to a regular, active account; any other status needs a
+// StatusReason, as SuspendUser does. Password, Role, Status and
+// StatusReason only apply to new accounts: a row for a registered email
+// only updates its profile and needs no password, as passwords and status
+// change through PasswordUseCase and SuspendUser/ReinstateUser.
+type ImportRow struct {
+	Line      int    `json:"-"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+	Address   string `json:"address"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	// StatusReason is recorded with a suspended, locked or banned status.
+	StatusReason string `json:"status_reason"`
+}
+
+// ImportSource yields rows until it returns io.EOF. A record that cannot be
+// decoded is reported as a *MalformedRowError; any other error aborts the
+// import.
+type ImportSource interface {
+	Next() (ImportRow, error)
+}
+
+// MalformedRowError reports an undecodable record; the import skips it.
+type MalformedRowError struct {
+	Line int
+	Err  error
+}
+
+func (e *MalformedRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *MalformedRowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportRowError explains why one row was not imported.
+type ImportRowError struct {
+	Line   int               `json:"line"`
+	Email  string            `json:"email,omitempty"`
+	Error  string            `json:"error,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// ImportReport summarizes an import. Rows are counted as imported once
+// their batch has committed.
+type ImportReport struct {
+	Processed int              `json:"processed"`
+	Imported  int              `json:"imported"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// BulkOptions tune batch sizes for import and export.
+type BulkOptions struct {
+	// BatchSize is the number of users per transaction and per export page.
+	BatchSize int
+	// HashConcurrency bounds how many passwords of a batch are hashed at once.
+	HashConcurrency int
+}
+
+// BulkUseCase imports and exports users in bulk.
+type BulkUseCase struct {
+	repo           repository.UserRepository
+	hasher         service.PasswordHasher
+	passwordPolicy *policy.PasswordPolicy
+	options        BulkOptions
+}
+
+// NewBulkUseCase creates a BulkUseCase
+func NewBulkUseCase(repo repository.UserRepository, hasher service.PasswordHasher, passwordPolicy *policy.PasswordPolicy, options BulkOptions) *BulkUseCase {
+	if options.BatchSize <= 0 {
+		options.BatchSize = 500
+	}
+	if options.HashConcurrency <= 0 {
+		options.HashConcurrency = 4
+	}
+	return &BulkUseCase{
+		repo:           repo,
+		hasher:         hasher,
+		passwordPolicy: passwordPolicy,
+		options:        options,
+	}
+}
+
+// pendingUser is a row whose fields are valid, waiting for its batch to
+// learn whether the email is registered.
+type pendingUser struct {
+	row      ImportRow
+	username entity.Username
+	email    entity.Email
+	phone    entity.PhoneNumber
+	role     entity.Role
+	status   entity.AccountStatus
+	// user is the account the row will store; isNew says it is not
+	// registered yet and its password still has to be hashed.
+	user  *entity.User
+	isNew bool
+}
+
+// Import reads every row from src, validates it and upserts the valid ones
+// by email in batches. The report is returned even when err is set, and
+// then covers the batches committed before the failure.
+func (uc *BulkUseCase) Import(ctx context.Context, src ImportSource) (*ImportReport, error) {
+	report := &ImportReport{Errors: []ImportRowError{}}
+	batch := make([]pendingUser, 0, uc.options.BatchSize)
+
+	for {
+		row, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var malformed *MalformedRowError
+		if errors.As(err, &malformed) {
+			report.Processed++
+			report.fail(ImportRowError{Line: malformed.Line, Error: malformed.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Processed++
+		pending, err := parseRow(row)
+		if err != nil {
+			report.fail(newImportRowError(row, err))
+			continue
+		}
+		batch = append(batch, pending)
+
+		if len(batch) == uc.options.BatchSize {
+			if err := uc.storeBatch(ctx, batch, report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := uc.storeBatch(ctx, batch, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// parseRow checks the fields every row needs, whether it creates an account
+// or updates one. All field problems are returned together in one
+// *entity.ValidationError.
+func parseRow(row ImportRow) (pendingUser, error) {
+	pending := pendingUser{row: row, role: entity.RoleUser, status: entity.StatusActive}
+	verr := &entity.ValidationError{}
+	var err error
+
+	pending.email, err = entity.NewEmail(row.Email)
+	verr.Check("email", err)
+	pending.username, err = entity.NewUsername(row.Username)
+	verr.Check("username", err)
+	pending.phone, err = entity.NewPhoneNumber(row.Phone)
+	verr.Check("phone", err)
+	if row.Role != "" {
+		pending.role, err = entity.ParseRole(row.Role)
+		verr.Check("role", err)
+	}
+	if row.Status != "" {
+		pending.status, err = entity.ParseAccountStatus(row.Status)
+		verr.Check("status", err)
+	}
+	if verr.HasErrors() {
+		return pendingUser{}, verr
+	}
+	return pending, nil
+}
+
+// newAccount applies the registration rules to a row whose email is not
+// registered: it needs a password the policy accepts, and a reason for any
+// status other than active.
+func (uc *BulkUseCase) newAccount(ctx context.Context, pending pendingUser) (*entity.User, error) {
+	row := pending.row
+	verr := &entity.ValidationError{}
+	user, err := entity.NewUser(row.Username, row.Email, row.Password)
+	if err != nil && !errors.As(err, &verr) {
+		return nil, err
+	}
+	if pending.status != entity.StatusActive {
+		verr.Check("status_reason", entity.ValidateReason(row.StatusReason))
+	}
+	if verr.HasErrors() {
+		return nil, verr
+	}
+
+	if err := uc.passwordPolicy.Validate(ctx, policy.PasswordCandidate{
+		Password: row.Password,
+		Username: user.Username.String(),
+		Email:    user.Email.String(),
+	}); err != nil {
+		return nil, err
+	}
+
+	user.UpdateProfile(row.FirstName, row.LastName, pending.phone, row.Address)
+	user.Role = pending.role
+	now := time.Now()
+	switch pending.status {
+	case entity.StatusSuspended:
+		err = user.Suspend(row.StatusReason, nil, now)
+	case entity.StatusLocked:
+		err = user.Lock(row.StatusReason, now)
+	case entity.StatusBanned:
+		err = user.Ban(row.StatusReason, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// resolve decides for each row of the batch whether it creates an account
+// or updates the profile of a registered one, and builds the user to store.
+// Rows that fail the rules for new accounts are reported and dropped.
+func (uc *BulkUseCase) resolve(ctx context.Context, batch []pendingUser, report *ImportReport) ([]pendingUser, error) {
+	emails := make([]entity.Email, len(batch))
+	for i, pending := range batch {
+		emails[i] = pending.email
+	}
+	registered, err := uc.repo.GetByEmails(ctx, emails)
+	if err != nil {
+		return nil, fmt.Errorf("look up users from line %d: %w", batch[0].row.Line, err)
+	}
+	byEmail := make(map[entity.Email]*entity.User, len(registered))
+	for _, user := range registered {
+		byEmail[user.Email] = user
+	}
+
+	resolved := batch[:0]
+	for _, pending := range batch {
+		if existing, ok := byEmail[pending.email]; ok {
+			// Only the profile changes; the upsert keeps everything else
+			user := *existing
+			user.Username = pending.username
+			user.UpdateProfile(pending.row.FirstName, pending.row.LastName, pending.phone, pending.row.Address)
+			pending.user = &user
+		} else {
+			user, err := uc.newAccount(ctx, pending)
+			if err != nil {
+				report.fail(newImportRowError(pending.row, err))
+				continue
+			}
+			pending.user, pending.isNew = user, true
+		}
+		resolved = append(resolved, pending)
+	}
+	return resolved, nil
+}
+
+// storeBatch hashes the passwords of the batch's new accounts and upserts
+// the users.
+func (uc *BulkUseCase) storeBatch(ctx context.Context, batch []pendingUser, report *ImportReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	batch, err := uc.resolve(ctx, batch, report)
+	if err != nil {
+		return err
+	}
+	hashErrs := uc.hashPasswords(ctx, batch)
+
+	users := make([]*entity.User, 0, len(batch))
+	stored := make([]pendingUser, 0, len(batch))
+	for i, pending := range batch {
+		if hashErrs[i] != nil {
+			report.fail(newImportRowError(pending.row, hashErrs[i]))
+			continue
+		}
+		users = append(users, pending.user)
+		stored = append(stored, pending)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	errs, err := uc.repo.UpsertBatch(ctx, users)
+	if err != nil {
+		return fmt.Errorf("store users from line %d: %w", stored[0].row.Line, err)
+	}
+	for i, pending := range stored {
+		if errs[i] != nil {
+			report.fail(newImportRowError(pending.row, errs[i]))
+			continue
+		}
+		report.Imported++
+	}
+	return nil
+}
+
+// hashPasswords replaces the plaintext password of each new account with
+// its hash, running at most HashConcurrency hashes at a time.
+func (uc *BulkUseCase) hashPasswords(ctx context.Context, batch []pendingUser) []error {
+	errs := make([]error, len(batch))
+	slots := make(chan struct{}, uc.options.HashConcurrency)
+	var wg sync.WaitGroup
+	for i := range batch {
+		if !batch[i].isNew {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			hashed, err := uc.hasher.Hash(ctx, batch[i].user.Password)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			batch[i].user.SetPasswordHash(hashed)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// Export calls emit for every user selected by filter, oldest first,
+// reading one page at a time.
+func (uc *BulkUseCase) Export(ctx context.Context, filter repository.UserFilter, emit func(*entity.User) error) error {
+	var cursor *repository.UserCursor
+	for {
+		page, err := uc.repo.ListPage(ctx, repository.UserPageQuery{
+			Limit:  uc.options.BatchSize,
+			Cursor: cursor,
+			Filter: filter,
+			Sort:   repository.DefaultUserSort,
+		})
+		if err != nil {
+			return err
+		}
+		for _, user := range page.Users {
+			if err := emit(user); err != nil {
+				return err
+			}
+		}
+		if cursor = page.NextCursor(); cursor == nil {
+			return nil
+		}
+	}
+}
+
+func (r *ImportReport) fail(rowErr ImportRowError) {
+	r.Failed++
+	r.Errors = append(r.Errors, rowErr)
+}
+
+// newImportRowError describes err for row, keeping field-level detail.
+func newImportRowError(row ImportRow, err error) ImportRowError {
+	rowErr := ImportRowError{Line: row.Line, Email: row.Email}
+
+	var verr *entity.ValidationError
+	var perr *policy.PasswordPolicyError
+	var conflict *repository.ConflictError
+	switch {
+	case errors.As(err, &verr):
+		rowErr.Fields = make(map[string]string, len(verr.Fields))
+		for _, f := range verr.Fields {
+			rowErr.Fields[f.Field] = f.Message
+		}
+	case errors.As(err, &perr):
+		rowErr.Fields = map[string]string{"password": perr.Error()}
+	case errors.As(err, &conflict):
+		rowErr.Fields = map[string]string{conflict.Field: conflict.Error()}
+	default:
+		rowErr.Error = err.Error()
+	}
+	return rowErr
+}
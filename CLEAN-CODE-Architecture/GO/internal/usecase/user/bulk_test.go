@@ -0,0 +1,92 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"auth-module/internal/domain/entity"
+	"auth-module/internal/domain/policy"
+	"auth-module/internal/interface/repository/repotest"
+	userUseCase "auth-module/internal/usecase/user"
+	"auth-module/pkg/hash"
+)
+
+// rows is an ImportSource over a fixed list of rows.
+type rows []userUseCase.ImportRow
+
+func (r *rows) Next() (userUseCase.ImportRow, error) {
+	if len(*r) == 0 {
+		return userUseCase.ImportRow{}, io.EOF
+	}
+	row := (*r)[0]
+	*r = (*r)[1:]
+	return row, nil
+}
+
+// countingHasher counts the passwords it hashes.
+type countingHasher struct {
+	*hash.Hasher
+	hashed atomic.Int32
+}
+
+func (h *countingHasher) Hash(ctx context.Context, password string) (string, error) {
+	h.hashed.Add(1)
+	return h.Hasher.Hash(ctx, password)
+}
+
+func TestImportOnlyRequiresPasswordsForNewAccounts(t *testing.T) {
+	b := repotest.Memory()
+	ctx := b.NewTenant(t, "import")
+	existing := b.CreateUser(t, ctx, "existing", "existing@example.com")
+
+	hasher := &countingHasher{Hasher: hash.NewHasher(hash.NewBcrypt(4))}
+	uc := userUseCase.NewBulkUseCase(b.Users, hasher,
+		policy.NewPasswordPolicy(policy.DefaultPasswordPolicyConfig(), nil), userUseCase.BulkOptions{})
+
+	src := rows{
+		{Line: 2, Username: "existing", Email: "Existing@example.com", FirstName: "Ada", Status: "banned"},
+		{Line: 3, Username: "nopassword", Email: "nopassword@example.com"},
+		{Line: 4, Username: "noreason", Email: "noreason@example.com", Password: "Sup3r-Secret-Pass!", Status: "suspended"},
+		{Line: 5, Username: "suspended", Email: "suspended@example.com", Password: "Sup3r-Secret-Pass!", Status: "suspended", StatusReason: "Migrated as suspended"},
+	}
+	report, err := uc.Import(ctx, &src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Imported != 2 || report.Failed != 2 {
+		t.Fatalf("imported %d and failed %d rows, want 2 and 2: %+v", report.Imported, report.Failed, report.Errors)
+	}
+	failedFields := map[int]string{3: "password", 4: "status_reason"}
+	for _, rowErr := range report.Errors {
+		field := failedFields[rowErr.Line]
+		if _, ok := rowErr.Fields[field]; field == "" || !ok {
+			t.Errorf("line %d failed with %+v, want an error for %q", rowErr.Line, rowErr, field)
+		}
+	}
+	if n := hasher.hashed.Load(); n != 1 {
+		t.Errorf("hashed %d passwords, want only the new account's", n)
+	}
+
+	stored, err := b.Users.GetByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.FirstName != "Ada" {
+		t.Errorf("profile of the existing account was not updated: %+v", stored)
+	}
+	if stored.Password != existing.Password || stored.Status != entity.StatusActive {
+		t.Error("import changed the password or status of an existing account")
+	}
+
+	email, _ := entity.NewEmail("suspended@example.com")
+	suspended, err := b.Users.GetByEmail(ctx, email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suspended == nil || suspended.Status != entity.StatusSuspended || suspended.StatusReason != "Migrated as suspended" {
+		t.Errorf("new suspended account was stored as %+v", suspended)
+	}
+}
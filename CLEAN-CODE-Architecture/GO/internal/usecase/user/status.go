@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"auth-module/internal/domain/entity"
+)
+
+// ErrOwnAccount is returned when an admin tries to suspend, lock, ban or
+// reinstate their own account.
+var ErrOwnAccount = errors.New("cannot change the status of your own account")
+
+// SuspendUser suspends the account id for reason, until the given time or
+// indefinitely, and revokes its sessions. actor is the admin doing it.
+//
+// Errors:
+//   - ErrUserNotFound, ErrOwnAccount
+//   - *entity.ValidationError for a missing reason or an end in the past
+//   - entity.ErrInvalidStatusTransition for banned accounts
+func (uc *UserUseCase) SuspendUser(ctx context.Context, actor, id entity.UserID, reason string, until *time.Time) (*entity.User, error) {
+	return uc.changeStatus(ctx, actor, id, func(user *entity.User) error {
+		return user.Suspend(reason, until, time.Now())
+	})
+}
+
+// LockUser locks the account id for security reasons, such as a suspected
+// compromise, and revokes its sessions. It stays locked until reinstated.
+//
+// Errors:
+//   - ErrUserNotFound, ErrOwnAccount
+//   - *entity.ValidationError for a missing reason
+//   - entity.ErrInvalidStatusTransition for locked or banned accounts
+func (uc *UserUseCase) LockUser(ctx context.Context, actor, id entity.UserID, reason string) (*entity.User, error) {
+	return uc.changeStatus(ctx, actor, id, func(user *entity.User) error {
+		return user.Lock(reason, time.Now())
+	})
+}
+
+// BanUser bans the account id permanently and revokes its sessions. Only
+// ReinstateUser lifts a ban.
+//
+// Errors:
+//   - ErrUserNotFound, ErrOwnAccount
+//   - *entity.ValidationError for a missing reason
+//   - entity.ErrInvalidStatusTransition for banned accounts
+func (uc *UserUseCase) BanUser(ctx context.Context, actor, id entity.UserID, reason string) (*entity.User, error) {
+	return uc.changeStatus(ctx, actor, id, func(user *entity.User) error {
+		return user.Ban(reason, time.Now())
+	})
+}
+
+// ReinstateUser makes a suspended, locked or banned account active again.
+// Sessions revoked when it was stopped stay revoked, so the user signs in
+// again.
+//
+// Errors: ErrUserNotFound, ErrOwnAccount, and
+// entity.ErrInvalidStatusTransition for active accounts.
+func (uc *UserUseCase) ReinstateUser(ctx context.Context, actor, id entity.UserID) (*entity.User, error) {
+	return uc.changeStatus(ctx, actor, id, func(user *entity.User) error {
+		return user.Reinstate(time.Now())
+	})
+}
+
+// changeStatus loads the user, applies change and stores the result in one
+// transaction.
+func (uc *UserUseCase) changeStatus(ctx context.Context, actor, id entity.UserID, change func(*entity.User) error) (*entity.User, error) {
+	if actor == id {
+		return nil, ErrOwnAccount
+	}
+	var user *entity.User
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if err := change(user); err != nil {
+			return err
+		}
+		return uc.userRepo.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -7,57 +7,31 @@ import (
 	"errors"
 )
 
+// ErrUserNotFound is returned when the user to act on does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
 // UserUseCase handles user-related business logic
 // This is part of the Use Case layer in Clean Architecture
 type UserUseCase struct {
-	userRepo repository.UserRepository
+	userRepo  repository.UserRepository
+	txManager repository.TxManager
 }
 
 // NewUserUseCase creates a new UserUseCase
-func NewUserUseCase(userRepo repository.UserRepository) *UserUseCase {
+func NewUserUseCase(userRepo repository.UserRepository, txManager repository.TxManager) *UserUseCase {
 	return &UserUseCase{
-		userRepo: userRepo,
-	}
-}
-
-// CreateUser creates a new user with validation
-func (uc *UserUseCase) CreateUser(ctx context.Context, username, email, password string) (*entity.User, error) {
-	// Check if user already exists.
-	existingUser, err := uc.userRepo.GetByEmail(ctx, email)
-	if err != nil {
-		return nil, err
-	}
-	if existingUser != nil {
-		return nil, errors.New("user with this email already exists")
-	}
-
-	// Check if username is taken
-	existingUser, err = uc.userRepo.GetByUsername(ctx, username)
-	if err != nil {
-		return nil, err
+		userRepo:  userRepo,
+		txManager: txManager,
 	}
-	if existingUser != nil {
-		return nil, errors.New("username is already taken")
-	}
-
-	// Create new user using domain entity constructor
-	user, err := entity.NewUser(username, email, password)
-	if err != nil {
-		return nil, err
-	}
-
-	// Save user to repository
-	createdUser, err := uc.userRepo.Create(ctx, user)
-	if err != nil {
-		return nil, err
-	}
-
-	return createdUser, nil
 }
 
 // GetUserByEmail retrieves a user by email
 func (uc *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
-	return uc.userRepo.GetByEmail(ctx, email)
+	parsedEmail, err := entity.NewEmail(email)
+	if err != nil {
+		return nil, entity.NewFieldError("email", err)
+	}
+	return uc.userRepo.GetByEmail(ctx, parsedEmail)
 }
 
 // GetUserByID retrieves a user by ID
@@ -65,88 +39,102 @@ func (uc *UserUseCase) GetUserByID(ctx context.Context, id entity.UserID) (*enti
 	return uc.userRepo.GetByID(ctx, id)
 }
 
-// UpdateUserProfile updates user profile information
-func (uc *UserUseCase) UpdateUserProfile(ctx context.Context, id entity.UserID, firstName, lastName, phone, address string) error {
-	user, err := uc.userRepo.GetByID(ctx, id)
-	if err != nil {
-		return err
-	}
-	if user == nil {
-		return errors.New("user not found")
-	}
-
-	// Use domain method to update profile
-	user.UpdateProfile(firstName, lastName, phone, address)
+// GetUserByLegacyID retrieves a user by the integer ID used before UUIDs
+func (uc *UserUseCase) GetUserByLegacyID(ctx context.Context, legacyID int64) (*entity.User, error) {
+	return uc.userRepo.GetByLegacyID(ctx, legacyID)
+}
 
-	return uc.userRepo.Update(ctx, user)
+// ProfileChanges lists the profile fields to change; nil fields keep their
+// current value.
+type ProfileChanges struct {
+	FirstName *string
+	LastName  *string
+	Phone     *string
+	Address   *string
 }
 
-// ChangeUserPassword changes user password
-func (uc *UserUseCase) ChangeUserPassword(ctx context.Context, id entity.UserID, newPassword string) error {
-	user, err := uc.userRepo.GetByID(ctx, id)
+// UpdateUserProfile applies changes to the user's profile and returns the
+// stored user. A non-zero expectedVersion makes the update conditional on
+// the caller having seen that version (e.g. from an ETag); either way the
+// write itself is rejected with *repository.VersionConflictError if the user
+// changed after it was read here. The read and the write run in one
+// transaction.
+func (uc *UserUseCase) UpdateUserProfile(ctx context.Context, id entity.UserID, expectedVersion int64, changes ProfileChanges) (*entity.User, error) {
+	var user *entity.User
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if expectedVersion != 0 && user.Version != expectedVersion {
+			return &repository.VersionConflictError{ID: id, Version: expectedVersion}
+		}
+
+		firstName, lastName, address := user.FirstName, user.LastName, user.Address
+		phone := user.Phone
+		if changes.FirstName != nil {
+			firstName = *changes.FirstName
+		}
+		if changes.LastName != nil {
+			lastName = *changes.LastName
+		}
+		if changes.Address != nil {
+			address = *changes.Address
+		}
+		if changes.Phone != nil {
+			if phone, err = entity.NewPhoneNumber(*changes.Phone); err != nil {
+				return entity.NewFieldError("phone", err)
+			}
+		}
+
+		// Use domain method to update profile
+		user.UpdateProfile(firstName, lastName, phone, address)
+
+		return uc.userRepo.Update(ctx, user)
+	})
 	if err != nil {
-		return err
-	}
-	if user == nil {
-		return errors.New("user not found")
-	}
-
-	// Use domain method to change password
-	if err := user.ChangePassword(newPassword); err != nil {
-		return err
+		return nil, err
 	}
+	return user, nil
+}
 
-	return uc.userRepo.Update(ctx, user)
+// ListUsersPage retrieves one keyset page of users selected by query.
+func (uc *UserUseCase) ListUsersPage(ctx context.Context, query repository.UserPageQuery) (*repository.UserPage, error) {
+	query.Limit = clampPageLimit(query.Limit, 100)
+	return uc.userRepo.ListPage(ctx, query)
 }
 
-// ListUsers retrieves a list of users with pagination
-func (uc *UserUseCase) ListUsers(ctx context.Context, limit, offset int) ([]*entity.User, error) {
-	// Validate pagination parameters
-	if limit <= 0 {
-		limit = 10 // Default limit
-	}
-	if limit > 100 {
-		limit = 100 // Maximum limit to prevent abuse
+// SearchUsersPage retrieves one keyset page of users matching
+// query.Filter.Search, best matches first unless another sort is given.
+func (uc *UserUseCase) SearchUsersPage(ctx context.Context, query repository.UserPageQuery) (*repository.UserPage, error) {
+	if query.Filter.Search == "" {
+		return nil, errors.New("search query cannot be empty")
 	}
-	if offset < 0 {
-		offset = 0
+	if query.Sort.Field == "" {
+		query.Sort = repository.RelevanceSort
 	}
-	
-	return uc.userRepo.List(ctx, limit, offset)
+	query.Limit = clampPageLimit(query.Limit, 1000)
+	return uc.userRepo.ListPage(ctx, query)
 }
 
-// GetAllUsers retrieves all users with a reasonable limit
-func (uc *UserUseCase) GetAllUsers(ctx context.Context, limit int) ([]*entity.User, error) {
-	// Validate limit
-	if limit <= 0 {
-		limit = 50 // Default limit
-	}
-	if limit > 1000 {
-		limit = 1000 // Maximum limit to prevent memory issues
-	}
-	
-	return uc.userRepo.List(ctx, limit, 0)
+// CountUsers counts the users selected by filter.
+func (uc *UserUseCase) CountUsers(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	return uc.userRepo.CountMatching(ctx, filter)
 }
 
-// SearchUsers searches for users by username, email, first name, or last name
-func (uc *UserUseCase) SearchUsers(ctx context.Context, query string, limit, offset int) ([]*entity.User, error) {
-	// Validate inputs
-	if query == "" {
-		return nil, errors.New("search query cannot be empty")
-	}
-	
+// clampPageLimit applies the default page size and caps it at max.
+func clampPageLimit(limit, max int) int {
 	if limit <= 0 {
-		limit = 10
-	}
-	if limit > 1000 {
-		limit = 1000
+		return 10
 	}
-	if offset < 0 {
-		offset = 0
+	if limit > max {
+		return max
 	}
-	
-	// Use the Search method from repository
-	return uc.userRepo.Search(ctx, query, limit, offset)
+	return limit
 }
 
 // GetUserCount returns the total number of users
@@ -0,0 +1,223 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params are the tunable Argon2id costs.
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follows the OWASP recommendation for Argon2id.
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Pepper is a server-side secret mixed into every Argon2id hash with
+// HMAC-SHA256 before hashing. The ID is stored in the hash's "keyid"
+// parameter so the pepper can be rotated: hashes made with a retired (or
+// no) pepper still verify, are reported as outdated and are rehashed with
+// the current pepper on the next login. A hash whose pepper is neither
+// current nor retired can no longer be verified, so retire a pepper only
+// once no hash uses it.
+type Pepper struct {
+	ID  string
+	Key []byte
+}
+
+var b64 = base64.RawStdEncoding
+
+// Argon2id hashes passwords with Argon2id and encodes them as PHC strings:
+//
+//	$argon2id$v=19$m=65536,t=3,p=2[,keyid=<b64>]$<b64 salt>$<b64 hash>
+type Argon2id struct {
+	params Argon2Params
+	pepper Pepper
+	// peppers holds the current and retired peppers by ID.
+	peppers map[string]Pepper
+}
+
+// NewArgon2id creates an Argon2id algorithm. New hashes use pepper; pass a
+// zero Pepper to disable peppering. retired are earlier peppers whose
+// hashes are still accepted.
+func NewArgon2id(params Argon2Params, pepper Pepper, retired ...Pepper) *Argon2id {
+	peppers := make(map[string]Pepper, len(retired)+1)
+	for _, p := range retired {
+		peppers[p.ID] = p
+	}
+	if pepper.ID != "" {
+		peppers[pepper.ID] = pepper
+	}
+	return &Argon2id{params: params, pepper: pepper, peppers: peppers}
+}
+
+func (a *Argon2id) ID() string {
+	return "argon2id"
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey(pepperize(a.pepper, password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, a.params.KeyLength)
+
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", a.params.Memory, a.params.Iterations, a.params.Parallelism)
+	if a.pepper.ID != "" {
+		params += ",keyid=" + b64.EncodeToString([]byte(a.pepper.ID))
+	}
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s", argon2.Version, params, b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+func (a *Argon2id) Verify(password, encoded string) bool {
+	h, err := parseArgon2id(encoded)
+	if err != nil {
+		return false
+	}
+	var pepper Pepper
+	if h.keyID != "" {
+		var ok bool
+		if pepper, ok = a.peppers[h.keyID]; !ok {
+			// Made with a pepper we no longer hold; it cannot be verified.
+			return false
+		}
+	}
+
+	key := argon2.IDKey(pepperize(pepper, password), h.salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1
+}
+
+func (a *Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *Argon2id) Params(encoded string) string {
+	h, err := parseArgon2id(encoded)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("m=%d,t=%d,p=%d,l=%d", h.params.Memory, h.params.Iterations, h.params.Parallelism, len(h.key))
+}
+
+func (a *Argon2id) HashLike(password, encoded string) (string, error) {
+	h, err := parseArgon2id(encoded)
+	if err != nil {
+		return "", err
+	}
+	like := *a
+	like.params = h.params
+	like.params.SaltLength = uint32(len(h.salt))
+	like.params.KeyLength = uint32(len(h.key))
+	return like.Hash(password)
+}
+
+func (a *Argon2id) Outdated(encoded string) bool {
+	h, err := parseArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return h.params.Memory != a.params.Memory ||
+		h.params.Iterations != a.params.Iterations ||
+		h.params.Parallelism != a.params.Parallelism ||
+		uint32(len(h.key)) != a.params.KeyLength ||
+		h.keyID != a.pepper.ID
+}
+
+// pepperize applies pepper to password, unless pepper is zero.
+func pepperize(pepper Pepper, password string) []byte {
+	if pepper.ID == "" {
+		return []byte(password)
+	}
+	mac := hmac.New(sha256.New, pepper.Key)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+type argon2Hash struct {
+	params Argon2Params
+	keyID  string
+	salt   []byte
+	key    []byte
+}
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// parseArgon2id decodes a PHC-formatted Argon2id hash.
+func parseArgon2id(encoded string) (*argon2Hash, error) {
+	parts := strings.Split(encoded, "$")
+	// "", "argon2id", "v=19", params, salt, hash
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, errInvalidArgon2Hash
+	}
+	if parts[2] != "v="+strconv.Itoa(argon2.Version) {
+		return nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+
+	h := &argon2Hash{}
+	for _, kv := range strings.Split(parts[3], ",") {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, errInvalidArgon2Hash
+		}
+		switch name {
+		case "m", "t", "p":
+			n, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, errInvalidArgon2Hash
+			}
+			switch name {
+			case "m":
+				h.params.Memory = uint32(n)
+			case "t":
+				h.params.Iterations = uint32(n)
+			case "p":
+				if n > 255 {
+					return nil, errInvalidArgon2Hash
+				}
+				h.params.Parallelism = uint8(n)
+			}
+		case "keyid":
+			id, err := b64.DecodeString(value)
+			if err != nil {
+				return nil, errInvalidArgon2Hash
+			}
+			h.keyID = string(id)
+		default:
+			return nil, errInvalidArgon2Hash
+		}
+	}
+	if h.params.Memory == 0 || h.params.Iterations == 0 || h.params.Parallelism == 0 {
+		return nil, errInvalidArgon2Hash
+	}
+
+	var err error
+	if h.salt, err = b64.DecodeString(parts[4]); err != nil {
+		return nil, errInvalidArgon2Hash
+	}
+	if h.key, err = b64.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return nil, errInvalidArgon2Hash
+	}
+	return h, nil
+}
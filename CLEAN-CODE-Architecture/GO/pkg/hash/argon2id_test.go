@@ -0,0 +1,39 @@
+package hash
+
+import "testing"
+
+// testParams keeps the tests fast; production uses DefaultArgon2Params.
+var testParams = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idPepperRotation(t *testing.T) {
+	oldPepper := Pepper{ID: "1", Key: []byte("old pepper key, 32 bytes long!!!")}
+	newPepper := Pepper{ID: "2", Key: []byte("new pepper key, 32 bytes long!!!")}
+
+	encoded, err := NewArgon2id(testParams, oldPepper).Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := NewArgon2id(testParams, newPepper, oldPepper)
+	if !rotated.Verify("correct horse", encoded) {
+		t.Error("hash with a retired pepper does not verify")
+	}
+	if rotated.Verify("wrong horse", encoded) {
+		t.Error("wrong password verifies against a retired pepper")
+	}
+	if !rotated.Outdated(encoded) {
+		t.Error("hash with a retired pepper is not outdated")
+	}
+
+	rehashed, err := rotated.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.Outdated(rehashed) || !rotated.Verify("correct horse", rehashed) {
+		t.Error("rehash with the current pepper is not current")
+	}
+
+	if NewArgon2id(testParams, newPepper).Verify("correct horse", encoded) {
+		t.Error("hash verifies after its pepper was dropped")
+	}
+}
@@ -1,13 +1,68 @@
 package hash
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"strconv"
+	"strings"
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is the bcrypt work factor used when none is configured.
+const DefaultBcryptCost = 12
+
+// BcryptMaxPasswordLength is the longest password, in bytes, bcrypt hashes;
+// Hash refuses longer ones.
+const BcryptMaxPasswordLength = 72
+
+// Bcrypt hashes passwords with bcrypt. Hashes use the modular crypt
+// format ("$2a$12$...").
+type Bcrypt struct {
+	cost int
+}
+
+// NewBcrypt creates a bcrypt algorithm with the given cost.
+func NewBcrypt(cost int) *Bcrypt {
+	return &Bcrypt{cost: cost}
+}
+
+func (b *Bcrypt) ID() string {
+	return "bcrypt"
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
 	return string(bytes), err
 }
 
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+func (b *Bcrypt) Verify(password, encoded string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
 	return err == nil
 }
+
+func (b *Bcrypt) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *Bcrypt) Params(encoded string) string {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return ""
+	}
+	return strconv.Itoa(cost)
+}
+
+func (b *Bcrypt) HashLike(password, encoded string) (string, error) {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return "", err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	return string(hashed), err
+}
+
+func (b *Bcrypt) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.cost
+}
@@ -0,0 +1,211 @@
+// Package hash implements password hashing for the service.PasswordHasher port.
+//
+// Hashes are stored in self-describing formats (PHC strings for Argon2id, the
+// modular crypt format for bcrypt), so a Hasher can verify hashes produced by
+// any supported algorithm while always creating new ones with the preferred
+// algorithm and parameters. NeedsRehash tells callers when a stored hash
+// should be upgraded, which login does transparently.
+package hash
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+	"sync"
+)
+
+// Algorithm is a single password hashing scheme.
+type Algorithm interface {
+	// ID names the algorithm, e.g. "bcrypt" or "argon2id".
+	ID() string
+	// Hash returns the encoded hash of password.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches the encoded hash.
+	Verify(password, encoded string) bool
+	// Recognizes reports whether encoded was produced by this algorithm.
+	Recognizes(encoded string) bool
+	// Outdated reports whether encoded was produced with parameters other
+	// than the ones this algorithm is configured with.
+	Outdated(encoded string) bool
+	// Params describes the cost parameters of encoded, so that hashes which
+	// take equally long to verify have equal Params; "" if encoded is invalid.
+	Params(encoded string) string
+	// HashLike hashes password with the cost parameters of encoded.
+	HashLike(password, encoded string) (string, error)
+}
+
+// Hasher hashes with a preferred algorithm and verifies hashes from the
+// preferred or any legacy algorithm.
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+
+	// mu guards the dummy classes and their selection key.
+	mu       sync.Mutex
+	classes  []*dummyClass
+	byParams map[string]*dummyClass
+	key      []byte
+}
+
+// dummyClass is one algorithm and cost seen among the verified hashes.
+type dummyClass struct {
+	alg    Algorithm
+	sample string // a verified hash to copy the cost parameters from
+	weight uint64
+
+	once  sync.Once
+	dummy string
+	err   error
+}
+
+// NewHasher creates a Hasher that hashes with preferred and also accepts
+// hashes produced by the legacy algorithms.
+func NewHasher(preferred Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, legacy...),
+		byParams:   make(map[string]*dummyClass),
+	}
+}
+
+func (h *Hasher) Hash(ctx context.Context, password string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.preferred.Hash(password)
+}
+
+func (h *Hasher) Compare(ctx context.Context, password, encoded string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	alg := h.algorithmFor(encoded)
+	if alg == nil {
+		return false, nil
+	}
+	h.observe(alg, encoded, 1)
+	return alg.Verify(password, encoded), nil
+}
+
+// Observe counts encoded toward the mix of algorithms and costs that
+// CompareDummy imitates. Compare observes every hash it verifies; callers
+// may also prime the mix with stored hashes, e.g. at startup.
+func (h *Hasher) Observe(encoded string) {
+	if alg := h.algorithmFor(encoded); alg != nil {
+		h.observe(alg, encoded, 1)
+	}
+}
+
+func (h *Hasher) observe(alg Algorithm, encoded string, weight uint64) {
+	params := alg.Params(encoded)
+	if params == "" {
+		return
+	}
+	key := alg.ID() + "$" + params
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	class, ok := h.byParams[key]
+	if !ok {
+		class = &dummyClass{alg: alg, sample: encoded}
+		h.byParams[key] = class
+		h.classes = append(h.classes, class)
+	}
+	class.weight += weight
+}
+
+// CompareDummy verifies password against a throwaway hash, so an unknown
+// account costs what a real Compare costs. Verifying a legacy hash can take
+// much longer than a current one, so the dummy is drawn from the algorithms
+// and costs Compare has verified, in proportion to how often it verified
+// each. identifier (e.g. the email that was looked up) picks the draw with a
+// keyed hash: repeated attempts for one identifier always cost the same,
+// while unknown identifiers as a whole match the timing of real accounts.
+func (h *Hasher) CompareDummy(ctx context.Context, identifier, password string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	class, err := h.dummyClassFor(identifier)
+	if err != nil {
+		return err
+	}
+	class.once.Do(func() {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			class.err = err
+			return
+		}
+		class.dummy, class.err = class.alg.HashLike(hex.EncodeToString(secret), class.sample)
+	})
+	if class.err != nil {
+		return class.err
+	}
+	class.alg.Verify(password, class.dummy)
+	return nil
+}
+
+func (h *Hasher) dummyClassFor(identifier string) (*dummyClass, error) {
+	h.mu.Lock()
+	if h.key == nil {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			h.mu.Unlock()
+			return nil, err
+		}
+		h.key = key
+	}
+	empty := len(h.classes) == 0
+	h.mu.Unlock()
+
+	if empty {
+		// Nothing verified yet: assume the preferred algorithm's cost.
+		sample, err := h.preferred.Hash("")
+		if err != nil {
+			return nil, err
+		}
+		h.observe(h.preferred, sample, 1)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(identifier))
+	var total uint64
+	for _, class := range h.classes {
+		total += class.weight
+	}
+	// Scale the keyed hash, a fraction of 2^64, to the total weight: the
+	// identifier keeps its place in the mix while the weights grow.
+	pick, _ := bits.Mul64(binary.BigEndian.Uint64(mac.Sum(nil)), total)
+	for _, class := range h.classes {
+		if pick < class.weight {
+			return class, nil
+		}
+		pick -= class.weight
+	}
+	return h.classes[len(h.classes)-1], nil
+}
+
+// NeedsRehash reports whether encoded was produced by a legacy algorithm or
+// with outdated parameters (cost, memory, pepper) and should be replaced the
+// next time the plaintext is available.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	alg := h.algorithmFor(encoded)
+	if alg == nil || alg.ID() != h.preferred.ID() {
+		return true
+	}
+	return alg.Outdated(encoded)
+}
+
+func (h *Hasher) algorithmFor(encoded string) Algorithm {
+	for _, alg := range h.algorithms {
+		if alg.Recognizes(encoded) {
+			return alg
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package hash
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"auth-module/internal/domain/service"
+)
+
+// PoolConfig bounds how much hashing work runs at once.
+type PoolConfig struct {
+	// Workers is the number of hashes computed concurrently.
+	Workers int
+	// QueueDepth is how many further requests may wait for a worker before
+	// new ones are rejected with service.ErrHasherOverloaded.
+	QueueDepth int
+}
+
+// Pool wraps a Hasher with a bounded executor so a burst of logins or
+// registrations cannot starve the rest of the server of CPU.
+//
+// A request first takes a queue position; if none is free it fails
+// immediately. While waiting for a worker it honours its context, so a client
+// that disconnects gives up its position. Once a worker is acquired the hash
+// runs to completion.
+type Pool struct {
+	hasher  *Hasher
+	queue   chan struct{} // capacity Workers+QueueDepth: admitted requests
+	workers chan struct{} // capacity Workers: requests currently hashing
+}
+
+// Pool metrics, published at /debug/vars under "password_hashing".
+var (
+	poolMetrics        = expvar.NewMap("password_hashing")
+	metricOperations   = new(expvar.Int)
+	metricRejected     = new(expvar.Int)
+	metricCanceled     = new(expvar.Int)
+	metricQueued       = new(expvar.Int)
+	metricRunning      = new(expvar.Int)
+	metricQueueWaitNs  = new(expvar.Int)
+	metricHashTimeNs   = new(expvar.Int)
+	metricMaxQueueWait = new(expvar.Int)
+)
+
+func init() {
+	poolMetrics.Set("operations_total", metricOperations)
+	poolMetrics.Set("rejected_total", metricRejected)
+	poolMetrics.Set("canceled_total", metricCanceled)
+	poolMetrics.Set("queued", metricQueued)
+	poolMetrics.Set("running", metricRunning)
+	poolMetrics.Set("queue_wait_ns_total", metricQueueWaitNs)
+	poolMetrics.Set("hash_time_ns_total", metricHashTimeNs)
+	poolMetrics.Set("queue_wait_ns_max", metricMaxQueueWait)
+}
+
+// NewPool creates a Pool around hasher.
+func NewPool(hasher *Hasher, cfg PoolConfig) *Pool {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueDepth < 0 {
+		cfg.QueueDepth = 0
+	}
+	return &Pool{
+		hasher:  hasher,
+		queue:   make(chan struct{}, cfg.Workers+cfg.QueueDepth),
+		workers: make(chan struct{}, cfg.Workers),
+	}
+}
+
+func (p *Pool) Hash(ctx context.Context, password string) (string, error) {
+	var encoded string
+	err := p.run(ctx, func() (err error) {
+		encoded, err = p.hasher.Hash(ctx, password)
+		return err
+	})
+	return encoded, err
+}
+
+func (p *Pool) Compare(ctx context.Context, password, encoded string) (bool, error) {
+	var ok bool
+	err := p.run(ctx, func() (err error) {
+		ok, err = p.hasher.Compare(ctx, password, encoded)
+		return err
+	})
+	return ok, err
+}
+
+func (p *Pool) CompareDummy(ctx context.Context, identifier, password string) error {
+	return p.run(ctx, func() error {
+		return p.hasher.CompareDummy(ctx, identifier, password)
+	})
+}
+
+// NeedsRehash only parses the hash, so it bypasses the pool.
+func (p *Pool) NeedsRehash(encoded string) bool {
+	return p.hasher.NeedsRehash(encoded)
+}
+
+func (p *Pool) run(ctx context.Context, work func() error) error {
+	select {
+	case p.queue <- struct{}{}:
+	default:
+		metricRejected.Add(1)
+		return service.ErrHasherOverloaded
+	}
+	defer func() { <-p.queue }()
+
+	metricQueued.Add(1)
+	enqueued := time.Now()
+	select {
+	case p.workers <- struct{}{}:
+	case <-ctx.Done():
+		metricQueued.Add(-1)
+		metricCanceled.Add(1)
+		return ctx.Err()
+	}
+	defer func() { <-p.workers }()
+	metricQueued.Add(-1)
+	recordQueueWait(time.Since(enqueued))
+
+	metricRunning.Add(1)
+	started := time.Now()
+	err := work()
+	metricHashTimeNs.Add(int64(time.Since(started)))
+	metricRunning.Add(-1)
+	metricOperations.Add(1)
+	return err
+}
+
+func recordQueueWait(wait time.Duration) {
+	metricQueueWaitNs.Add(int64(wait))
+	// expvar.Int has no compare-and-swap; a racing writer may briefly win,
+	// which is acceptable for a high-water mark.
+	if int64(wait) > metricMaxQueueWait.Value() {
+		metricMaxQueueWait.Set(int64(wait))
+	}
+}